@@ -116,8 +116,8 @@ func TestDefaultResource(t *testing.T) {
 
 	// TODO(muvaf): Find a way to compare function pointers.
 	ignoreUnexported := []cmp.Option{
-		cmpopts.IgnoreFields(Sensitive{}, "fieldPaths", "AdditionalConnectionDetailsFn"),
-		cmpopts.IgnoreFields(LateInitializer{}, "ignoredCanonicalFieldPaths"),
+		cmpopts.IgnoreFields(Sensitive{}, "fieldPaths", "connectionDetailsTemplates", "AdditionalConnectionDetailsFn"),
+		cmpopts.IgnoreFields(LateInitializer{}, "ignoredCanonicalFieldPaths", "canonicalPolicies"),
 		cmpopts.IgnoreFields(ExternalName{}, "SetIdentifierArgumentFn", "GetExternalNameFn", "GetIDFn"),
 		cmpopts.IgnoreFields(Resource{}, "useNoForkClient"),
 	}
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ReasonObserved is the reason of the custom conditions whose status
+	// is computed from an observed Terraform attribute.
+	ReasonObserved xpv1.ConditionReason = "Observed"
+	// ReasonNotObserved is the reason of the custom conditions whose
+	// Terraform attribute has not been observed yet.
+	ReasonNotObserved xpv1.ConditionReason = "NotObserved"
+
+	errFmtGetConditionField     = "cannot get the observed value of the field %q for the condition %q"
+	errFmtConditionFn           = "cannot compute the condition %q"
+	errFmtReservedConditionType = "condition type %q is reserved and cannot be used for a custom condition"
+
+	fmtFieldValue = "The field %q is %q"
+)
+
+// reservedConditionTypes are the types of the conditions managed by
+// Crossplane and Upjet, which cannot be used for the custom conditions.
+var reservedConditionTypes = map[xpv1.ConditionType]struct{}{
+	xpv1.TypeReady:       {},
+	xpv1.TypeSynced:      {},
+	"LastAsyncOperation": {},
+	"AsyncOperation":     {},
+	"DeletionProtection": {},
+	"Stalled":            {},
+	"Test":               {},
+}
+
+// ConditionFn computes the status, reason and message of a custom condition
+// from the observed Terraform state.
+type ConditionFn func(tfstate map[string]any) (status corev1.ConditionStatus, reason xpv1.ConditionReason, message string, err error)
+
+// CustomCondition is a domain-specific status condition of a managed resource,
+// such as CertificateValidated or EncryptionEnabled, which is computed from
+// the observed Terraform state on every observation.
+type CustomCondition struct {
+	// Type is the type of the condition, e.g., "CertificateValidated".
+	Type xpv1.ConditionType
+	// FieldPath is the Terraform field path of the observed attribute the
+	// condition's status is computed from, e.g., "validation_status" or
+	// "encryption[0].enabled". The condition is True if the attribute has
+	// one of the TrueValues, False if it has another value, and Unknown if
+	// it has not been observed yet.
+	FieldPath string
+	// TrueValues are the values of the observed attribute for which the
+	// condition is True, compared with the string representation of the
+	// attribute.
+	TrueValues []string
+	// Fn computes the condition from the observed Terraform state. If set,
+	// the FieldPath and the TrueValues are ignored.
+	Fn ConditionFn
+}
+
+// validate returns an error if the condition has a type reserved for
+// the conditions managed by Crossplane and Upjet.
+func (c CustomCondition) validate() error {
+	if _, ok := reservedConditionTypes[c.Type]; ok {
+		return errors.Errorf(errFmtReservedConditionType, c.Type)
+	}
+	return nil
+}
+
+// Evaluate computes the condition from the specified observed Terraform
+// state.
+func (c CustomCondition) Evaluate(tfstate map[string]any) (xpv1.Condition, error) {
+	cond := xpv1.Condition{Type: c.Type}
+	if c.Fn != nil {
+		var err error
+		cond.Status, cond.Reason, cond.Message, err = c.Fn(tfstate)
+		return cond, errors.Wrapf(err, errFmtConditionFn, c.Type)
+	}
+	v, err := fieldpath.Pave(tfstate).GetValue(c.FieldPath)
+	if err != nil && !fieldpath.IsNotFound(err) {
+		return cond, errors.Wrapf(err, errFmtGetConditionField, c.FieldPath, c.Type)
+	}
+	if err != nil || v == nil {
+		cond.Status = corev1.ConditionUnknown
+		cond.Reason = ReasonNotObserved
+		cond.Message = fmt.Sprintf(fmtFieldNotObserved, c.FieldPath)
+		return cond, nil
+	}
+	s := fmt.Sprint(v)
+	cond.Status = corev1.ConditionFalse
+	if contains(c.TrueValues, s) {
+		cond.Status = corev1.ConditionTrue
+	}
+	cond.Reason = ReasonObserved
+	cond.Message = fmt.Sprintf(fmtFieldValue, c.FieldPath, s)
+	return cond, nil
+}
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCustomConditionEvaluate(t *testing.T) {
+	errBoom := errors.New("boom")
+	type args struct {
+		condition CustomCondition
+		tfstate   map[string]any
+	}
+	type want struct {
+		condition xpv1.Condition
+		err       error
+	}
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"True": {
+			reason: "The condition should be True if the observed attribute has one of the true values.",
+			args: args{
+				condition: CustomCondition{Type: "EncryptionEnabled", FieldPath: "encryption[0].enabled", TrueValues: []string{"true"}},
+				tfstate:   map[string]any{"encryption": []any{map[string]any{"enabled": true}}},
+			},
+			want: want{
+				condition: xpv1.Condition{
+					Type:    "EncryptionEnabled",
+					Status:  corev1.ConditionTrue,
+					Reason:  ReasonObserved,
+					Message: `The field "encryption[0].enabled" is "true"`,
+				},
+			},
+		},
+		"False": {
+			reason: "The condition should be False if the observed attribute has another value.",
+			args: args{
+				condition: CustomCondition{Type: "CertificateValidated", FieldPath: "status", TrueValues: []string{"ISSUED"}},
+				tfstate:   map[string]any{"status": "PENDING_VALIDATION"},
+			},
+			want: want{
+				condition: xpv1.Condition{
+					Type:    "CertificateValidated",
+					Status:  corev1.ConditionFalse,
+					Reason:  ReasonObserved,
+					Message: `The field "status" is "PENDING_VALIDATION"`,
+				},
+			},
+		},
+		"Unknown": {
+			reason: "The condition should be Unknown if the observed attribute is missing.",
+			args: args{
+				condition: CustomCondition{Type: "CertificateValidated", FieldPath: "status", TrueValues: []string{"ISSUED"}},
+				tfstate:   map[string]any{},
+			},
+			want: want{
+				condition: xpv1.Condition{
+					Type:    "CertificateValidated",
+					Status:  corev1.ConditionUnknown,
+					Reason:  ReasonNotObserved,
+					Message: `The field "status" has not been observed yet`,
+				},
+			},
+		},
+		"Fn": {
+			reason: "The condition function should take precedence over the field path.",
+			args: args{
+				condition: CustomCondition{
+					Type:      "CertificateValidated",
+					FieldPath: "status",
+					Fn: func(_ map[string]any) (corev1.ConditionStatus, xpv1.ConditionReason, string, error) {
+						return corev1.ConditionTrue, "Validated", "validated", nil
+					},
+				},
+			},
+			want: want{
+				condition: xpv1.Condition{
+					Type:    "CertificateValidated",
+					Status:  corev1.ConditionTrue,
+					Reason:  "Validated",
+					Message: "validated",
+				},
+			},
+		},
+		"FnFailed": {
+			reason: "The errors of the condition function should be returned.",
+			args: args{
+				condition: CustomCondition{
+					Type: "CertificateValidated",
+					Fn: func(_ map[string]any) (corev1.ConditionStatus, xpv1.ConditionReason, string, error) {
+						return "", "", "", errBoom
+					},
+				},
+			},
+			want: want{
+				condition: xpv1.Condition{Type: "CertificateValidated"},
+				err:       errors.Wrapf(errBoom, errFmtConditionFn, "CertificateValidated"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.args.condition.Evaluate(tc.args.tfstate)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nEvaluate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.condition, got); diff != "" {
+				t.Errorf("\n%s\nEvaluate(...): -want condition, +got condition:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"encoding/base64"
+	"text/template"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errFmtParseTemplate = "cannot parse the connection details template for the key %q"
+	errRequiredValue    = "required value is missing"
+)
+
+var templateFuncs = template.FuncMap{
+	"b64enc": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"b64dec": func(s string) (string, error) {
+		b, err := base64.StdEncoding.DecodeString(s)
+		return string(b), err
+	},
+	"required": func(v any) (any, error) {
+		if v == nil {
+			return nil, errors.New(errRequiredValue)
+		}
+		return v, nil
+	},
+}
+
+// GetConnectionDetailsTemplates returns the parsed ConnectionDetailsTemplates.
+// The templates are parsed once when the provider's resources are configured
+// and they are parsed on demand if they have not been parsed yet, e.g., for
+// the resource configurations built without a Provider.
+func (s *Sensitive) GetConnectionDetailsTemplates() (map[string]*template.Template, error) {
+	if s.connectionDetailsTemplates != nil || len(s.ConnectionDetailsTemplates) == 0 {
+		return s.connectionDetailsTemplates, nil
+	}
+	return parseConnectionDetailsTemplates(s.ConnectionDetailsTemplates)
+}
+
+// parseConnectionDetailsTemplates parses and caches
+// the ConnectionDetailsTemplates so that the invalid templates are reported
+// while the provider is being configured.
+func (s *Sensitive) parseConnectionDetailsTemplates() error {
+	templates, err := parseConnectionDetailsTemplates(s.ConnectionDetailsTemplates)
+	if err != nil {
+		return err
+	}
+	s.connectionDetailsTemplates = templates
+	return nil
+}
+
+func parseConnectionDetailsTemplates(texts map[string]string) (map[string]*template.Template, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	templates := make(map[string]*template.Template, len(texts))
+	for k, text := range texts {
+		t, err := template.New(k).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtParseTemplate, k)
+		}
+		templates[k] = t
+	}
+	return templates, nil
+}
@@ -7,6 +7,7 @@ package config
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -320,6 +321,10 @@ func (p *Provider) SetResourceConfigurator(resource string, c ResourceConfigurat
 }
 
 // ConfigureResources configures resources with provided ResourceConfigurator's
+// and validates the resulting resource configurations. It panics if
+// a resource configuration is invalid, e.g., if it has a connection details
+// template that cannot be parsed, so that the misconfigurations are caught
+// while the provider is being built instead of on every reconciliation.
 func (p *Provider) ConfigureResources() {
 	for name, c := range p.resourceConfigurators {
 		// if not skipped & included & configured via the default configurator
@@ -327,6 +332,21 @@ func (p *Provider) ConfigureResources() {
 			c.Configure(r)
 		}
 	}
+	for name, r := range p.Resources {
+		if err := r.validate(); err != nil {
+			panic(errors.Wrapf(err, "invalid configuration for the resource %q", name))
+		}
+	}
+}
+
+// APIGroup returns the API group of the managed resources generated for
+// the specified resource configuration, e.g., ec2.aws.upbound.io.
+// The resources without a ShortGroup are generated in the RootGroup.
+func (p *Provider) APIGroup(r *Resource) string {
+	if r.ShortGroup == "" {
+		return p.RootGroup
+	}
+	return strings.ToLower(r.ShortGroup) + "." + p.RootGroup
 }
 
 // GetSkippedResourceNames returns a list of Terraform resource names
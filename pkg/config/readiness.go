@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+)
+
+const (
+	errFmtGetReadinessField = "cannot get the observed value of the readiness field %q"
+	errCheckReadiness       = "cannot check the readiness of the external resource"
+
+	fmtFieldNotObserved = "The field %q has not been observed yet"
+	fmtUnexpectedValue  = "The field %q is %q, expected one of %q"
+)
+
+// ReadinessRule is a declarative readiness rule that requires an observed
+// Terraform attribute to have one of the specified values.
+type ReadinessRule struct {
+	// FieldPath is the Terraform field path of the observed attribute, e.g.,
+	// "status" or "certificate_status[0].phase".
+	FieldPath string
+	// Values are the values of the observed attribute that indicate
+	// the readiness of the external resource, compared with the string
+	// representation of the attribute, e.g., "ACTIVE" or "ISSUED".
+	Values []string
+}
+
+// ReadinessCheckFn reports whether the external resource is ready using its
+// observed Terraform state. If the resource is not ready, a message
+// explaining why is returned.
+type ReadinessCheckFn func(tfstate map[string]any) (ready bool, message string, err error)
+
+// Readiness represents the configuration of the readiness checks that are
+// evaluated before an observed external resource is marked as available.
+// A resource without any readiness rules or a readiness check function is
+// marked as available as soon as it's observed.
+type Readiness struct {
+	// Rules are the declarative readiness rules that all need to be
+	// satisfied for the external resource to be ready.
+	Rules []ReadinessRule
+	// CheckFn is a custom readiness check evaluated after the Rules.
+	CheckFn ReadinessCheckFn
+}
+
+// IsReady reports whether the external resource with the specified observed
+// Terraform state is ready, with a message explaining why if it's not.
+func (r Readiness) IsReady(tfstate map[string]any) (bool, string, error) {
+	paved := fieldpath.Pave(tfstate)
+	for _, rule := range r.Rules {
+		v, err := paved.GetValue(rule.FieldPath)
+		switch {
+		case fieldpath.IsNotFound(err):
+			return false, fmt.Sprintf(fmtFieldNotObserved, rule.FieldPath), nil
+		case err != nil:
+			return false, "", errors.Wrapf(err, errFmtGetReadinessField, rule.FieldPath)
+		case v == nil:
+			return false, fmt.Sprintf(fmtFieldNotObserved, rule.FieldPath), nil
+		}
+		s := fmt.Sprint(v)
+		if !contains(rule.Values, s) {
+			return false, fmt.Sprintf(fmtUnexpectedValue, rule.FieldPath, s, rule.Values), nil
+		}
+	}
+	if r.CheckFn == nil {
+		return true, "", nil
+	}
+	ready, msg, err := r.CheckFn(tfstate)
+	return ready, msg, errors.Wrap(err, errCheckReadiness)
+}
+
+func contains(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
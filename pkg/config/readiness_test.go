@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadinessIsReady(t *testing.T) {
+	errBoom := errors.New("boom")
+	type args struct {
+		readiness Readiness
+		tfstate   map[string]any
+	}
+	type want struct {
+		ready   bool
+		message string
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"NoRules": {
+			reason: "A resource without readiness rules should be ready.",
+			args: args{
+				tfstate: map[string]any{"status": "CREATING"},
+			},
+			want: want{
+				ready: true,
+			},
+		},
+		"RuleSatisfied": {
+			reason: "A resource whose observed attribute has one of the expected values should be ready.",
+			args: args{
+				readiness: Readiness{
+					Rules: []ReadinessRule{{FieldPath: "certificate[0].status", Values: []string{"ISSUED"}}},
+				},
+				tfstate: map[string]any{"certificate": []any{map[string]any{"status": "ISSUED"}}},
+			},
+			want: want{
+				ready: true,
+			},
+		},
+		"UnexpectedValue": {
+			reason: "A resource whose observed attribute has an unexpected value should not be ready.",
+			args: args{
+				readiness: Readiness{
+					Rules: []ReadinessRule{{FieldPath: "status", Values: []string{"ACTIVE"}}},
+				},
+				tfstate: map[string]any{"status": "CREATING"},
+			},
+			want: want{
+				message: `The field "status" is "CREATING", expected one of ["ACTIVE"]`,
+			},
+		},
+		"NotObserved": {
+			reason: "A resource whose readiness attribute has not been observed should not be ready.",
+			args: args{
+				readiness: Readiness{
+					Rules: []ReadinessRule{{FieldPath: "status", Values: []string{"ACTIVE"}}},
+				},
+				tfstate: map[string]any{},
+			},
+			want: want{
+				message: `The field "status" has not been observed yet`,
+			},
+		},
+		"CheckFnFailed": {
+			reason: "The errors of the readiness check function should be returned.",
+			args: args{
+				readiness: Readiness{
+					CheckFn: func(_ map[string]any) (bool, string, error) {
+						return false, "", errBoom
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCheckReadiness),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ready, msg, err := tc.args.readiness.IsReady(tc.args.tfstate)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nIsReady(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if ready != tc.want.ready {
+				t.Errorf("\n%s\nIsReady(...): want ready %v, got %v", tc.reason, tc.want.ready, ready)
+			}
+			if diff := cmp.Diff(tc.want.message, msg); diff != "" {
+				t.Errorf("\n%s\nIsReady(...): -want message, +got message:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ package config
 import (
 	"context"
 	"fmt"
+	"text/template"
 	"time"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -149,11 +150,69 @@ type Sensitive struct {
 	// connection details keys
 	AdditionalConnectionDetailsFn AdditionalConnectionDetailsFn
 
+	// ConnectionDetailsTemplates are the Go templates, keyed by the connection
+	// secret keys, that render additional connection details from the
+	// Terraform state and parameters of the resource, e.g., a JDBC URL
+	// or a kubeconfig. The templates are executed with text/template over
+	// a map with the "state" and "parameters" keys holding the Terraform
+	// state attributes, including the sensitive ones, and the Terraform
+	// parameters, respectively, e.g.,
+	// "postgres://{{ .state.username }}:{{ .state.password | urlquery }}@{{ .state.endpoint }}".
+	// The "b64enc", "b64dec" and "required" functions are available to
+	// the templates. A template fails to render if it refers to a missing
+	// key or to a null value, and "required" fails on null values in
+	// the pipelines, e.g., "{{ index .state.passwords 0 | required }}", so
+	// that incomplete credentials are not published. The templates are
+	// parsed when the resources of the provider are configured.
+	// Like the keys returned by AdditionalConnectionDetailsFn, the keys of the
+	// templates must not override the connection keys of the sensitive
+	// Terraform attributes, and they must not conflict with the keys
+	// returned by AdditionalConnectionDetailsFn.
+	ConnectionDetailsTemplates map[string]string
+
 	// fieldPaths keeps the mapping of sensitive fields in Terraform schema with
 	// terraform field path as key and xp field path as value.
 	fieldPaths map[string]string
+
+	// connectionDetailsTemplates are the parsed ConnectionDetailsTemplates.
+	connectionDetailsTemplates map[string]*template.Template
 }
 
+// DeletionProtection represents the configuration of the deletion
+// protection of a resource. Regardless of this configuration, a managed
+// resource can be protected from deletion with the
+// upjet.crossplane.io/deletion-protection annotation.
+type DeletionProtection struct {
+	// Enabled protects all managed resources of this kind from deletion
+	// unless they are annotated with
+	// upjet.crossplane.io/deletion-protection: "false".
+	Enabled bool
+
+	// Attribute is the Terraform field path of the native deletion
+	// protection argument of the resource, e.g., "deletion_protection".
+	// If the native deletion protection of an external resource is observed
+	// to be enabled while its managed resource is being deleted, the argument
+	// is turned off in the external resource before it's destroyed, instead
+	// of failing the deletion in the Cloud API.
+	Attribute string
+}
+
+// LateInitPolicy is the late-initialization policy of a field.
+type LateInitPolicy string
+
+const (
+	// LateInitOnce late-initializes a field only if it has not been
+	// late-initialized before, so that a field cleared by the user after
+	// its late-initialization is not initialized again.
+	LateInitOnce LateInitPolicy = "Once"
+	// LateInitAlways keeps the field in sync with its observed value, e.g.,
+	// for the fields owned by external controllers such as autoscalers.
+	LateInitAlways LateInitPolicy = "Always"
+	// LateInitNever never late-initializes the field. It's equivalent to
+	// adding the field to the IgnoredFields.
+	LateInitNever LateInitPolicy = "Never"
+)
+
 // LateInitializer represents configurations that control
 // late-initialization behaviour
 type LateInitializer struct {
@@ -168,6 +227,15 @@ type LateInitializer struct {
 	// during late-initialization. This is filled using the `IgnoredFields`
 	// field which keeps Terraform paths by converting them to Canonical paths.
 	ignoredCanonicalFieldPaths []string
+
+	// Policies are the late-initialization policies keyed by the Terraform
+	// field paths, which are specified like the IgnoredFields. The fields
+	// without a policy are late-initialized whenever they are unset.
+	Policies map[string]LateInitPolicy
+
+	// canonicalPolicies are the late-initialization policies keyed by the
+	// Canonical field paths. This is filled using the `Policies` field.
+	canonicalPolicies map[string]LateInitPolicy
 }
 
 // GetIgnoredCanonicalFields returns the ignoredCanonicalFields
@@ -183,6 +251,21 @@ func (l *LateInitializer) AddIgnoredCanonicalFields(cf string) {
 	l.ignoredCanonicalFieldPaths = append(l.ignoredCanonicalFieldPaths, cf)
 }
 
+// GetCanonicalPolicies returns the late-initialization policies keyed by
+// the Canonical field paths.
+func (l *LateInitializer) GetCanonicalPolicies() map[string]LateInitPolicy {
+	return l.canonicalPolicies
+}
+
+// AddCanonicalPolicy sets the late-initialization policy of the field with
+// the specified Canonical field path.
+func (l *LateInitializer) AddCanonicalPolicy(cf string, p LateInitPolicy) {
+	if l.canonicalPolicies == nil {
+		l.canonicalPolicies = make(map[string]LateInitPolicy)
+	}
+	l.canonicalPolicies[cf] = p
+}
+
 // GetFieldPaths returns the fieldPaths map for Sensitive
 func (s *Sensitive) GetFieldPaths() map[string]string {
 	return s.fieldPaths
@@ -313,6 +396,20 @@ type Resource struct {
 	// LateInitializer configuration to control late-initialization behaviour
 	LateInitializer LateInitializer
 
+	// Readiness configures the readiness checks evaluated before the
+	// observed external resource is marked as available.
+	Readiness Readiness
+
+	// CustomConditions are the domain-specific status conditions computed
+	// from the observed Terraform state on every observation. The types of
+	// the conditions managed by Crossplane and Upjet, such as Ready,
+	// Synced, Stalled, LastAsyncOperation and AsyncOperation, are reserved and
+	// rejected when the resources of the provider are configured.
+	CustomConditions []CustomCondition
+
+	// DeletionProtection configures the deletion protection of the resource.
+	DeletionProtection DeletionProtection
+
 	// MetaResource is the metadata associated with the resource scraped from
 	// the Terraform registry.
 	MetaResource *registry.Resource
@@ -347,6 +444,18 @@ func (r *Resource) ShouldUseNoForkClient() bool {
 	return r.useNoForkClient
 }
 
+// validate validates the configuration of the resource and prepares
+// the parts of it that are used on every reconciliation, such as
+// the connection details templates.
+func (r *Resource) validate() error {
+	for _, cc := range r.CustomConditions {
+		if err := cc.validate(); err != nil {
+			return err
+		}
+	}
+	return r.Sensitive.parseConnectionDetailsTemplates()
+}
+
 // CustomDiff customizes the computed Terraform InstanceDiff. This can be used
 // in cases where, for example, changes in a certain argument should just be
 // dismissed. The new InstanceDiff is returned along with any errors.
@@ -112,3 +112,73 @@ func TestSetExternalTagsWithPaved(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceValidate(t *testing.T) {
+	type want struct {
+		templates []string
+		err       error
+	}
+	cases := map[string]struct {
+		reason string
+		r      *Resource
+		want   want
+	}{
+		"NoTemplates": {
+			reason: "A resource without connection details templates should be valid.",
+			r:      &Resource{},
+		},
+		"ValidTemplates": {
+			reason: "The connection details templates should be parsed once the resource is validated.",
+			r: &Resource{
+				Sensitive: Sensitive{
+					ConnectionDetailsTemplates: map[string]string{
+						"url": "{{ .state.endpoint | required }}",
+					},
+				},
+			},
+			want: want{
+				templates: []string{"url"},
+			},
+		},
+		"ReservedConditionType": {
+			reason: "An error should be returned if a custom condition has a reserved type.",
+			r: &Resource{
+				CustomConditions: []CustomCondition{
+					{Type: "CertificateValidated"},
+					{Type: "LastAsyncOperation"},
+				},
+			},
+			want: want{
+				err: errors.Errorf(errFmtReservedConditionType, "LastAsyncOperation"),
+			},
+		},
+		"InvalidTemplate": {
+			reason: "An error should be returned if a connection details template cannot be parsed.",
+			r: &Resource{
+				Sensitive: Sensitive{
+					ConnectionDetailsTemplates: map[string]string{
+						"url": "{{ .state.endpoint }",
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrapf(errors.New(`template: url:1: unexpected "}" in operand`), errFmtParseTemplate, "url"),
+			},
+		},
+	}
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			err := tc.r.validate()
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nvalidate(): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			var got []string
+			for k := range tc.r.Sensitive.connectionDetailsTemplates {
+				got = append(got, k)
+			}
+			if diff := cmp.Diff(tc.want.templates, got); diff != "" {
+				t.Errorf("\n%s\nvalidate(): -want parsed templates, +got parsed templates:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
@@ -16,9 +17,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/manager"
 
+	"github.com/crossplane/upjet/pkg/config"
 	"github.com/crossplane/upjet/pkg/controller/handler"
 	"github.com/crossplane/upjet/pkg/resource"
 	"github.com/crossplane/upjet/pkg/terraform"
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
 )
 
 const (
@@ -56,6 +59,22 @@ func (a *APISecretClient) GetSecretValue(ctx context.Context, sel xpv1.SecretKey
 	return d[sel.Key], err
 }
 
+// newSecretClient returns the SecretClient that resolves the secret references
+// of the sensitive parameters. The references without a scheme are resolved
+// from the Kubernetes Secrets and the others with the SecretClients
+// configured for their schemes.
+func newSecretClient(kube client.Client, clients map[string]resource.SecretClient) resource.SecretClient {
+	sc := &APISecretClient{kube: kube}
+	if len(clients) == 0 {
+		return sc
+	}
+	opts := make([]resource.SecretClientChainOption, 0, len(clients))
+	for scheme, c := range clients {
+		opts = append(opts, resource.WithSchemeSecretClient(scheme, c))
+	}
+	return resource.NewSecretClientChain(sc, opts...)
+}
+
 // APICallbacksOption represents a configurable option for the APICallbacks
 type APICallbacksOption func(callbacks *APICallbacks)
 
@@ -79,6 +98,24 @@ func WithStatusUpdates(enabled bool) APICallbacksOption {
 	}
 }
 
+// WithCallbackEventRecorder sets the recorder the APICallbacks report
+// the Terraform diagnostics of the failed asynchronous operations with,
+// one event per diagnostic.
+func WithCallbackEventRecorder(r event.Recorder) APICallbacksOption {
+	return func(callbacks *APICallbacks) {
+		callbacks.recorder = r
+	}
+}
+
+// WithCallbackResourceConfig sets the configuration of the managed resources
+// the APICallbacks work on, which is used to map the attribute paths of
+// the Terraform diagnostics to the fields of the managed resources.
+func WithCallbackResourceConfig(cfg *config.Resource) APICallbacksOption {
+	return func(callbacks *APICallbacks) {
+		callbacks.config = cfg
+	}
+}
+
 // NewAPICallbacks returns a new APICallbacks.
 func NewAPICallbacks(m ctrl.Manager, of xpresource.ManagedKind, opts ...APICallbacksOption) *APICallbacks {
 	nt := func() resource.Terraformed {
@@ -90,6 +127,7 @@ func NewAPICallbacks(m ctrl.Manager, of xpresource.ManagedKind, opts ...APICallb
 		// the default behavior is to use the LastAsyncOperation
 		// status condition for backwards compatibility.
 		enableStatusUpdates: true,
+		recorder:            event.NewNopRecorder(),
 	}
 	for _, o := range opts {
 		o(cb)
@@ -100,6 +138,8 @@ func NewAPICallbacks(m ctrl.Manager, of xpresource.ManagedKind, opts ...APICallb
 // APICallbacks providers callbacks that work on API resources.
 type APICallbacks struct {
 	eventHandler *handler.EventHandler
+	recorder     event.Recorder
+	config       *config.Resource
 
 	kube                client.Client
 	newTerraformed      func() resource.Terraformed
@@ -119,6 +159,7 @@ func (ac *APICallbacks) callbackFn(name, op string) terraform.CallbackFn {
 		// to do so. So we keep the `LastAsyncOperation` condition.
 		// TODO: move this to the `Synced` condition.
 		tr.SetConditions(resource.LastAsyncOperationCondition(err))
+		recordDiagnostics(ac.recorder, tr, ac.config, err)
 		if ac.enableStatusUpdates {
 			tr.SetConditions(resource.AsyncOperationFinishedCondition())
 		}
@@ -126,10 +167,14 @@ func (ac *APICallbacks) callbackFn(name, op string) terraform.CallbackFn {
 		if ac.eventHandler != nil {
 			rateLimiter := handler.NoRateLimiter
 			switch {
+			case tferrors.IsTerminal(err):
+				// terminal errors are retried with a longer backoff
+				rateLimiter = handler.TerminalErrorRateLimiter
 			case err != nil:
 				rateLimiter = rateLimiterCallback
 			default:
 				ac.eventHandler.Forget(rateLimiterCallback, name)
+				ac.eventHandler.Forget(handler.TerminalErrorRateLimiter, name)
 			}
 			// TODO: use the errors.Join from
 			// github.com/crossplane/crossplane-runtime.
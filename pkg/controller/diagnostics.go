@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/upjet/pkg/config"
+	"github.com/crossplane/upjet/pkg/resource"
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
+)
+
+const (
+	reasonTerraformDiagnostic event.Reason = "TerraformDiagnostic"
+
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// recordDiagnostics records an event for each of the Terraform diagnostics
+// in the specified error chain, reporting the field of the managed resource
+// the diagnostic is about, if known. The error diagnostics are recorded as
+// warning events and the warning diagnostics as normal events. The specified
+// configuration of the managed resource, if any, is used to map
+// the attribute paths of the diagnostics to the fields.
+func recordDiagnostics(r event.Recorder, mg xpresource.Managed, cfg *config.Resource, err error) {
+	if r == nil || err == nil {
+		return
+	}
+	var mapping map[string]string
+	if tr, ok := mg.(resource.Terraformed); ok {
+		mapping = tr.GetConnectionDetailsMapping()
+	}
+	for _, d := range tferrors.Diagnostics(err) {
+		msg := d.Message()
+		if fp := resource.DiagnosticFieldPath(cfg, mapping, d.AttributePath); fp != "" {
+			msg = fmt.Sprintf("%s: %s", fp, msg)
+		}
+		if d.Severity == severityWarning {
+			r.Event(mg, event.Normal(reasonTerraformDiagnostic, msg, "severity", d.Severity))
+			continue
+		}
+		r.Event(mg, event.Warning(reasonTerraformDiagnostic, errors.New(msg), "severity", d.Severity))
+	}
+}
+
+// withDiagnostics annotates the specified error with the structured
+// representations of the specified Terraform plugin SDK diagnostics.
+func withDiagnostics(err error, diags diag.Diagnostics) error {
+	result := make([]tferrors.Diagnostic, 0, len(diags))
+	for _, d := range diags {
+		severity := severityError
+		if d.Severity == diag.Warning {
+			severity = severityWarning
+		}
+		result = append(result, tferrors.Diagnostic{
+			Severity:      severity,
+			Summary:       d.Summary,
+			Detail:        d.Detail,
+			AttributePath: attributePath(d.AttributePath),
+		})
+	}
+	return tferrors.WithDiagnostics(err, result)
+}
+
+// attributePath returns the string representation of the specified
+// attribute path, e.g., ingress[0].from_port.
+func attributePath(p cty.Path) string {
+	var b strings.Builder
+	for _, s := range p {
+		switch s := s.(type) {
+		case cty.GetAttrStep:
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(s.Name)
+		case cty.IndexStep:
+			switch s.Key.Type() {
+			case cty.Number:
+				i, _ := s.Key.AsBigFloat().Int64()
+				fmt.Fprintf(&b, "[%d]", i)
+			case cty.String:
+				fmt.Fprintf(&b, "[%q]", s.Key.AsString())
+			default:
+				// set elements cannot be addressed
+				return b.String()
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/upjet/pkg/resource/fake"
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
+)
+
+func TestRecordDiagnostics(t *testing.T) {
+	type args struct {
+		err error
+	}
+	cases := map[string]struct {
+		args args
+		want []event.Event
+	}{
+		"NoError": {},
+		"NoDiagnostics": {
+			args: args{
+				err: errBoom,
+			},
+		},
+		"Diagnostics": {
+			args: args{
+				err: errors.Wrap(withDiagnostics(errBoom, diag.Diagnostics{
+					{
+						Severity:      diag.Error,
+						Summary:       "Invalid value",
+						Detail:        "expected a port number",
+						AttributePath: cty.GetAttrPath("ingress").IndexInt(0).GetAttr("from_port"),
+					},
+					{
+						Severity: diag.Warning,
+						Summary:  "Deprecated",
+					},
+				}), errApply),
+			},
+			want: []event.Event{
+				event.Warning(reasonTerraformDiagnostic, errors.New("spec.forProvider.ingress[0].fromPort: Invalid value: expected a port number"), "severity", "error"),
+				event.Normal(reasonTerraformDiagnostic, "Deprecated", "severity", "warning"),
+			},
+		},
+		"CLIDiagnostics": {
+			args: args{
+				err: errors.Wrap(tferrors.NewApplyFailed([]byte(`{"@level":"error","@message":"Error: Invalid value","diagnostic":{"severity":"error","summary":"Invalid value","detail":"expected a port number","address":"aws_security_group.example","attribute":"aws_security_group.example.ingress[0].from_port"},"type":"diagnostic"}`)), errApply),
+			},
+			want: []event.Event{
+				event.Warning(reasonTerraformDiagnostic, errors.New("spec.forProvider.ingress[0].fromPort: Invalid value: expected a port number"), "severity", "error"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &eventRecorder{}
+			recordDiagnostics(r, &fake.Terraformed{}, nil, tc.args.err)
+			if diff := cmp.Diff(tc.want, r.events); diff != "" {
+				t.Errorf("\n%s\nrecordDiagnostics(...): -want events, +got events:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestAttributePath(t *testing.T) {
+	cases := map[string]struct {
+		path cty.Path
+		want string
+	}{
+		"Empty": {},
+		"Nested": {
+			path: cty.GetAttrPath("ingress").IndexInt(1).GetAttr("cidr_blocks"),
+			want: "ingress[1].cidr_blocks",
+		},
+		"MapKey": {
+			path: cty.GetAttrPath("tags").IndexString("team"),
+			want: `tags["team"]`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, attributePath(tc.path)); diff != "" {
+				t.Errorf("\n%s\nattributePath(...): -want, +got:\n%s", name, diff)
+			}
+		})
+	}
+}
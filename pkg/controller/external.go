@@ -9,10 +9,13 @@ import (
 	"time"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -26,24 +29,33 @@ import (
 )
 
 const (
-	errUnexpectedObject  = "the custom resource is not a Terraformed resource"
-	errGetTerraformSetup = "cannot get terraform setup"
-	errGetWorkspace      = "cannot get a terraform workspace for resource"
-	errRefresh           = "cannot run refresh"
-	errImport            = "cannot run import"
-	errPlan              = "cannot run plan"
-	errStartAsyncApply   = "cannot start async apply"
-	errStartAsyncDestroy = "cannot start async destroy"
-	errApply             = "cannot apply"
-	errDestroy           = "cannot destroy"
-	errScheduleProvider  = "cannot schedule native Terraform provider process, please consider increasing its TTL with the --provider-ttl command-line option"
-	errUpdateAnnotations = "cannot update managed resource annotations"
+	errUnexpectedObject        = "the custom resource is not a Terraformed resource"
+	errGetTerraformSetup       = "cannot get terraform setup"
+	errGetWorkspace            = "cannot get a terraform workspace for resource"
+	errRefresh                 = "cannot run refresh"
+	errImport                  = "cannot run import"
+	errPlan                    = "cannot run plan"
+	errStartAsyncApply         = "cannot start async apply"
+	errStartAsyncDestroy       = "cannot start async destroy"
+	errApply                   = "cannot apply"
+	errDestroy                 = "cannot destroy"
+	errScheduleProvider        = "cannot schedule native Terraform provider process, please consider increasing its TTL with the --provider-ttl command-line option"
+	errUpdateAnnotations       = "cannot update managed resource annotations"
+	errResetLateInit           = "cannot reset the late-initialized fields"
+	errDeletionProtection      = "cannot check the deletion protection"
+	errDisableNativeProtection = "cannot turn off the native deletion protection of the external resource"
+	errGetPrivateState         = "cannot get the private state of the managed resource"
+	errSetPrivateState         = "cannot store the private state of the managed resource"
+	errMarshalPrivateState     = "cannot marshal the private state of the managed resource"
+	errUnmarshalPrivateState   = "cannot unmarshal the private state of the managed resource"
 )
 
 const (
 	rateLimiterScheduler = "scheduler"
 	rateLimiterStatus    = "status"
 	retryLimit           = 20
+
+	reasonCannotScheduleProvider event.Reason = "CannotScheduleNativeProvider"
 )
 
 // Option allows you to configure Connector.
@@ -73,6 +85,68 @@ func WithConnectorEventHandler(e *handler.EventHandler) Option {
 	}
 }
 
+// WithEventRecorder configures the recorder the external clients report
+// the failures to schedule the native Terraform providers, e.g., due to
+// their crashes, with.
+func WithEventRecorder(r event.Recorder) Option {
+	return func(c *Connector) {
+		c.recorder = r
+	}
+}
+
+// WithPrivateStateStore configures the store the Terraform private states of
+// the managed resources are stored with. If not set, the private states are
+// stored in the annotations of the managed resources. The same store should
+// be configured for the workspace store with terraform.WithPrivateStateStore.
+func WithPrivateStateStore(s resource.PrivateStateStore) Option {
+	return func(c *Connector) {
+		c.privateStateStore = s
+	}
+}
+
+// WithSecretClient configures the SecretClient that resolves the secret
+// references of the sensitive parameters with the specified scheme, e.g.,
+// file:///mnt/secrets/db. The references without a scheme are resolved from
+// the Kubernetes Secrets.
+func WithSecretClient(scheme string, sc resource.SecretClient) Option {
+	return func(c *Connector) {
+		if c.secretClients == nil {
+			c.secretClients = make(map[string]resource.SecretClient)
+		}
+		c.secretClients[scheme] = sc
+	}
+}
+
+// WithSecretClients configures the SecretClients that resolve the secret
+// references of the sensitive parameters, keyed by their schemes. See
+// resource.SecretClientChain for how the schemes of the secret references
+// are determined.
+func WithSecretClients(clients map[string]resource.SecretClient) Option {
+	return func(c *Connector) {
+		for scheme, sc := range clients {
+			WithSecretClient(scheme, sc)(c)
+		}
+	}
+}
+
+// WithOperationLimiter configures the OperationLimiter that limits
+// the number of concurrently running asynchronous operations.
+func WithOperationLimiter(l *terraform.OperationLimiter) Option {
+	return func(c *Connector) {
+		c.operationLimiter = l
+	}
+}
+
+// WithErrorClassifier configures the Classifier the errors of the Terraform
+// operations are classified with, e.g., to tell the terminal errors from
+// the retryable ones. It overrides the Classifier of the workspace store.
+// A nil Classifier keeps the one of the workspace store.
+func WithErrorClassifier(ec *tferrors.Classifier) Option {
+	return func(c *Connector) {
+		c.classifier = ec
+	}
+}
+
 // NewConnector returns a new Connector object.
 func NewConnector(kube client.Client, ws Store, sf terraform.SetupFn, cfg *config.Resource, opts ...Option) *Connector {
 	c := &Connector{
@@ -81,6 +155,7 @@ func NewConnector(kube client.Client, ws Store, sf terraform.SetupFn, cfg *confi
 		store:             ws,
 		config:            cfg,
 		logger:            logging.NewNopLogger(),
+		recorder:          event.NewNopRecorder(),
 	}
 	for _, f := range opts {
 		f(c)
@@ -88,6 +163,18 @@ func NewConnector(kube client.Client, ws Store, sf terraform.SetupFn, cfg *confi
 	return c
 }
 
+// resetLateInitializedFields removes the late-initialized fields from the spec
+// of the specified managed resource if requested by the user and persists
+// the resulting spec, so that the external client is configured with the
+// minimal spec. The removed fields are not late-initialized again.
+func resetLateInitializedFields(ctx context.Context, kube client.Client, tr resource.Terraformed) error {
+	reset, err := resource.ResetLateInitializedFields(tr)
+	if err != nil || !reset {
+		return errors.Wrap(err, errResetLateInit)
+	}
+	return errors.Wrap(kube.Update(ctx, tr), errResetLateInit)
+}
+
 // Connector initializes the external client with credentials and other configuration
 // parameters.
 type Connector struct {
@@ -98,6 +185,11 @@ type Connector struct {
 	callback          CallbackProvider
 	eventHandler      *handler.EventHandler
 	logger            logging.Logger
+	recorder          event.Recorder
+	operationLimiter  *terraform.OperationLimiter
+	privateStateStore resource.PrivateStateStore
+	secretClients     map[string]resource.SecretClient
+	classifier        *tferrors.Classifier
 }
 
 // Connect makes sure the underlying client is ready to issue requests to the
@@ -113,24 +205,35 @@ func (c *Connector) Connect(ctx context.Context, mg xpresource.Managed) (managed
 		return nil, errors.Wrap(err, errGetTerraformSetup)
 	}
 
-	ws, err := c.store.Workspace(ctx, &APISecretClient{kube: c.kube}, tr, ts, c.config)
+	if err := resetLateInitializedFields(ctx, c.kube, tr); err != nil {
+		return nil, err
+	}
+	ws, err := c.store.Workspace(ctx, newSecretClient(c.kube, c.secretClients), tr, ts, c.config)
 	if err != nil {
 		return nil, errors.Wrap(err, errGetWorkspace)
 	}
+	ws.UseOperationLimiter(c.operationLimiter, terraform.NewOperationKey(mg))
+	if c.classifier != nil {
+		ws.UseErrorClassifier(c.classifier)
+	}
 	return &external{
 		workspace:         ws,
+		operation:         ws.LastOperation,
 		config:            c.config,
 		callback:          c.callback,
 		providerScheduler: ts.Scheduler,
 		providerHandle:    ws.ProviderHandle,
 		eventHandler:      c.eventHandler,
 		kube:              c.kube,
+		recorder:          c.recorder,
+		privateStateStore: c.privateStateStore,
 		logger:            c.logger.WithValues("uid", mg.GetUID(), "name", mg.GetName(), "gvk", mg.GetObjectKind().GroupVersionKind().String()),
 	}, nil
 }
 
 type external struct {
 	workspace         Workspace
+	operation         *terraform.Operation
 	config            *config.Resource
 	callback          CallbackProvider
 	providerScheduler terraform.ProviderScheduler
@@ -138,23 +241,29 @@ type external struct {
 	eventHandler      *handler.EventHandler
 	kube              client.Client
 	logger            logging.Logger
+	recorder          event.Recorder
+	privateStateStore resource.PrivateStateStore
 }
 
-func (e *external) scheduleProvider(name string) (bool, error) {
+func (e *external) scheduleProvider(mg xpresource.Managed) (bool, error) {
 	if e.providerScheduler == nil || e.workspace == nil {
 		return false, nil
 	}
 	inuse, attachmentConfig, err := e.providerScheduler.Start(e.providerHandle)
 	if err != nil {
 		retryLimit := retryLimit
-		if tferrors.IsRetryScheduleError(err) && (e.eventHandler != nil && e.eventHandler.RequestReconcile(rateLimiterScheduler, name, &retryLimit)) {
+		if tferrors.IsRetryScheduleError(err) && (e.eventHandler != nil && e.eventHandler.RequestReconcile(rateLimiterScheduler, mg.GetName(), &retryLimit)) {
 			// the reconcile request has been requeued for a rate-limited retry
 			return true, nil
 		}
-		return false, errors.Wrap(err, errScheduleProvider)
+		err = errors.Wrap(err, errScheduleProvider)
+		if e.recorder != nil {
+			e.recorder.Event(mg, event.Warning(reasonCannotScheduleProvider, err))
+		}
+		return false, err
 	}
 	if e.eventHandler != nil {
-		e.eventHandler.Forget(rateLimiterScheduler, name)
+		e.eventHandler.Forget(rateLimiterScheduler, mg.GetName())
 	}
 	if ps, ok := e.workspace.(ProviderSharer); ok {
 		ps.UseProvider(inuse, attachmentConfig)
@@ -162,6 +271,16 @@ func (e *external) scheduleProvider(name string) (bool, error) {
 	return false, nil
 }
 
+// setCriticalState sets the external name and stores the private state of
+// the managed resource, and reports whether the managed resource has been
+// modified.
+func (e *external) setCriticalState(ctx context.Context, tr resource.Terraformed, tfstate map[string]any, privateRaw string) (bool, error) {
+	if e.privateStateStore == nil {
+		return resource.SetCriticalAnnotations(tr, e.config, tfstate, privateRaw)
+	}
+	return resource.SetCriticalState(ctx, tr, e.config, tfstate, privateRaw, e.privateStateStore)
+}
+
 func (e *external) stopProvider() {
 	if e.providerScheduler == nil {
 		return
@@ -176,7 +295,7 @@ func (e *external) Observe(ctx context.Context, mg xpresource.Managed) (managed.
 	// and serial.
 	// TODO(muvaf): Look for ways to reduce the cyclomatic complexity without
 	// increasing the difficulty of understanding the flow.
-	requeued, err := e.scheduleProvider(mg.GetName())
+	requeued, err := e.scheduleProvider(mg)
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrapf(err, "cannot schedule a native provider during observe: %s", mg.GetUID())
 	}
@@ -195,6 +314,10 @@ func (e *external) Observe(ctx context.Context, mg xpresource.Managed) (managed.
 		return managed.ExternalObservation{}, errors.New(errUnexpectedObject)
 	}
 
+	if err := backedOffError(e.eventHandler, mg); err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
 	policySet := sets.New[xpv1.ManagementAction](tr.GetManagementPolicies()...)
 
 	// Note(turkenh): We don't need to check if the management policies are
@@ -210,9 +333,10 @@ func (e *external) Observe(ctx context.Context, mg xpresource.Managed) (managed.
 		return e.Import(ctx, tr)
 	}
 
+	cancelStaleOperation(e.operation, mg, e.logger)
 	res, err := e.workspace.Refresh(ctx)
 	if err != nil {
-		return managed.ExternalObservation{}, errors.Wrap(err, errRefresh)
+		return managed.ExternalObservation{}, errors.Wrap(backOffTerminalError(e.eventHandler, mg, err), errRefresh)
 	}
 
 	switch {
@@ -243,6 +367,9 @@ func (e *external) Observe(ctx context.Context, mg xpresource.Managed) (managed.
 	if err := tr.SetObservation(tfstate); err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, "cannot set observation")
 	}
+	if err := resource.SetCustomConditions(tr, e.config, tfstate); err != nil {
+		return managed.ExternalObservation{}, err
+	}
 
 	// NOTE(lsviben) although the annotations were supposed to be set and the
 	// managed resource updated during the Create step, we are checking and
@@ -255,7 +382,7 @@ func (e *external) Observe(ctx context.Context, mg xpresource.Managed) (managed.
 	// turned off. To circumvent this, we are checking if the management policy
 	// does not contain LateInitialize and if it does not, we are updating the
 	// annotations manually.
-	annotationsUpdated, err := resource.SetCriticalAnnotations(tr, e.config, tfstate, string(res.State.GetPrivateRaw()))
+	annotationsUpdated, err := e.setCriticalState(ctx, tr, tfstate, string(res.State.GetPrivateRaw()))
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, "cannot set critical annotations")
 	}
@@ -279,6 +406,13 @@ func (e *external) Observe(ctx context.Context, mg xpresource.Managed) (managed.
 		}
 	}
 	markedAvailable := tr.GetCondition(xpv1.TypeReady).Equal(xpv1.Available())
+	ready, notReadyMsg := true, ""
+	if !markedAvailable {
+		ready, notReadyMsg, err = e.config.Readiness.IsReady(tfstate)
+		if err != nil {
+			return managed.ExternalObservation{}, err
+		}
+	}
 
 	// In the following switch block, before running a relatively costly
 	// Terraform apply and that may fail before critical annotations are
@@ -303,7 +437,7 @@ func (e *external) Observe(ctx context.Context, mg xpresource.Managed) (managed.
 			ResourceLateInitialized: true,
 		}, nil
 	// we prioritize status updates over late-init'ed spec updates
-	case !markedAvailable:
+	case !markedAvailable && ready:
 		addTTR(tr)
 		tr.SetConditions(xpv1.Available())
 		e.logger.Debug("Resource is marked as available.")
@@ -327,12 +461,26 @@ func (e *external) Observe(ctx context.Context, mg xpresource.Managed) (managed.
 		}, nil
 	// now we do a Workspace.Refresh
 	default:
-		if e.eventHandler != nil {
+		switch {
+		// the external resource exists but is not usable yet, e.g., it's
+		// still being provisioned, so we poll it until it becomes ready.
+		// Like the no-fork external clients, we still compute the diff so
+		// that the resource can be updated while it's not ready.
+		case !markedAvailable:
+			tr.SetConditions(xpv1.Unavailable().WithMessage(notReadyMsg))
+			e.logger.Debug("Resource is not ready yet.", "reason", notReadyMsg)
+			if e.eventHandler != nil {
+				e.eventHandler.RequestReconcile(rateLimiterStatus, mg.GetName(), nil)
+			}
+		case e.eventHandler != nil:
 			e.eventHandler.Forget(rateLimiterStatus, mg.GetName())
 		}
 		plan, err := e.workspace.Plan(ctx)
 		if err != nil {
-			return managed.ExternalObservation{}, errors.Wrap(err, errPlan)
+			return managed.ExternalObservation{}, errors.Wrap(backOffTerminalError(e.eventHandler, mg, err), errPlan)
+		}
+		if plan.UpToDate {
+			clearTerminalError(e.eventHandler, mg)
 		}
 
 		resource.SetUpToDateCondition(mg, plan.UpToDate)
@@ -346,13 +494,39 @@ func (e *external) Observe(ctx context.Context, mg xpresource.Managed) (managed.
 	}
 }
 
+// cancelStaleOperation cancels the running asynchronous operation if it has
+// become stale, i.e., if the managed resource has been deleted or its
+// generation has changed since the operation started. Operations that are
+// not bound to a generation, such as creations, are only canceled when
+// the managed resource is deleted, because interrupting a creation may leave
+// a partially created external resource behind. Returns true if
+// the operation has been canceled.
+func cancelStaleOperation(op *terraform.Operation, mg xpresource.Managed, l logging.Logger) bool {
+	if op == nil || !op.IsRunning() || op.IsCanceled() {
+		return false
+	}
+	switch op.Type {
+	case "destroy", "delete":
+		return false
+	}
+	g := op.Generation()
+	if !meta.WasDeleted(mg) && (g == 0 || g == mg.GetGeneration()) {
+		return false
+	}
+	if !op.Cancel() {
+		return false
+	}
+	l.Info("Canceled the stale async operation", "opType", op.Type, "generation", g, "currentGeneration", mg.GetGeneration(), "deleted", meta.WasDeleted(mg))
+	return true
+}
+
 func addTTR(mg xpresource.Managed) {
 	gvk := mg.GetObjectKind().GroupVersionKind()
 	metrics.TTRMeasurements.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).Observe(time.Since(mg.GetCreationTimestamp().Time).Seconds())
 }
 
 func (e *external) Create(ctx context.Context, mg xpresource.Managed) (managed.ExternalCreation, error) {
-	requeued, err := e.scheduleProvider(mg.GetName())
+	requeued, err := e.scheduleProvider(mg)
 	if err != nil {
 		return managed.ExternalCreation{}, errors.Wrapf(err, "cannot schedule a native provider during create: %s", mg.GetUID())
 	}
@@ -369,8 +543,10 @@ func (e *external) Create(ctx context.Context, mg xpresource.Managed) (managed.E
 	}
 	res, err := e.workspace.Apply(ctx)
 	if err != nil {
-		return managed.ExternalCreation{}, errors.Wrap(err, errApply)
+		recordDiagnostics(e.recorder, mg, e.config, err)
+		return managed.ExternalCreation{}, errors.Wrap(backOffTerminalError(e.eventHandler, mg, err), errApply)
 	}
+	clearTerminalError(e.eventHandler, mg)
 	tfstate := map[string]any{}
 	if err := json.JSParser.Unmarshal(res.State.GetAttributes(), &tfstate); err != nil {
 		return managed.ExternalCreation{}, errors.Wrap(err, "cannot unmarshal state attributes")
@@ -382,12 +558,12 @@ func (e *external) Create(ctx context.Context, mg xpresource.Managed) (managed.E
 	}
 
 	// NOTE(muvaf): Only spec and metadata changes are saved after Create call.
-	_, err = resource.SetCriticalAnnotations(tr, e.config, tfstate, string(res.State.GetPrivateRaw()))
+	_, err = e.setCriticalState(ctx, tr, tfstate, string(res.State.GetPrivateRaw()))
 	return managed.ExternalCreation{ConnectionDetails: conn}, errors.Wrap(err, "cannot set critical annotations")
 }
 
 func (e *external) Update(ctx context.Context, mg xpresource.Managed) (managed.ExternalUpdate, error) {
-	requeued, err := e.scheduleProvider(mg.GetName())
+	requeued, err := e.scheduleProvider(mg)
 	if err != nil {
 		return managed.ExternalUpdate{}, errors.Wrapf(err, "cannot schedule a native provider during update: %s", mg.GetUID())
 	}
@@ -396,7 +572,15 @@ func (e *external) Update(ctx context.Context, mg xpresource.Managed) (managed.E
 	}
 	defer e.stopProvider()
 	if e.config.UseAsync {
-		return managed.ExternalUpdate{}, errors.Wrap(e.workspace.ApplyAsync(e.callback.Update(mg.GetName())), errStartAsyncApply)
+		if err := e.workspace.ApplyAsync(e.callback.Update(mg.GetName())); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errStartAsyncApply)
+		}
+		// bind the update operation to the current generation so that
+		// it can be canceled if the spec changes before it completes.
+		if e.operation != nil {
+			e.operation.SetGeneration(mg.GetGeneration())
+		}
+		return managed.ExternalUpdate{}, nil
 	}
 	tr, ok := mg.(resource.Terraformed)
 	if !ok {
@@ -404,8 +588,10 @@ func (e *external) Update(ctx context.Context, mg xpresource.Managed) (managed.E
 	}
 	res, err := e.workspace.Apply(ctx)
 	if err != nil {
-		return managed.ExternalUpdate{}, errors.Wrap(err, errApply)
+		recordDiagnostics(e.recorder, mg, e.config, err)
+		return managed.ExternalUpdate{}, errors.Wrap(backOffTerminalError(e.eventHandler, mg, err), errApply)
 	}
+	clearTerminalError(e.eventHandler, mg)
 	attr := map[string]any{}
 	if err := json.JSParser.Unmarshal(res.State.GetAttributes(), &attr); err != nil {
 		return managed.ExternalUpdate{}, errors.Wrap(err, "cannot unmarshal state attributes")
@@ -414,7 +600,14 @@ func (e *external) Update(ctx context.Context, mg xpresource.Managed) (managed.E
 }
 
 func (e *external) Delete(ctx context.Context, mg xpresource.Managed) error {
-	requeued, err := e.scheduleProvider(mg.GetName())
+	tr, ok := mg.(resource.Terraformed)
+	if !ok {
+		return errors.New(errUnexpectedObject)
+	}
+	if err := checkDeletionProtection(tr, e.config); err != nil {
+		return err
+	}
+	requeued, err := e.scheduleProvider(mg)
 	if err != nil {
 		return errors.Wrapf(err, "cannot schedule a native provider during delete: %s", mg.GetUID())
 	}
@@ -422,10 +615,76 @@ func (e *external) Delete(ctx context.Context, mg xpresource.Managed) error {
 		return nil
 	}
 	defer e.stopProvider()
+	if applying, err := e.applyNativeDeletionProtection(ctx, tr); err != nil || applying {
+		return err
+	}
 	if e.config.UseAsync {
 		return errors.Wrap(e.workspace.DestroyAsync(e.callback.Destroy(mg.GetName())), errStartAsyncDestroy)
 	}
-	return errors.Wrap(e.workspace.Destroy(ctx), errDestroy)
+	err = e.workspace.Destroy(ctx)
+	recordDiagnostics(e.recorder, mg, e.config, err)
+	return errors.Wrap(err, errDestroy)
+}
+
+// applyNativeDeletionProtection applies the workspace configuration, in which
+// the native deletion protection argument has been turned off while
+// connecting, if the native deletion protection of the external resource is
+// observed to be enabled. It reports whether the external resource is still
+// being applied asynchronously, in which case it's to be destroyed after
+// the apply completes. Otherwise, the observation is updated with
+// the applied state so that the argument is not applied again while
+// the external resource is being destroyed.
+func (e *external) applyNativeDeletionProtection(ctx context.Context, tr resource.Terraformed) (bool, error) {
+	// the argument has already been turned off if a destroy operation has
+	// been started.
+	if e.operation != nil && e.operation.Type == "destroy" {
+		return false, nil
+	}
+	protected, err := resource.NativeDeletionProtectionEnabled(tr, e.config)
+	if err != nil {
+		return false, errors.Wrap(err, errDeletionProtection)
+	}
+	if !protected {
+		return false, nil
+	}
+	if e.config.UseAsync {
+		// the destroy operation would cancel the ongoing apply operation,
+		// so the external resource is destroyed after it completes and
+		// the applied state is observed.
+		if e.operation != nil && e.operation.IsRunning() {
+			return true, nil
+		}
+		return true, errors.Wrap(e.workspace.ApplyAsync(e.callback.Update(tr.GetName())), errDisableNativeProtection)
+	}
+	res, err := e.workspace.Apply(ctx)
+	if err != nil {
+		recordDiagnostics(e.recorder, tr, e.config, err)
+		return false, errors.Wrap(err, errDisableNativeProtection)
+	}
+	if res.State == nil {
+		return false, nil
+	}
+	attr := map[string]any{}
+	if err := json.JSParser.Unmarshal(res.State.GetAttributes(), &attr); err != nil {
+		return false, errors.Wrap(err, "cannot unmarshal state attributes")
+	}
+	return false, errors.Wrap(tr.SetObservation(attr), "cannot set observation")
+}
+
+// checkDeletionProtection returns an error explaining why the managed resource
+// cannot be deleted and sets its DeletionProtection condition if it's
+// protected from deletion. The condition is cleared once the deletion is
+// allowed.
+func checkDeletionProtection(tr resource.Terraformed, cfg *config.Resource) error {
+	msg := resource.GetDeletionProtection(tr, cfg)
+	if msg != "" {
+		tr.SetConditions(resource.DeletionBlockedCondition(msg))
+		return errors.New(msg)
+	}
+	if tr.GetCondition(resource.TypeDeletionProtection).Status == corev1.ConditionTrue {
+		tr.SetConditions(resource.DeletionAllowedCondition())
+	}
+	return nil
 }
 
 func (e *external) Import(ctx context.Context, tr resource.Terraformed) (managed.ExternalObservation, error) {
@@ -464,6 +723,9 @@ func (e *external) Import(ctx context.Context, tr resource.Terraformed) (managed
 	if err := tr.SetObservation(tfstate); err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, "cannot set observation")
 	}
+	if err := resource.SetCustomConditions(tr, e.config, tfstate); err != nil {
+		return managed.ExternalObservation{}, err
+	}
 	conn, err := resource.GetConnectionDetails(tfstate, tr, e.config)
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, "cannot get connection details")
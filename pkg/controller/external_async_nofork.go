@@ -23,12 +23,16 @@ import (
 	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
 )
 
-var defaultAsyncTimeout = 1 * time.Hour
+const (
+	defaultAsyncTimeout = 1 * time.Hour
+)
 
 type NoForkAsyncConnector struct {
 	*NoForkConnector
-	callback     CallbackProvider
-	eventHandler *handler.EventHandler
+	callback         CallbackProvider
+	eventHandler     *handler.EventHandler
+	operationLimiter *terraform.OperationLimiter
+	asyncTimeout     time.Duration
 }
 
 type NoForkAsyncOption func(connector *NoForkAsyncConnector)
@@ -36,6 +40,7 @@ type NoForkAsyncOption func(connector *NoForkAsyncConnector)
 func NewNoForkAsyncConnector(kube client.Client, ots *OperationTrackerStore, sf terraform.SetupFn, cfg *config.Resource, opts ...NoForkAsyncOption) *NoForkAsyncConnector {
 	nfac := &NoForkAsyncConnector{
 		NoForkConnector: NewNoForkConnector(kube, sf, cfg, ots),
+		asyncTimeout:    defaultAsyncTimeout,
 	}
 	for _, f := range opts {
 		f(nfac)
@@ -50,9 +55,12 @@ func (c *NoForkAsyncConnector) Connect(ctx context.Context, mg xpresource.Manage
 	}
 
 	return &noForkAsyncExternal{
-		noForkExternal: ec.(*noForkExternal),
-		callback:       c.callback,
-		eventHandler:   c.eventHandler,
+		noForkExternal:   ec.(*noForkExternal),
+		callback:         c.callback,
+		eventHandler:     c.eventHandler,
+		operationLimiter: c.operationLimiter,
+		operationKey:     terraform.NewOperationKey(mg),
+		asyncTimeout:     c.asyncTimeout,
 	}, nil
 }
 
@@ -61,6 +69,7 @@ func (c *NoForkAsyncConnector) Connect(ctx context.Context, mg xpresource.Manage
 func WithNoForkAsyncConnectorEventHandler(e *handler.EventHandler) NoForkAsyncOption {
 	return func(c *NoForkAsyncConnector) {
 		c.eventHandler = e
+		c.NoForkConnector.eventHandler = e
 	}
 }
 
@@ -96,17 +105,96 @@ func WithNoForkAsyncManagementPolicies(isManagementPoliciesEnabled bool) NoForkA
 	}
 }
 
+// WithNoForkAsyncOperationLimiter configures the OperationLimiter that
+// limits the number of concurrently running asynchronous operations.
+func WithNoForkAsyncOperationLimiter(l *terraform.OperationLimiter) NoForkAsyncOption {
+	return func(c *NoForkAsyncConnector) {
+		c.operationLimiter = l
+	}
+}
+
+// WithNoForkAsyncTimeout configures the duration after which
+// the asynchronous operations time out. The timeout of an operation starts
+// once the configured concurrency limits allow it to run.
+func WithNoForkAsyncTimeout(d time.Duration) NoForkAsyncOption {
+	return func(c *NoForkAsyncConnector) {
+		c.asyncTimeout = d
+	}
+}
+
+// WithNoForkAsyncSecretClient configures the SecretClient that resolves
+// the secret references of the sensitive parameters with the specified scheme.
+func WithNoForkAsyncSecretClient(scheme string, sc resource.SecretClient) NoForkAsyncOption {
+	return func(c *NoForkAsyncConnector) {
+		WithNoForkSecretClient(scheme, sc)(c.NoForkConnector)
+	}
+}
+
+// WithNoForkAsyncSecretClients configures the SecretClients that resolve
+// the secret references of the sensitive parameters, keyed by their schemes.
+func WithNoForkAsyncSecretClients(clients map[string]resource.SecretClient) NoForkAsyncOption {
+	return func(c *NoForkAsyncConnector) {
+		WithNoForkSecretClients(clients)(c.NoForkConnector)
+	}
+}
+
+// WithNoForkAsyncPrivateStateStore configures the store the Terraform
+// private states of the managed resources are stored in.
+func WithNoForkAsyncPrivateStateStore(ps resource.PrivateStateStore) NoForkAsyncOption {
+	return func(c *NoForkAsyncConnector) {
+		WithNoForkPrivateStateStore(ps)(c.NoForkConnector)
+	}
+}
+
+// WithNoForkAsyncErrorClassifier configures the Classifier the errors of
+// the Terraform operations are classified with, e.g., to tell the terminal
+// errors from the retryable ones. A nil Classifier keeps the default one
+// with the tferrors.DefaultClassificationRules.
+func WithNoForkAsyncErrorClassifier(ec *tferrors.Classifier) NoForkAsyncOption {
+	return func(c *NoForkAsyncConnector) {
+		WithNoForkErrorClassifier(ec)(c.NoForkConnector)
+	}
+}
+
 type noForkAsyncExternal struct {
 	*noForkExternal
-	callback     CallbackProvider
-	eventHandler *handler.EventHandler
+	callback         CallbackProvider
+	eventHandler     *handler.EventHandler
+	operationLimiter *terraform.OperationLimiter
+	operationKey     terraform.OperationKey
+	asyncTimeout     time.Duration
 }
 
 type CallbackFn func(error, context.Context) error
 
+// runLimited runs the specified asynchronous operation once the configured
+// concurrency limits allow it to run. The operation times out after
+// the configured async timeout, which starts when the slot is acquired so that
+// the time spent waiting in the queue does not count against the operation's
+// deadline.
+func (n *noForkAsyncExternal) runLimited(ctx context.Context, op func(ctx context.Context) error) error {
+	release, err := n.operationLimiter.Acquire(ctx, n.operationKey)
+	if err != nil {
+		return errors.Wrap(err, "cannot acquire a slot for the queued async operation")
+	}
+	defer release()
+	ctx, cancel := context.WithTimeout(ctx, n.asyncTimeout)
+	defer cancel()
+	return op(ctx)
+}
+
+// callbackContext returns the context the callbacks of the asynchronous
+// operations are run with. It's not derived from the context of
+// the operation, so that the callbacks can still report the results of
+// the canceled operations.
+func callbackContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), terraform.CallbackTimeout)
+}
+
 func (n *noForkAsyncExternal) Observe(ctx context.Context, mg xpresource.Managed) (managed.ExternalObservation, error) {
 	if n.opTracker.LastOperation.IsRunning() {
 		n.logger.WithValues("opType", n.opTracker.LastOperation.Type).Debug("ongoing async operation")
+		cancelStaleOperation(n.opTracker.LastOperation, mg, n.logger)
 		return managed.ExternalObservation{
 			ResourceExists:   true,
 			ResourceUpToDate: true,
@@ -129,18 +217,23 @@ func (n *noForkAsyncExternal) Create(_ context.Context, mg xpresource.Managed) (
 		return managed.ExternalCreation{}, errors.Errorf("%s operation that started at %s is still running", n.opTracker.LastOperation.Type, n.opTracker.LastOperation.StartTime().String())
 	}
 
-	ctx, cancel := context.WithDeadline(context.Background(), n.opTracker.LastOperation.StartTime().Add(defaultAsyncTimeout))
+	ctx, cancel := n.opTracker.LastOperation.WithCancel(context.Background())
 	go func() {
 		defer cancel()
 
 		n.opTracker.logger.Debug("Async create starting...", "tfID", n.opTracker.GetTfID())
-		_, err := n.noForkExternal.Create(ctx, mg)
+		err := n.runLimited(ctx, func(ctx context.Context) error {
+			_, err := n.noForkExternal.create(ctx, mg)
+			return err
+		})
 		err = tferrors.NewAsyncCreateFailed(err)
 		n.opTracker.LastOperation.SetError(err)
 		n.opTracker.logger.Debug("Async create ended.", "error", err, "tfID", n.opTracker.GetTfID())
 
 		n.opTracker.LastOperation.MarkEnd()
-		if cErr := n.callback.Create(mg.GetName())(err, ctx); cErr != nil {
+		cbCtx, cbCancel := callbackContext()
+		defer cbCancel()
+		if cErr := n.callback.Create(mg.GetName())(err, cbCtx); cErr != nil {
 			n.opTracker.logger.Info("Async create callback failed", "error", cErr.Error())
 		}
 	}()
@@ -152,19 +245,27 @@ func (n *noForkAsyncExternal) Update(_ context.Context, mg xpresource.Managed) (
 	if !n.opTracker.LastOperation.MarkStart("update") {
 		return managed.ExternalUpdate{}, errors.Errorf("%s operation that started at %s is still running", n.opTracker.LastOperation.Type, n.opTracker.LastOperation.StartTime().String())
 	}
+	// bind the update operation to the current generation so that it can be
+	// canceled if the spec changes before it completes.
+	n.opTracker.LastOperation.SetGeneration(mg.GetGeneration())
 
-	ctx, cancel := context.WithDeadline(context.Background(), n.opTracker.LastOperation.StartTime().Add(defaultAsyncTimeout))
+	ctx, cancel := n.opTracker.LastOperation.WithCancel(context.Background())
 	go func() {
 		defer cancel()
 
 		n.opTracker.logger.Debug("Async update starting...", "tfID", n.opTracker.GetTfID())
-		_, err := n.noForkExternal.Update(ctx, mg)
+		err := n.runLimited(ctx, func(ctx context.Context) error {
+			_, err := n.noForkExternal.update(ctx, mg)
+			return err
+		})
 		err = tferrors.NewAsyncUpdateFailed(err)
 		n.opTracker.LastOperation.SetError(err)
 		n.opTracker.logger.Debug("Async update ended.", "error", err, "tfID", n.opTracker.GetTfID())
 
 		n.opTracker.LastOperation.MarkEnd()
-		if cErr := n.callback.Update(mg.GetName())(err, ctx); cErr != nil {
+		cbCtx, cbCancel := callbackContext()
+		defer cbCancel()
+		if cErr := n.callback.Update(mg.GetName())(err, cbCtx); cErr != nil {
 			n.opTracker.logger.Info("Async update callback failed", "error", cErr.Error())
 		}
 	}()
@@ -173,25 +274,35 @@ func (n *noForkAsyncExternal) Update(_ context.Context, mg xpresource.Managed) (
 }
 
 func (n *noForkAsyncExternal) Delete(_ context.Context, mg xpresource.Managed) error {
+	if err := checkDeletionProtection(mg.(resource.Terraformed), n.config); err != nil {
+		return err
+	}
 	switch {
 	case n.opTracker.LastOperation.Type == "delete":
 		n.opTracker.logger.Debug("The previous delete operation is still ongoing", "tfID", n.opTracker.GetTfID())
 		return nil
 	case !n.opTracker.LastOperation.MarkStart("delete"):
+		// the ongoing create or update operation is stale, and we interrupt it
+		// so that the delete operation can start as soon as possible.
+		cancelStaleOperation(n.opTracker.LastOperation, mg, n.logger)
 		return errors.Errorf("%s operation that started at %s is still running", n.opTracker.LastOperation.Type, n.opTracker.LastOperation.StartTime().String())
 	}
 
-	ctx, cancel := context.WithDeadline(context.Background(), n.opTracker.LastOperation.StartTime().Add(defaultAsyncTimeout))
+	ctx, cancel := n.opTracker.LastOperation.WithCancel(context.Background())
 	go func() {
 		defer cancel()
 
 		n.opTracker.logger.Debug("Async delete starting...", "tfID", n.opTracker.GetTfID())
-		err := tferrors.NewAsyncDeleteFailed(n.noForkExternal.Delete(ctx, mg))
+		err := tferrors.NewAsyncDeleteFailed(n.runLimited(ctx, func(ctx context.Context) error {
+			return n.noForkExternal.Delete(ctx, mg)
+		}))
 		n.opTracker.LastOperation.SetError(err)
 		n.opTracker.logger.Debug("Async delete ended.", "error", err, "tfID", n.opTracker.GetTfID())
 
 		n.opTracker.LastOperation.MarkEnd()
-		if cErr := n.callback.Destroy(mg.GetName())(err, ctx); cErr != nil {
+		cbCtx, cbCancel := callbackContext()
+		defer cbCancel()
+		if cErr := n.callback.Destroy(mg.GetName())(err, cbCtx); cErr != nil {
 			n.opTracker.logger.Info("Async delete callback failed", "error", cErr.Error())
 		}
 	}()
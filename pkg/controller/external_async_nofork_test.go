@@ -7,6 +7,7 @@ package controller
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
@@ -98,6 +99,18 @@ func prepareNoForkAsyncExternal(r Resource, cfg *config.Resource, fns CallbackFn
 	}
 }
 
+// waitFor returns a callback function that calls the specified one and then
+// closes the done channel, so that the tests can wait for the asynchronous
+// operations to complete.
+func waitFor(done chan struct{}, fn func(string) terraform.CallbackFn) func(string) terraform.CallbackFn {
+	return func(name string) terraform.CallbackFn {
+		return func(err error, ctx context.Context) error {
+			defer close(done)
+			return fn(name)(err, ctx)
+		}
+	}
+}
+
 func TestAsyncNoForkConnect(t *testing.T) {
 	type args struct {
 		setupFn terraform.SetupFn
@@ -238,8 +251,12 @@ func TestAsyncNoForkCreate(t *testing.T) {
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			noForkAsyncExternal := prepareNoForkAsyncExternal(tc.args.r, tc.args.cfg, tc.args.fns)
+			done := make(chan struct{})
+			fns := tc.args.fns
+			fns.CreateFn = waitFor(done, fns.CreateFn)
+			noForkAsyncExternal := prepareNoForkAsyncExternal(tc.args.r, tc.args.cfg, fns)
 			_, err := noForkAsyncExternal.Create(context.TODO(), tc.args.obj)
+			<-done
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nConnect(...): -want error, +got error:\n", diff)
 			}
@@ -282,8 +299,12 @@ func TestAsyncNoForkUpdate(t *testing.T) {
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			noForkAsyncExternal := prepareNoForkAsyncExternal(tc.args.r, tc.args.cfg, tc.args.fns)
+			done := make(chan struct{})
+			fns := tc.args.fns
+			fns.UpdateFn = waitFor(done, fns.UpdateFn)
+			noForkAsyncExternal := prepareNoForkAsyncExternal(tc.args.r, tc.args.cfg, fns)
 			_, err := noForkAsyncExternal.Update(context.TODO(), tc.args.obj)
+			<-done
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nConnect(...): -want error, +got error:\n", diff)
 			}
@@ -326,11 +347,88 @@ func TestAsyncNoForkDelete(t *testing.T) {
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			noForkAsyncExternal := prepareNoForkAsyncExternal(tc.args.r, tc.args.cfg, tc.args.fns)
+			done := make(chan struct{})
+			fns := tc.args.fns
+			fns.DestroyFn = waitFor(done, fns.DestroyFn)
+			noForkAsyncExternal := prepareNoForkAsyncExternal(tc.args.r, tc.args.cfg, fns)
 			err := noForkAsyncExternal.Delete(context.TODO(), tc.args.obj)
+			<-done
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nConnect(...): -want error, +got error:\n", diff)
 			}
 		})
 	}
 }
+
+func TestAsyncNoForkRunLimitedDeadline(t *testing.T) {
+	timeout := 100 * time.Millisecond
+	l := terraform.NewOperationLimiter(terraform.WithKindLimit(1))
+	k := terraform.OperationKey{ProviderConfig: "default"}
+	n := &noForkAsyncExternal{operationLimiter: l, operationKey: k, asyncTimeout: timeout}
+	release, err := l.Acquire(context.Background(), k)
+	if err != nil {
+		t.Fatalf("Acquire(...): unexpected error: %v", err)
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- n.runLimited(context.Background(), func(ctx context.Context) error {
+			return ctx.Err()
+		})
+	}()
+	// the queued operation waits longer than the operation timeout for
+	// the slot to be released.
+	time.Sleep(2 * timeout)
+	release()
+	if diff := cmp.Diff(nil, <-errCh, test.EquateErrors()); diff != "" {
+		t.Errorf("runLimited(...): -want error, +got error:\n%s", diff)
+	}
+}
+
+func TestAsyncNoForkCreateCanceled(t *testing.T) {
+	started := make(chan struct{})
+	r := mockResource{
+		ApplyFn: func(ctx context.Context, s *tf.InstanceState, d *tf.InstanceDiff, meta interface{}) (*tf.InstanceState, diag.Diagnostics) {
+			close(started)
+			<-ctx.Done()
+			return nil, diag.FromErr(ctx.Err())
+		},
+	}
+	type result struct {
+		err    error
+		ctxErr error
+	}
+	results := make(chan result, 1)
+	fns := CallbackFns{
+		CreateFn: func(string) terraform.CallbackFn {
+			return func(err error, ctx context.Context) error {
+				results <- result{err: err, ctxErr: ctx.Err()}
+				return nil
+			}
+		},
+	}
+	obj := &fake.Terraformed{
+		Parameterizable: fake.Parameterizable{
+			Parameters: map[string]any{
+				"name": "example",
+			},
+		},
+		Observable: fake.Observable{
+			Observation: map[string]any{},
+		},
+	}
+	n := prepareNoForkAsyncExternal(r, cfgAsync, fns)
+	if _, err := n.Create(context.TODO(), obj); err != nil {
+		t.Fatalf("Create(...): unexpected error: %v", err)
+	}
+	<-started
+	n.opTracker.LastOperation.Cancel()
+	got := <-results
+	if got.err == nil {
+		t.Error("Create(...): want the callback to be called with the error of the canceled operation, got nil")
+	}
+	// the callback must be able to report the result of the canceled
+	// operation.
+	if diff := cmp.Diff(nil, got.ctxErr, test.EquateErrors()); diff != "" {
+		t.Errorf("Create(...): -want callback context error, +got callback context error:\n%s", diff)
+	}
+}
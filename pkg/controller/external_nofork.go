@@ -11,6 +11,7 @@ import (
 	"time"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
@@ -25,10 +26,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/upjet/pkg/config"
+	"github.com/crossplane/upjet/pkg/controller/handler"
 	"github.com/crossplane/upjet/pkg/metrics"
 	"github.com/crossplane/upjet/pkg/resource"
 	"github.com/crossplane/upjet/pkg/resource/json"
 	"github.com/crossplane/upjet/pkg/terraform"
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
 )
 
 type NoForkConnector struct {
@@ -39,6 +42,11 @@ type NoForkConnector struct {
 	metricRecorder              *metrics.MetricRecorder
 	operationTrackerStore       *OperationTrackerStore
 	isManagementPoliciesEnabled bool
+	secretClients               map[string]resource.SecretClient
+	classifier                  *tferrors.Classifier
+	privateStateStore           resource.PrivateStateStore
+	recorder                    event.Recorder
+	eventHandler                *handler.EventHandler
 }
 
 // NoForkOption allows you to configure NoForkConnector.
@@ -67,12 +75,74 @@ func WithNoForkManagementPolicies(isManagementPoliciesEnabled bool) NoForkOption
 	}
 }
 
+// WithNoForkSecretClient configures the SecretClient that resolves the secret
+// references of the sensitive parameters with the specified scheme.
+func WithNoForkSecretClient(scheme string, sc resource.SecretClient) NoForkOption {
+	return func(c *NoForkConnector) {
+		if c.secretClients == nil {
+			c.secretClients = make(map[string]resource.SecretClient)
+		}
+		c.secretClients[scheme] = sc
+	}
+}
+
+// WithNoForkSecretClients configures the SecretClients that resolve
+// the secret references of the sensitive parameters, keyed by their schemes.
+func WithNoForkSecretClients(clients map[string]resource.SecretClient) NoForkOption {
+	return func(c *NoForkConnector) {
+		for scheme, sc := range clients {
+			WithNoForkSecretClient(scheme, sc)(c)
+		}
+	}
+}
+
+// WithNoForkErrorClassifier configures the Classifier the errors of
+// the Terraform operations are classified with, e.g., to tell the terminal
+// errors from the retryable ones. A nil Classifier keeps the default one
+// with the tferrors.DefaultClassificationRules.
+func WithNoForkErrorClassifier(ec *tferrors.Classifier) NoForkOption {
+	return func(c *NoForkConnector) {
+		if ec != nil {
+			c.classifier = ec
+		}
+	}
+}
+
+// WithNoForkEventRecorder configures the event recorder the Terraform
+// diagnostics of the failed create, update and delete operations are
+// recorded with. The diagnostics of the asynchronous operations are recorded
+// by the CallbackProvider instead.
+func WithNoForkEventRecorder(r event.Recorder) NoForkOption {
+	return func(c *NoForkConnector) {
+		c.recorder = r
+	}
+}
+
+// WithNoForkEventHandler configures the EventHandler the Terraform operations
+// of the no-fork external clients are backed off with after the terminal
+// errors.
+func WithNoForkEventHandler(e *handler.EventHandler) NoForkOption {
+	return func(c *NoForkConnector) {
+		c.eventHandler = e
+	}
+}
+
+// WithNoForkPrivateStateStore configures the store the Terraform private
+// states of the managed resources are stored in. If not configured,
+// the private states are only kept in the persisted instance states.
+func WithNoForkPrivateStateStore(s resource.PrivateStateStore) NoForkOption {
+	return func(c *NoForkConnector) {
+		c.privateStateStore = s
+	}
+}
+
 func NewNoForkConnector(kube client.Client, sf terraform.SetupFn, cfg *config.Resource, ots *OperationTrackerStore, opts ...NoForkOption) *NoForkConnector {
 	nfc := &NoForkConnector{
 		kube:                  kube,
 		getTerraformSetup:     sf,
 		config:                cfg,
 		operationTrackerStore: ots,
+		classifier:            tferrors.NewClassifier(tferrors.DefaultClassificationRules()...),
 	}
 	for _, f := range opts {
 		f(nfc)
@@ -109,23 +179,27 @@ type Resource interface {
 }
 
 type noForkExternal struct {
-	ts             terraform.Setup
-	resourceSchema Resource
-	config         *config.Resource
-	instanceDiff   *tf.InstanceDiff
-	params         map[string]any
-	rawConfig      cty.Value
-	logger         logging.Logger
-	metricRecorder *metrics.MetricRecorder
-	opTracker      *AsyncTracker
-}
-
-func getExtendedParameters(ctx context.Context, tr resource.Terraformed, externalName string, config *config.Resource, ts terraform.Setup, initParamsMerged bool, kube client.Client) (map[string]any, error) {
+	ts                terraform.Setup
+	resourceSchema    Resource
+	config            *config.Resource
+	instanceDiff      *tf.InstanceDiff
+	params            map[string]any
+	rawConfig         cty.Value
+	logger            logging.Logger
+	metricRecorder    *metrics.MetricRecorder
+	opTracker         *AsyncTracker
+	classifier        *tferrors.Classifier
+	privateStateStore resource.PrivateStateStore
+	recorder          event.Recorder
+	eventHandler      *handler.EventHandler
+}
+
+func getExtendedParameters(ctx context.Context, tr resource.Terraformed, externalName string, config *config.Resource, ts terraform.Setup, initParamsMerged bool, sc resource.SecretClient) (map[string]any, error) {
 	params, err := tr.GetMergedParameters(initParamsMerged)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot get merged parameters")
 	}
-	if err = resource.GetSensitiveParameters(ctx, &APISecretClient{kube: kube}, tr, params, tr.GetConnectionDetailsMapping()); err != nil {
+	if err = resource.GetSensitiveParameters(ctx, sc, tr, params, tr.GetConnectionDetailsMapping()); err != nil {
 		return nil, errors.Wrap(err, "cannot store sensitive parameters into params")
 	}
 	config.ExternalName.SetIdentifierArgumentFn(params, externalName)
@@ -240,12 +314,19 @@ func (c *NoForkConnector) Connect(ctx context.Context, mg xpresource.Managed) (m
 
 	// To Compute the ResourceDiff: n.resourceSchema.Diff(...)
 	tr := mg.(resource.Terraformed)
+	if err := resetLateInitializedFields(ctx, c.kube, tr); err != nil {
+		return nil, err
+	}
 	opTracker := c.operationTrackerStore.Tracker(tr)
 	externalName := meta.GetExternalName(tr)
-	params, err := getExtendedParameters(ctx, tr, externalName, c.config, ts, c.isManagementPoliciesEnabled, c.kube)
+	sc := newSecretClient(c.kube, c.secretClients)
+	params, err := getExtendedParameters(ctx, tr, externalName, c.config, ts, c.isManagementPoliciesEnabled, sc)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get the extended parameters for resource %q", mg.GetName())
 	}
+	if _, err := resource.DisableNativeDeletionProtection(tr, c.config, params); err != nil {
+		return nil, errors.Wrap(err, errDeletionProtection)
+	}
 	params = c.processParamsWithStateFunc(c.config.TerraformResource.Schema, params)
 
 	schemaBlock := c.config.TerraformResource.CoreConfigSchema()
@@ -253,6 +334,21 @@ func (c *NoForkConnector) Connect(ctx context.Context, mg xpresource.Managed) (m
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to convert params JSON map to cty.Value")
 	}
+	if !opTracker.HasState() {
+		s, err := opTracker.LoadTfState(ctx, tr)
+		if err != nil {
+			logger.Info("Cannot load the persisted instance state, reconstructing...", "error", err)
+		}
+		if s != nil {
+			logger.Debug("Instance state not found in cache, restored the persisted state")
+			s.RawPlan, err = s.AttrsAsObjectValue(schemaBlock.ImpliedType())
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot convert the persisted instance state attributes to cty.Value")
+			}
+			s.RawConfig = rawConfig
+			opTracker.SetTfState(s)
+		}
+	}
 	if !opTracker.HasState() {
 		logger.Debug("Instance state not found in cache, reconstructing...")
 		tfState, err := tr.GetObservation()
@@ -260,7 +356,7 @@ func (c *NoForkConnector) Connect(ctx context.Context, mg xpresource.Managed) (m
 			return nil, errors.Wrap(err, "failed to get the observation")
 		}
 		copyParams := len(tfState) == 0
-		if err = resource.GetSensitiveParameters(ctx, &APISecretClient{kube: c.kube}, tr, tfState, tr.GetConnectionDetailsMapping()); err != nil {
+		if err = resource.GetSensitiveParameters(ctx, sc, tr, tfState, tr.GetConnectionDetailsMapping()); err != nil {
 			return nil, errors.Wrap(err, "cannot store sensitive parameters into tfState")
 		}
 		c.config.ExternalName.SetIdentifierArgumentFn(tfState, externalName)
@@ -279,21 +375,44 @@ func (c *NoForkConnector) Connect(ctx context.Context, mg xpresource.Managed) (m
 		}
 		s.RawPlan = tfStateCtyValue
 		s.RawConfig = rawConfig
+		if err := c.loadPrivateState(ctx, tr, s); err != nil {
+			return nil, err
+		}
 		opTracker.SetTfState(s)
 	}
 
 	return &noForkExternal{
-		ts:             ts,
-		resourceSchema: c.config.TerraformResource,
-		config:         c.config,
-		params:         params,
-		rawConfig:      rawConfig,
-		logger:         logger,
-		metricRecorder: c.metricRecorder,
-		opTracker:      opTracker,
+		ts:                ts,
+		resourceSchema:    c.config.TerraformResource,
+		config:            c.config,
+		params:            params,
+		rawConfig:         rawConfig,
+		logger:            logger,
+		metricRecorder:    c.metricRecorder,
+		opTracker:         opTracker,
+		classifier:        c.classifier,
+		privateStateStore: c.privateStateStore,
+		recorder:          c.recorder,
+		eventHandler:      c.eventHandler,
 	}, nil
 }
 
+// loadPrivateState restores the private state of the reconstructed instance
+// state from the configured PrivateStateStore, if any.
+func (c *NoForkConnector) loadPrivateState(ctx context.Context, tr resource.Terraformed, s *tf.InstanceState) error {
+	if c.privateStateStore == nil {
+		return nil
+	}
+	privateState, err := c.privateStateStore.Get(ctx, tr)
+	if err != nil {
+		return errors.Wrap(err, errGetPrivateState)
+	}
+	if privateState == "" {
+		return nil
+	}
+	return errors.Wrap(json.TFParser.Unmarshal([]byte(privateState), &s.Meta), errUnmarshalPrivateState)
+}
+
 func filterInitExclusiveDiffs(tr resource.Terraformed, instanceDiff *tf.InstanceDiff) error { //nolint:gocyclo
 	if instanceDiff == nil || instanceDiff.Empty() {
 		return nil
@@ -456,22 +575,29 @@ func (n *noForkExternal) Observe(ctx context.Context, mg xpresource.Managed) (ma
 			ResourceExists: false,
 		}, nil
 	}
+	if err := backedOffError(n.eventHandler, mg); err != nil {
+		return managed.ExternalObservation{}, err
+	}
 
 	start := time.Now()
 	newState, diag := n.resourceSchema.RefreshWithoutUpgrade(ctx, n.opTracker.GetTfState(), n.ts.Meta)
 	metrics.ExternalAPITime.WithLabelValues("read").Observe(time.Since(start).Seconds())
 	if diag != nil && diag.HasError() {
-		return managed.ExternalObservation{}, errors.Errorf("failed to observe the resource: %v", diag)
+		return managed.ExternalObservation{}, backOffTerminalError(n.eventHandler, mg, n.diagnosticsError(errors.Errorf("failed to observe the resource: %v", diag), diag))
 	}
 	n.opTracker.SetTfState(newState) // TODO: missing RawConfig & RawPlan here...
+	n.persistTfState(ctx, mg)
 
 	resourceExists := newState != nil && newState.ID != ""
 	instanceDiff, err := n.getResourceDataDiff(mg.(resource.Terraformed), ctx, newState, resourceExists)
 	if err != nil {
-		return managed.ExternalObservation{}, errors.Wrap(err, "cannot compute the instance diff")
+		return managed.ExternalObservation{}, errors.Wrap(backOffTerminalError(n.eventHandler, mg, n.classifier.Classify(err)), "cannot compute the instance diff")
 	}
 	n.instanceDiff = instanceDiff
 	noDiff := instanceDiff.Empty()
+	if resourceExists && noDiff {
+		clearTerminalError(n.eventHandler, mg)
+	}
 
 	var connDetails managed.ConnectionDetails
 	if !resourceExists && mg.GetDeletionTimestamp() != nil {
@@ -480,15 +606,28 @@ func (n *noForkExternal) Observe(ctx context.Context, mg xpresource.Managed) (ma
 	}
 	specUpdateRequired := false
 	if resourceExists {
-		if mg.GetCondition(xpv1.TypeReady).Status == corev1.ConditionUnknown ||
-			mg.GetCondition(xpv1.TypeReady).Status == corev1.ConditionFalse {
-			addTTR(mg)
-		}
-		mg.SetConditions(xpv1.Available())
 		stateValueMap, err := n.fromInstanceStateToJSONMap(newState)
 		if err != nil {
 			return managed.ExternalObservation{}, errors.Wrap(err, "cannot convert instance state to JSON map")
 		}
+		// the readiness checks are evaluated until the resource is marked
+		// as available as in the CLI-based external client
+		ready, notReadyMsg := true, ""
+		if !mg.GetCondition(xpv1.TypeReady).Equal(xpv1.Available()) {
+			ready, notReadyMsg, err = n.config.Readiness.IsReady(stateValueMap)
+			if err != nil {
+				return managed.ExternalObservation{}, err
+			}
+		}
+		if ready {
+			if mg.GetCondition(xpv1.TypeReady).Status == corev1.ConditionUnknown ||
+				mg.GetCondition(xpv1.TypeReady).Status == corev1.ConditionFalse {
+				addTTR(mg)
+			}
+			mg.SetConditions(xpv1.Available())
+		} else {
+			mg.SetConditions(xpv1.Unavailable().WithMessage(notReadyMsg))
+		}
 
 		buff, err := json.TFParser.Marshal(stateValueMap)
 		if err != nil {
@@ -503,6 +642,9 @@ func (n *noForkExternal) Observe(ctx context.Context, mg xpresource.Managed) (ma
 		if err != nil {
 			return managed.ExternalObservation{}, errors.Errorf("could not set observation: %v", err)
 		}
+		if err := resource.SetCustomConditions(mg, n.config, stateValueMap); err != nil {
+			return managed.ExternalObservation{}, err
+		}
 		connDetails, err = resource.GetConnectionDetails(stateValueMap, mg.(resource.Terraformed), n.config)
 		if err != nil {
 			return managed.ExternalObservation{}, errors.Wrap(err, "cannot get connection details")
@@ -520,6 +662,11 @@ func (n *noForkExternal) Observe(ctx context.Context, mg xpresource.Managed) (ma
 		} else {
 			specUpdateRequired = specUpdateRequired || nameChanged
 		}
+		privateStateChanged, err := n.storePrivateState(ctx, mg, newState)
+		if err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		specUpdateRequired = specUpdateRequired || privateStateChanged
 	}
 
 	return managed.ExternalObservation{
@@ -530,6 +677,21 @@ func (n *noForkExternal) Observe(ctx context.Context, mg xpresource.Managed) (ma
 	}, nil
 }
 
+// storePrivateState stores the private state of the specified instance state
+// with the configured PrivateStateStore, if any, and reports whether the MR
+// has been modified.
+func (n *noForkExternal) storePrivateState(ctx context.Context, mg xpresource.Managed, newState *tf.InstanceState) (bool, error) {
+	if n.privateStateStore == nil {
+		return false, nil
+	}
+	privateState, err := json.TFParser.Marshal(newState.Meta)
+	if err != nil {
+		return false, errors.Wrap(err, errMarshalPrivateState)
+	}
+	changed, err := n.privateStateStore.Set(ctx, mg, string(privateState))
+	return changed, errors.Wrap(err, errSetPrivateState)
+}
+
 // sets the external-name on the MR. Returns `true`
 // if the external-name of the MR has changed.
 func (n *noForkExternal) setExternalName(mg xpresource.Managed, newState *tf.InstanceState) (bool, error) {
@@ -549,19 +711,33 @@ func (n *noForkExternal) setExternalName(mg xpresource.Managed, newState *tf.Ins
 }
 
 func (n *noForkExternal) Create(ctx context.Context, mg xpresource.Managed) (managed.ExternalCreation, error) {
+	c, err := n.create(ctx, mg)
+	if err != nil {
+		return c, backOffTerminalError(n.eventHandler, mg, err)
+	}
+	clearTerminalError(n.eventHandler, mg)
+	return c, nil
+}
+
+// create creates the external resource. Unlike Create, it does not report
+// the terminal errors on the managed resource, so that it can be run
+// asynchronously.
+func (n *noForkExternal) create(ctx context.Context, mg xpresource.Managed) (managed.ExternalCreation, error) {
 	n.logger.Debug("Creating the external resource")
 	start := time.Now()
 	newState, diag := n.resourceSchema.Apply(ctx, n.opTracker.GetTfState(), n.instanceDiff, n.ts.Meta)
 	metrics.ExternalAPITime.WithLabelValues("create").Observe(time.Since(start).Seconds())
 	// diag := n.resourceSchema.CreateWithoutTimeout(ctx, n.resourceData, n.ts.Meta)
 	if diag != nil && diag.HasError() {
-		return managed.ExternalCreation{}, errors.Errorf("failed to create the resource: %v", diag)
+		n.trackPartialState(mg, newState)
+		return managed.ExternalCreation{}, n.operationError(mg, errors.Errorf("failed to create the resource: %v", diag), diag)
 	}
 
 	if newState == nil || newState.ID == "" {
 		return managed.ExternalCreation{}, errors.New("failed to read the ID of the new resource")
 	}
 	n.opTracker.SetTfState(newState)
+	n.persistTfState(ctx, mg)
 
 	if _, err := n.setExternalName(mg, newState); err != nil {
 		return managed.ExternalCreation{}, errors.Wrapf(err, "failed to set the external-name of the managed resource during create")
@@ -603,6 +779,18 @@ func (n *noForkExternal) assertNoForceNew() error {
 }
 
 func (n *noForkExternal) Update(ctx context.Context, mg xpresource.Managed) (managed.ExternalUpdate, error) {
+	u, err := n.update(ctx, mg)
+	if err != nil {
+		return u, backOffTerminalError(n.eventHandler, mg, err)
+	}
+	clearTerminalError(n.eventHandler, mg)
+	return u, nil
+}
+
+// update updates the external resource. Unlike Update, it does not report
+// the terminal errors on the managed resource, so that it can be run
+// asynchronously.
+func (n *noForkExternal) update(ctx context.Context, mg xpresource.Managed) (managed.ExternalUpdate, error) {
 	n.logger.Debug("Updating the external resource")
 
 	if err := n.assertNoForceNew(); err != nil {
@@ -613,9 +801,11 @@ func (n *noForkExternal) Update(ctx context.Context, mg xpresource.Managed) (man
 	newState, diag := n.resourceSchema.Apply(ctx, n.opTracker.GetTfState(), n.instanceDiff, n.ts.Meta)
 	metrics.ExternalAPITime.WithLabelValues("update").Observe(time.Since(start).Seconds())
 	if diag != nil && diag.HasError() {
-		return managed.ExternalUpdate{}, errors.Errorf("failed to update the resource: %v", diag)
+		n.trackPartialState(mg, newState)
+		return managed.ExternalUpdate{}, n.operationError(mg, errors.Errorf("failed to update the resource: %v", diag), diag)
 	}
 	n.opTracker.SetTfState(newState)
+	n.persistTfState(ctx, mg)
 
 	stateValueMap, err := n.fromInstanceStateToJSONMap(newState)
 	if err != nil {
@@ -629,8 +819,14 @@ func (n *noForkExternal) Update(ctx context.Context, mg xpresource.Managed) (man
 	return managed.ExternalUpdate{}, nil
 }
 
-func (n *noForkExternal) Delete(ctx context.Context, _ xpresource.Managed) error {
+func (n *noForkExternal) Delete(ctx context.Context, mg xpresource.Managed) error {
 	n.logger.Debug("Deleting the external resource")
+	if err := checkDeletionProtection(mg.(resource.Terraformed), n.config); err != nil {
+		return err
+	}
+	if err := n.applyNativeDeletionProtection(ctx, mg); err != nil {
+		return err
+	}
 	if n.instanceDiff == nil {
 		n.instanceDiff = tf.NewInstanceDiff()
 	}
@@ -640,14 +836,78 @@ func (n *noForkExternal) Delete(ctx context.Context, _ xpresource.Managed) error
 	newState, diag := n.resourceSchema.Apply(ctx, n.opTracker.GetTfState(), n.instanceDiff, n.ts.Meta)
 	metrics.ExternalAPITime.WithLabelValues("delete").Observe(time.Since(start).Seconds())
 	if diag != nil && diag.HasError() {
-		return errors.Errorf("failed to delete the resource: %v", diag)
+		return n.operationError(mg, errors.Errorf("failed to delete the resource: %v", diag), diag)
 	}
 	n.opTracker.SetTfState(newState)
+	n.persistTfState(ctx, mg)
 	// mark the resource as logically deleted if the TF call clears the state
 	n.opTracker.SetDeleted(newState == nil)
 	return nil
 }
 
+// applyNativeDeletionProtection applies the instance diff, which turns off the
+// native deletion protection argument disabled in the parameters while
+// connecting, if the native deletion protection of the external resource is
+// observed to be enabled.
+func (n *noForkExternal) applyNativeDeletionProtection(ctx context.Context, mg xpresource.Managed) error {
+	protected, err := resource.NativeDeletionProtectionEnabled(mg.(resource.Terraformed), n.config)
+	if err != nil {
+		return errors.Wrap(err, errDeletionProtection)
+	}
+	if !protected || n.instanceDiff == nil || n.instanceDiff.Empty() {
+		return nil
+	}
+	if err := n.assertNoForceNew(); err != nil {
+		return errors.Wrap(err, errDisableNativeProtection)
+	}
+	newState, diag := n.resourceSchema.Apply(ctx, n.opTracker.GetTfState(), n.instanceDiff, n.ts.Meta)
+	if diag != nil && diag.HasError() {
+		n.trackPartialState(mg, newState)
+		return n.operationError(mg, errors.Errorf("%s: %v", errDisableNativeProtection, diag), diag)
+	}
+	n.opTracker.SetTfState(newState)
+	n.persistTfState(ctx, mg)
+	n.instanceDiff = nil
+	return nil
+}
+
+// diagnosticsError annotates the specified error of a Terraform operation
+// with the operation's diagnostics and classifies it.
+func (n *noForkExternal) diagnosticsError(err error, diags diag.Diagnostics) error {
+	return n.classifier.Classify(withDiagnostics(err, diags))
+}
+
+// operationError returns the diagnosticsError of a failed create, update or
+// delete operation on the specified managed resource and records its
+// diagnostics as events, like the CLI-based external client does.
+func (n *noForkExternal) operationError(mg xpresource.Managed, err error, diags diag.Diagnostics) error {
+	err = n.diagnosticsError(err, diags)
+	recordDiagnostics(n.recorder, mg, n.config, err)
+	return err
+}
+
+// persistTfState persists the tracked instance state. A failure to persist
+// the state is not fatal as the state is still tracked in memory, and it
+// can be reconstructed from the managed resource if lost.
+func (n *noForkExternal) persistTfState(ctx context.Context, mg xpresource.Managed) {
+	if err := n.opTracker.PersistTfState(ctx, mg); err != nil {
+		n.logger.Info("Cannot persist the Terraform instance state", "error", err)
+	}
+}
+
+// trackPartialState keeps track of the state returned from a failed apply,
+// which might belong to a partially created or updated resource, e.g.,
+// if the operation has been canceled. This prevents us from losing track
+// of, and thus orphaning, the external resource.
+func (n *noForkExternal) trackPartialState(mg xpresource.Managed, newState *tf.InstanceState) {
+	if newState == nil || newState.ID == "" {
+		return
+	}
+	n.opTracker.SetTfState(newState)
+	// the context of the failed operation might have been canceled
+	n.persistTfState(context.TODO(), mg)
+}
+
 func (n *noForkExternal) fromInstanceStateToJSONMap(newState *tf.InstanceState) (map[string]interface{}, error) {
 	impliedType := n.config.TerraformResource.CoreConfigSchema().ImpliedType()
 	attrsAsCtyValue, err := newState.AttrsAsObjectValue(impliedType)
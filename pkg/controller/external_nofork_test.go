@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
@@ -22,8 +23,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/crossplane/upjet/pkg/config"
+	"github.com/crossplane/upjet/pkg/resource"
 	"github.com/crossplane/upjet/pkg/resource/fake"
 	"github.com/crossplane/upjet/pkg/terraform"
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
 )
 
 var (
@@ -97,9 +100,10 @@ func prepareNoForkExternal(r Resource, cfg *config.Resource) *noForkExternal {
 		params: map[string]any{
 			"name": "example",
 		},
-		rawConfig: rawConfig,
-		logger:    logTest,
-		opTracker: NewAsyncTracker(),
+		rawConfig:  rawConfig,
+		logger:     logTest,
+		opTracker:  NewAsyncTracker(),
+		classifier: tferrors.NewClassifier(tferrors.DefaultClassificationRules()...),
 	}
 }
 
@@ -289,8 +293,11 @@ func TestNoForkCreate(t *testing.T) {
 		obj xpresource.Managed
 	}
 	type want struct {
-		err error
+		err      error
+		terminal bool
+		events   []event.Event
 	}
+	deniedDiags := diag.Diagnostics{{Severity: diag.Error, Summary: "creating the resource: AccessDenied"}}
 	cases := map[string]struct {
 		args
 		want
@@ -309,6 +316,24 @@ func TestNoForkCreate(t *testing.T) {
 				err: errors.New("failed to read the ID of the new resource"),
 			},
 		},
+		"TerminalFailure": {
+			args: args{
+				r: mockResource{
+					ApplyFn: func(ctx context.Context, s *tf.InstanceState, d *tf.InstanceDiff, meta interface{}) (*tf.InstanceState, diag.Diagnostics) {
+						return nil, deniedDiags
+					},
+				},
+				cfg: cfg,
+				obj: obj,
+			},
+			want: want{
+				err:      tferrors.NewClassifier(tferrors.DefaultClassificationRules()...).Classify(withDiagnostics(errors.Errorf("failed to create the resource: %v", deniedDiags), deniedDiags)),
+				terminal: true,
+				events: []event.Event{
+					event.Warning(reasonTerraformDiagnostic, errors.New("creating the resource: AccessDenied"), "severity", "error"),
+				},
+			},
+		},
 		"Successful": {
 			args: args{
 				r: mockResource{
@@ -324,10 +349,18 @@ func TestNoForkCreate(t *testing.T) {
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			noForkExternal := prepareNoForkExternal(tc.args.r, tc.args.cfg)
+			r := &eventRecorder{}
+			noForkExternal.recorder = r
 			_, err := noForkExternal.Create(context.TODO(), tc.args.obj)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nConnect(...): -want error, +got error:\n", diff)
 			}
+			if got := tferrors.IsTerminal(err); got != tc.want.terminal {
+				t.Errorf("\nCreate(...): want terminal %v, got %v", tc.want.terminal, got)
+			}
+			if diff := cmp.Diff(tc.want.events, r.events); diff != "" {
+				t.Errorf("\nCreate(...): -want events, +got events:\n%s", diff)
+			}
 		})
 	}
 }
@@ -403,3 +436,56 @@ func TestNoForkDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestNoForkObservePrivateState(t *testing.T) {
+	type args struct {
+		store resource.PrivateStateStore
+		meta  map[string]any
+	}
+	type want struct {
+		privateState string
+		err          error
+	}
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"NoStore": {
+			reason: "The private state should not be stored if no PrivateStateStore is configured.",
+		},
+		"Stored": {
+			reason: "The private state of the refreshed instance state should be stored with the configured PrivateStateStore.",
+			args: args{
+				store: resource.NewAnnotationPrivateStateStore(),
+				meta:  map[string]any{"schema_version": "1"},
+			},
+			want: want{
+				privateState: `{"schema_version":"1"}`,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := mockResource{
+				RefreshWithoutUpgradeFn: func(ctx context.Context, s *tf.InstanceState, meta interface{}) (*tf.InstanceState, diag.Diagnostics) {
+					return &tf.InstanceState{ID: "example-id", Attributes: map[string]string{"name": "example"}, Meta: tc.args.meta}, nil
+				},
+			}
+			noForkExternal := prepareNoForkExternal(r, cfg)
+			noForkExternal.privateStateStore = tc.args.store
+			mg := &fake.Terraformed{
+				Parameterizable: fake.Parameterizable{
+					Parameters: map[string]any{"name": "example"},
+				},
+			}
+			_, err := noForkExternal.Observe(context.TODO(), mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.privateState, mg.GetAnnotations()[resource.AnnotationKeyPrivateRawAttribute]); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want private state, +got private state:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -6,9 +6,11 @@ package controller
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	xpmeta "github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
@@ -18,7 +20,9 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
+	"github.com/spf13/afero"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/upjet/pkg/config"
@@ -26,6 +30,8 @@ import (
 	"github.com/crossplane/upjet/pkg/resource/fake"
 	"github.com/crossplane/upjet/pkg/resource/json"
 	"github.com/crossplane/upjet/pkg/terraform"
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
+	tffake "github.com/crossplane/upjet/pkg/terraform/fake"
 )
 
 const (
@@ -193,9 +199,10 @@ func TestConnect(t *testing.T) {
 
 func TestObserve(t *testing.T) {
 	type args struct {
-		w      Workspace
-		obj    xpresource.Managed
-		client client.Client
+		w         Workspace
+		obj       xpresource.Managed
+		client    client.Client
+		readiness config.Readiness
 	}
 	type want struct {
 		obs       managed.ExternalObservation
@@ -312,6 +319,42 @@ func TestObserve(t *testing.T) {
 				condition: available(),
 			},
 		},
+		"NotReady": {
+			reason: "We should not mark the resource as ready if the readiness rules are not satisfied",
+			args: args{
+				obj: &fake.Terraformed{
+					Managed: xpfake.Managed{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: exampleCriticalAnnotations,
+						},
+						Manageable: xpfake.Manageable{
+							Policy: xpv1.ManagementPolicies{xpv1.ManagementActionAll},
+						},
+					},
+				},
+				w: WorkspaceFns{
+					RefreshFn: func(_ context.Context) (terraform.RefreshResult, error) {
+						return terraform.RefreshResult{
+							Exists: true,
+							State:  exampleState,
+						}, nil
+					},
+					PlanFn: func(_ context.Context) (terraform.PlanResult, error) {
+						return terraform.PlanResult{Exists: true, UpToDate: false}, nil
+					},
+				},
+				readiness: config.Readiness{
+					Rules: []config.ReadinessRule{{FieldPath: "obs", Values: []string{"ready"}}},
+				},
+			},
+			want: want{
+				obs: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				condition: unavailable(`The field "obs" is "obsval", expected one of ["ready"]`),
+			},
+		},
 		"PlanFailed": {
 			reason: "Failure of plan should be reported",
 			args: args{
@@ -592,7 +635,9 @@ func TestObserve(t *testing.T) {
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := &external{workspace: tc.w, config: config.DefaultResource("upjet_resource", nil, nil), kube: tc.args.client, logger: logging.NewNopLogger()}
+			cfg := config.DefaultResource("upjet_resource", nil, nil)
+			cfg.Readiness = tc.args.readiness
+			e := &external{workspace: tc.w, config: cfg, kube: tc.args.client, logger: logging.NewNopLogger()}
 			observation, err := e.Observe(context.TODO(), tc.args.obj)
 			if diff := cmp.Diff(tc.want.obs, observation); diff != "" {
 				t.Errorf("\n%s\nObserve(...): -want observation, +got observation:\n%s", tc.reason, diff)
@@ -609,11 +654,202 @@ func TestObserve(t *testing.T) {
 	}
 }
 
+func TestObserveWithBackend(t *testing.T) {
+	refreshErr := tferrors.NewRefreshFailed([]byte(`{"@level":"error","@message":"Error: boom","diagnostic":{"severity":"error","summary":"boom","detail":"failed"},"type":"diagnostic"}`))
+	type args struct {
+		refreshFn func(fs afero.Fs) func(ctx context.Context, inv terraform.Invocation) error
+	}
+	type want struct {
+		obs managed.ExternalObservation
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"NotFound": {
+			reason: "A resource whose refreshed state is empty should be reported as not existing.",
+			args: args{
+				refreshFn: func(fs afero.Fs) func(ctx context.Context, inv terraform.Invocation) error {
+					return func(_ context.Context, inv terraform.Invocation) error {
+						return afero.WriteFile(fs, filepath.Join(inv.Dir, "terraform.tfstate"), []byte(`{"version":4,"resources":[]}`), 0600)
+					}
+				},
+			},
+		},
+		"RefreshFailed": {
+			reason: "The error of the Backend should be returned if the refresh fails.",
+			args: args{
+				refreshFn: func(_ afero.Fs) func(ctx context.Context, inv terraform.Invocation) error {
+					return func(_ context.Context, _ terraform.Invocation) error {
+						return refreshErr
+					}
+				},
+			},
+			want: want{
+				err: errors.Wrap(refreshErr, errRefresh),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			w := terraform.NewWorkspace(testPath, terraform.WithAferoFs(fs), terraform.WithWorkspaceBackend(&tffake.Backend{
+				MockRefresh: tc.args.refreshFn(fs),
+			}))
+			obj := &fake.Terraformed{
+				Managed: xpfake.Managed{
+					Manageable: xpfake.Manageable{
+						Policy: xpv1.ManagementPolicies{xpv1.ManagementActionAll},
+					},
+				},
+			}
+			e := &external{workspace: w, config: config.DefaultResource("upjet_resource", nil, nil), logger: logging.NewNopLogger()}
+			observation, err := e.Observe(context.TODO(), obj)
+			if diff := cmp.Diff(tc.want.obs, observation); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want observation, +got observation:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+type lateInitParameters struct {
+	Param *string `json:"param,omitempty" tf:"param,omitempty"`
+}
+
+// lateInitTerraformed is a fake Terraformed whose spec.forProvider is
+// late-initialized like the generated resources.
+type lateInitTerraformed struct {
+	fake.Terraformed
+	Spec struct {
+		ForProvider lateInitParameters
+	}
+}
+
+func (tr *lateInitTerraformed) LateInitialize(attrs []byte) (bool, error) {
+	params := &lateInitParameters{}
+	if err := json.TFParser.Unmarshal(attrs, params); err != nil {
+		return false, err
+	}
+	li := resource.NewGenericLateInitializer(resource.WithZeroValueJSONOmitEmptyFilter(resource.CNameWildcard),
+		resource.WithLateInitializedFields(resource.GetLateInitializedFields(tr)...),
+		resource.WithClearedFields(resource.GetClearedLateInitializedFields(tr)...))
+	changed, err := li.LateInitialize(&tr.Spec.ForProvider, params)
+	if err != nil {
+		return false, err
+	}
+	resource.RecordLateInitializedFields(tr, li.LateInitializedFields()...)
+	return changed, nil
+}
+
+func TestObserveResetLateInitializedFields(t *testing.T) {
+	paramval := "paramval"
+	type args struct {
+		annotations map[string]string
+		param       *string
+	}
+	type want struct {
+		obs   managed.ExternalObservation
+		param *string
+	}
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"LateInitialized": {
+			reason: "A field that is not set should be late-initialized from the observed state.",
+			want: want{
+				obs: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: true,
+				},
+				param: &paramval,
+			},
+		},
+		"Reset": {
+			reason: "A late-initialized field that has been reset should not be late-initialized again, so that the spec stays minimal.",
+			args: args{
+				annotations: map[string]string{
+					resource.AnnotationKeyLateInitializedFields:      "Param",
+					resource.AnnotationKeyResetLateInitializedFields: "true",
+				},
+				param: &paramval,
+			},
+			want: want{
+				obs: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			obj := &lateInitTerraformed{
+				Terraformed: fake.Terraformed{
+					Managed: xpfake.Managed{
+						ConditionedStatus: xpv1.ConditionedStatus{
+							Conditions: []xpv1.Condition{xpv1.Available()},
+						},
+						Manageable: xpfake.Manageable{
+							Policy: xpv1.ManagementPolicies{xpv1.ManagementActionAll},
+						},
+					},
+				},
+			}
+			xpmeta.AddAnnotations(obj, exampleCriticalAnnotations)
+			xpmeta.AddAnnotations(obj, tc.args.annotations)
+			obj.Spec.ForProvider.Param = tc.args.param
+			if _, err := resource.ResetLateInitializedFields(obj); err != nil {
+				t.Fatalf("\n%s\nResetLateInitializedFields(...): unexpected error: %v", tc.reason, err)
+			}
+			w := WorkspaceFns{
+				RefreshFn: func(_ context.Context) (terraform.RefreshResult, error) {
+					return terraform.RefreshResult{
+						Exists: true,
+						State:  exampleState,
+					}, nil
+				},
+				PlanFn: func(_ context.Context) (terraform.PlanResult, error) {
+					return terraform.PlanResult{Exists: true, UpToDate: true}, nil
+				},
+			}
+			e := &external{workspace: w, config: config.DefaultResource("upjet_resource", nil, nil), logger: logging.NewNopLogger()}
+			observation, err := e.Observe(context.TODO(), obj)
+			if err != nil {
+				t.Fatalf("\n%s\nObserve(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.obs, observation); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want observation, +got observation:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.param, obj.Spec.ForProvider.Param); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want spec.forProvider.param, +got spec.forProvider.param:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func available() *xpv1.Condition {
 	c := xpv1.Available()
 	return &c
 }
 
+func unavailable(msg string) *xpv1.Condition {
+	c := xpv1.Unavailable().WithMessage(msg)
+	return &c
+}
+
+func deletionAllowed() *xpv1.Condition {
+	c := resource.DeletionAllowedCondition()
+	return &c
+}
+
 func TestCreate(t *testing.T) {
 	type args struct {
 		w   Workspace
@@ -766,9 +1002,11 @@ func TestDelete(t *testing.T) {
 		cfg *config.Resource
 		c   CallbackProvider
 		obj xpresource.Managed
+		op  *terraform.Operation
 	}
 	type want struct {
-		err error
+		err       error
+		condition *xpv1.Condition
 	}
 	cases := map[string]struct {
 		reason string
@@ -812,14 +1050,358 @@ func TestDelete(t *testing.T) {
 				err: errors.Wrap(errBoom, errDestroy),
 			},
 		},
+		"ProtectedByAnnotation": {
+			reason: "It should not destroy a managed resource protected from deletion by its annotation",
+			args: args{
+				obj: &fake.Terraformed{
+					Managed: xpfake.Managed{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{resource.AnnotationKeyDeletionProtection: "true"},
+						},
+					},
+				},
+				cfg: &config.Resource{},
+			},
+			want: want{
+				err: errors.Errorf("The managed resource is protected from deletion by the %s annotation", resource.AnnotationKeyDeletionProtection),
+			},
+		},
+		"ProtectedNatively": {
+			reason: "It should turn off the native deletion protection of an external resource before destroying it",
+			args: args{
+				obj: &fake.Terraformed{
+					Observable: fake.Observable{
+						Observation: map[string]any{"deletion_protection": true},
+					},
+				},
+				cfg: &config.Resource{
+					DeletionProtection: config.DeletionProtection{Attribute: "deletion_protection"},
+				},
+				w: WorkspaceFns{
+					ApplyFn: func(_ context.Context) (terraform.ApplyResult, error) {
+						return terraform.ApplyResult{}, nil
+					},
+					DestroyFn: func(_ context.Context) error {
+						return nil
+					},
+				},
+			},
+		},
+		"ProtectedNativelyAsync": {
+			reason: "It should turn off the native deletion protection of an external resource asynchronously and destroy it after the apply completes",
+			args: args{
+				obj: &fake.Terraformed{
+					Observable: fake.Observable{
+						Observation: map[string]any{"deletion_protection": true},
+					},
+				},
+				cfg: &config.Resource{
+					UseAsync:           true,
+					DeletionProtection: config.DeletionProtection{Attribute: "deletion_protection"},
+				},
+				c: CallbackFns{
+					UpdateFn: func(_ string) terraform.CallbackFn {
+						return nil
+					},
+				},
+				w: WorkspaceFns{
+					ApplyAsyncFn: func(_ terraform.CallbackFn) error {
+						return nil
+					},
+					DestroyAsyncFn: func(_ terraform.CallbackFn) error {
+						return errBoom
+					},
+				},
+			},
+		},
+		"ApplyInProgress": {
+			reason: "It should not destroy an external resource whose native deletion protection is being turned off asynchronously",
+			args: args{
+				obj: &fake.Terraformed{
+					Observable: fake.Observable{
+						Observation: map[string]any{"deletion_protection": true},
+					},
+				},
+				cfg: &config.Resource{
+					UseAsync:           true,
+					DeletionProtection: config.DeletionProtection{Attribute: "deletion_protection"},
+				},
+				op: applyOperation(),
+				w: WorkspaceFns{
+					ApplyAsyncFn: func(_ terraform.CallbackFn) error {
+						return errBoom
+					},
+					DestroyAsyncFn: func(_ terraform.CallbackFn) error {
+						return errBoom
+					},
+				},
+			},
+		},
+		"DestroyInProgress": {
+			reason: "It should not apply the native deletion protection again while the external resource is being destroyed",
+			args: args{
+				obj: &fake.Terraformed{
+					Observable: fake.Observable{
+						Observation: map[string]any{"deletion_protection": true},
+					},
+				},
+				cfg: &config.Resource{
+					UseAsync:           true,
+					DeletionProtection: config.DeletionProtection{Attribute: "deletion_protection"},
+				},
+				c: CallbackFns{
+					DestroyFn: func(_ string) terraform.CallbackFn {
+						return nil
+					},
+				},
+				op: destroyOperation(),
+				w: WorkspaceFns{
+					ApplyFn: func(_ context.Context) (terraform.ApplyResult, error) {
+						return terraform.ApplyResult{}, errBoom
+					},
+					DestroyAsyncFn: func(_ terraform.CallbackFn) error {
+						return nil
+					},
+				},
+			},
+		},
+		"DisableNativeProtectionFailed": {
+			reason: "It should not destroy an external resource whose native deletion protection cannot be turned off",
+			args: args{
+				obj: &fake.Terraformed{
+					Observable: fake.Observable{
+						Observation: map[string]any{"deletion_protection": true},
+					},
+				},
+				cfg: &config.Resource{
+					DeletionProtection: config.DeletionProtection{Attribute: "deletion_protection"},
+				},
+				w: WorkspaceFns{
+					ApplyFn: func(_ context.Context) (terraform.ApplyResult, error) {
+						return terraform.ApplyResult{}, errBoom
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errDisableNativeProtection),
+			},
+		},
+		"NativeProtectionDisabled": {
+			reason: "It should destroy an external resource whose native deletion protection is disabled",
+			args: args{
+				obj: &fake.Terraformed{
+					Observable: fake.Observable{
+						Observation: map[string]any{"deletion_protection": false},
+					},
+				},
+				cfg: &config.Resource{
+					DeletionProtection: config.DeletionProtection{Attribute: "deletion_protection"},
+				},
+				w: WorkspaceFns{
+					DestroyFn: func(_ context.Context) error {
+						return nil
+					},
+				},
+			},
+		},
+		"DeletionAllowed": {
+			reason: "It should clear the DeletionProtection condition once the deletion is allowed",
+			args: args{
+				obj: &fake.Terraformed{
+					Managed: xpfake.Managed{
+						ConditionedStatus: xpv1.ConditionedStatus{
+							Conditions: []xpv1.Condition{resource.DeletionBlockedCondition("blocked")},
+						},
+					},
+				},
+				cfg: &config.Resource{},
+				w: WorkspaceFns{
+					DestroyFn: func(_ context.Context) error {
+						return nil
+					},
+				},
+			},
+			want: want{
+				condition: deletionAllowed(),
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := &external{workspace: tc.w, callback: tc.c, config: tc.cfg}
+			e := &external{workspace: tc.w, callback: tc.c, config: tc.cfg, operation: tc.args.op}
 			err := e.Delete(context.TODO(), tc.args.obj)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nCreate(...): -want error, +got error:\n%s", tc.reason, diff)
 			}
+			if tc.want.condition != nil {
+				if diff := cmp.Diff(*tc.want.condition, tc.args.obj.GetCondition(resource.TypeDeletionProtection), test.EquateConditions()); diff != "" {
+					t.Errorf("\n%s\nDelete(...): -want condition, +got condition:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+// destroyOperation returns an Operation for a running destroy.
+func destroyOperation() *terraform.Operation {
+	op := &terraform.Operation{}
+	op.MarkStart("destroy")
+	return op
+}
+
+// applyOperation returns an Operation for a running apply.
+func applyOperation() *terraform.Operation {
+	op := &terraform.Operation{}
+	op.MarkStart("apply")
+	return op
+}
+
+func TestCancelStaleOperation(t *testing.T) {
+	type args struct {
+		opType     string
+		generation int64
+		obj        xpresource.Managed
+	}
+	type want struct {
+		canceled bool
+	}
+	deleted := metav1.Now()
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"UpToDateGeneration": {
+			args: args{
+				opType:     "update",
+				generation: 1,
+				obj:        &fake.Terraformed{Managed: xpfake.Managed{ObjectMeta: metav1.ObjectMeta{Generation: 1}}},
+			},
+		},
+		"GenerationChanged": {
+			args: args{
+				opType:     "update",
+				generation: 1,
+				obj:        &fake.Terraformed{Managed: xpfake.Managed{ObjectMeta: metav1.ObjectMeta{Generation: 2}}},
+			},
+			want: want{
+				canceled: true,
+			},
+		},
+		"UnboundOperation": {
+			args: args{
+				opType: "create",
+				obj:    &fake.Terraformed{Managed: xpfake.Managed{ObjectMeta: metav1.ObjectMeta{Generation: 2}}},
+			},
+		},
+		"Deleted": {
+			args: args{
+				opType: "create",
+				obj:    &fake.Terraformed{Managed: xpfake.Managed{ObjectMeta: metav1.ObjectMeta{Generation: 2, DeletionTimestamp: &deleted}}},
+			},
+			want: want{
+				canceled: true,
+			},
+		},
+		"Destroying": {
+			args: args{
+				opType: "destroy",
+				obj:    &fake.Terraformed{Managed: xpfake.Managed{ObjectMeta: metav1.ObjectMeta{Generation: 2, DeletionTimestamp: &deleted}}},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			op := &terraform.Operation{}
+			op.MarkStart(tc.args.opType)
+			op.SetGeneration(tc.args.generation)
+			ctx, cancel := op.WithCancel(context.Background())
+			defer cancel()
+			got := cancelStaleOperation(op, tc.args.obj, logging.NewNopLogger())
+			if diff := cmp.Diff(tc.want.canceled, got); diff != "" {
+				t.Errorf("\n%s\ncancelStaleOperation(...): -want canceled, +got canceled:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.canceled, ctx.Err() != nil); diff != "" {
+				t.Errorf("\n%s\ncancelStaleOperation(...): -want context canceled, +got context canceled:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+type providerScheduler struct {
+	StartFn func(terraform.ProviderHandle) (terraform.InUse, string, error)
+}
+
+func (s providerScheduler) Start(h terraform.ProviderHandle) (terraform.InUse, string, error) {
+	return s.StartFn(h)
+}
+
+func (providerScheduler) Stop(terraform.ProviderHandle) error {
+	return nil
+}
+
+type eventRecorder struct {
+	events []event.Event
+}
+
+func (r *eventRecorder) Event(_ runtime.Object, e event.Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder) WithAnnotations(_ ...string) event.Recorder {
+	return r
+}
+
+func TestScheduleProvider(t *testing.T) {
+	type args struct {
+		scheduler terraform.ProviderScheduler
+	}
+	type want struct {
+		requeued bool
+		events   []event.Event
+		err      error
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"NoScheduler": {},
+		"Scheduled": {
+			args: args{
+				scheduler: providerScheduler{
+					StartFn: func(terraform.ProviderHandle) (terraform.InUse, string, error) {
+						return nil, "", nil
+					},
+				},
+			},
+		},
+		"ScheduleFailed": {
+			args: args{
+				scheduler: providerScheduler{
+					StartFn: func(terraform.ProviderHandle) (terraform.InUse, string, error) {
+						return nil, "", errBoom
+					},
+				},
+			},
+			want: want{
+				events: []event.Event{event.Warning(reasonCannotScheduleProvider, errors.Wrap(errBoom, errScheduleProvider))},
+				err:    errors.Wrap(errBoom, errScheduleProvider),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &eventRecorder{}
+			e := &external{workspace: WorkspaceFns{}, providerScheduler: tc.args.scheduler, recorder: r, logger: logging.NewNopLogger()}
+			requeued, err := e.scheduleProvider(&fake.Terraformed{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nscheduleProvider(...): -want error, +got error:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.requeued, requeued); diff != "" {
+				t.Errorf("\n%s\nscheduleProvider(...): -want requeued, +got requeued:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, r.events); diff != "" {
+				t.Errorf("\n%s\nscheduleProvider(...): -want events, +got events:\n%s", name, diff)
+			}
 		})
 	}
 }
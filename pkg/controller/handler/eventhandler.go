@@ -17,7 +17,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
-const NoRateLimiter = ""
+const (
+	NoRateLimiter = ""
+	// TerminalErrorRateLimiter is the name of the rate limiter for
+	// requeuing the reconcile requests after the terminal errors, which
+	// are not expected to be resolved by retrying, with a longer backoff.
+	TerminalErrorRateLimiter = "terminalError"
+
+	terminalErrorBaseDelay = 30 * time.Second
+	terminalErrorMaxDelay  = 30 * time.Minute
+)
 
 // EventHandler handles Kubernetes events by queueing reconcile requests for
 // objects and allows upjet components to queue reconcile requests.
@@ -25,10 +34,17 @@ type EventHandler struct {
 	innerHandler   handler.EventHandler
 	queue          workqueue.RateLimitingInterface
 	rateLimiterMap map[string]workqueue.RateLimiter
+	backoffs       map[string]backoff
 	logger         logging.Logger
 	mu             *sync.RWMutex
 }
 
+// backoff is the terminal error backoff of an object with a generation.
+type backoff struct {
+	until      time.Time
+	generation int64
+}
+
 // Option configures an option for the EventHandler.
 type Option func(eventHandler *EventHandler)
 
@@ -39,12 +55,24 @@ func WithLogger(logger logging.Logger) Option {
 	}
 }
 
+// WithRateLimiter configures the rate limiter with the specified name.
+// The rate limiters that are not configured default to
+// the workqueue.DefaultControllerRateLimiter.
+func WithRateLimiter(name string, r workqueue.RateLimiter) Option {
+	return func(eventHandler *EventHandler) {
+		eventHandler.rateLimiterMap[name] = r
+	}
+}
+
 // NewEventHandler initializes a new EventHandler instance.
 func NewEventHandler(opts ...Option) *EventHandler {
 	eh := &EventHandler{
-		innerHandler:   &handler.EnqueueRequestForObject{},
-		mu:             &sync.RWMutex{},
-		rateLimiterMap: make(map[string]workqueue.RateLimiter),
+		innerHandler: &handler.EnqueueRequestForObject{},
+		mu:           &sync.RWMutex{},
+		rateLimiterMap: map[string]workqueue.RateLimiter{
+			TerminalErrorRateLimiter: workqueue.NewItemExponentialFailureRateLimiter(terminalErrorBaseDelay, terminalErrorMaxDelay),
+		},
+		backoffs: make(map[string]backoff),
 	}
 	for _, o := range opts {
 		o(eh)
@@ -84,11 +112,57 @@ func (e *EventHandler) RequestReconcile(rateLimiterName, name string, failureLim
 	return true
 }
 
+// BackOff requeues a reconciliation request for the specified name with
+// the TerminalErrorRateLimiter and reports the object with the specified
+// generation as backed off with IsBackedOff until the request is due.
+// Returns true if the reconcile request was successfully queued.
+func (e *EventHandler) BackOff(name string, generation int64) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.queue == nil {
+		return false
+	}
+	item := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name: name,
+		},
+	}
+	when := e.rateLimiterMap[TerminalErrorRateLimiter].When(item)
+	e.queue.AddAfter(item, when)
+	e.backoffs[name] = backoff{
+		until:      time.Now().Add(when),
+		generation: generation,
+	}
+	e.logger.Debug("Reconcile request has been backed off.", "name", name, "when", when)
+	return true
+}
+
+// IsBackedOff reports whether the object with the specified name and
+// generation has been backed off with BackOff and its reconcile request is
+// not due yet. A change in the generation of the object ends its backoff.
+// The backoffs that have ended are removed.
+func (e *EventHandler) IsBackedOff(name string, generation int64) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b, ok := e.backoffs[name]
+	if !ok {
+		return false
+	}
+	if b.generation != generation || !time.Now().Before(b.until) {
+		delete(e.backoffs, name)
+		return false
+	}
+	return true
+}
+
 // Forget indicates that the reconcile retries is finished for
 // the specified name.
 func (e *EventHandler) Forget(rateLimiterName, name string) {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if rateLimiterName == TerminalErrorRateLimiter {
+		delete(e.backoffs, name)
+	}
 	rateLimiter := e.rateLimiterMap[rateLimiterName]
 	if rateLimiter == nil {
 		return
@@ -122,6 +196,8 @@ func (e *EventHandler) Update(ctx context.Context, ev event.UpdateEvent, limitin
 
 func (e *EventHandler) Delete(ctx context.Context, ev event.DeleteEvent, limitingInterface workqueue.RateLimitingInterface) {
 	e.setQueue(limitingInterface)
+	// the backoff of a deleted object would otherwise never be removed
+	e.Forget(TerminalErrorRateLimiter, ev.Object.GetName())
 	e.logger.Debug("Calling the inner handler for Delete event.", "name", ev.Object.GetName(), "queueLength", limitingInterface.Len())
 	e.innerHandler.Delete(ctx, ev, limitingInterface)
 }
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	xpfake "github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestBackOff(t *testing.T) {
+	type args struct {
+		generation int64
+		// elapsed is the time elapsed since the backoff
+		elapsed time.Duration
+		deleted bool
+	}
+	type want struct {
+		backedOff bool
+		backoffs  int
+	}
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"BackedOff": {
+			reason: "An object with an unchanged generation should be backed off until its reconcile request is due.",
+			want: want{
+				backedOff: true,
+				backoffs:  1,
+			},
+		},
+		"GenerationChanged": {
+			reason: "The backoff of an object whose generation has changed should end and be removed.",
+			args: args{
+				generation: 1,
+			},
+		},
+		"Due": {
+			reason: "The backoff of an object whose reconcile request is due should end and be removed.",
+			args: args{
+				elapsed: time.Hour,
+			},
+		},
+		"Deleted": {
+			reason: "The backoff of a deleted object should be removed.",
+			args: args{
+				deleted: true,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mg := &xpfake.Managed{}
+			mg.SetName("name")
+			eh := NewEventHandler(WithLogger(logging.NewNopLogger()))
+			q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer q.ShutDown()
+			eh.Generic(context.TODO(), event.GenericEvent{Object: mg}, q)
+
+			if !eh.BackOff(mg.GetName(), 0) {
+				t.Fatalf("\n%s\nBackOff(...): want the reconcile request to be queued", tc.reason)
+			}
+			b := eh.backoffs[mg.GetName()]
+			b.until = b.until.Add(-tc.args.elapsed)
+			eh.backoffs[mg.GetName()] = b
+			if tc.args.deleted {
+				eh.Delete(context.TODO(), event.DeleteEvent{Object: mg}, q)
+			}
+			if diff := cmp.Diff(tc.want.backedOff, eh.IsBackedOff(mg.GetName(), tc.args.generation)); diff != "" {
+				t.Errorf("\n%s\nIsBackedOff(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.backoffs, len(eh.backoffs)); diff != "" {
+				t.Errorf("\n%s\nIsBackedOff(...): -want backoffs, +got backoffs:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
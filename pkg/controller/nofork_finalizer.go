@@ -7,8 +7,11 @@ package controller
 import (
 	"context"
 
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/pkg/errors"
+
+	"github.com/crossplane/upjet/pkg/terraform"
 )
 
 const (
@@ -17,15 +20,40 @@ const (
 
 // TrackerCleaner is the interface that the no-fork finalizer needs to work with.
 type TrackerCleaner interface {
-	RemoveTracker(obj xpresource.Object) error
+	RemoveTracker(ctx context.Context, obj xpresource.Object) error
+}
+
+// NoForkFinalizerOption configures a NoForkFinalizer.
+type NoForkFinalizerOption func(nf *NoForkFinalizer)
+
+// WithNoForkFinalizerStateExporter configures the StateExporter the Terraform
+// states of the orphaned managed resources are exported with before their
+// operation trackers are removed.
+func WithNoForkFinalizerStateExporter(e terraform.StateExporter) NoForkFinalizerOption {
+	return func(nf *NoForkFinalizer) {
+		nf.StateExporter = e
+	}
+}
+
+// WithNoForkFinalizerLogger configures the logger the failures to export
+// the Terraform states of the orphaned managed resources are logged with.
+func WithNoForkFinalizerLogger(l logging.Logger) NoForkFinalizerOption {
+	return func(nf *NoForkFinalizer) {
+		nf.logger = l
+	}
 }
 
 // NewNoForkFinalizer returns a new NoForkFinalizer.
-func NewNoForkFinalizer(tc TrackerCleaner, af xpresource.Finalizer) *NoForkFinalizer {
-	return &NoForkFinalizer{
+func NewNoForkFinalizer(tc TrackerCleaner, af xpresource.Finalizer, opts ...NoForkFinalizerOption) *NoForkFinalizer {
+	nf := &NoForkFinalizer{
 		Finalizer:      af,
 		OperationStore: tc,
+		logger:         logging.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(nf)
 	}
+	return nf
 }
 
 // NoForkFinalizer removes the operation tracker from the workspace store and only
@@ -33,6 +61,10 @@ func NewNoForkFinalizer(tc TrackerCleaner, af xpresource.Finalizer) *NoForkFinal
 type NoForkFinalizer struct {
 	xpresource.Finalizer
 	OperationStore TrackerCleaner
+	// StateExporter, if set, exports the Terraform states of the orphaned
+	// managed resources.
+	StateExporter terraform.StateExporter
+	logger        logging.Logger
 }
 
 // AddFinalizer to the supplied Managed resource.
@@ -43,7 +75,8 @@ func (nf *NoForkFinalizer) AddFinalizer(ctx context.Context, obj xpresource.Obje
 // RemoveFinalizer removes the workspace from workspace store before removing
 // the finalizer.
 func (nf *NoForkFinalizer) RemoveFinalizer(ctx context.Context, obj xpresource.Object) error {
-	if err := nf.OperationStore.RemoveTracker(obj); err != nil {
+	terraform.TryExportOrphanedState(ctx, nf.StateExporter, obj, nf.logger)
+	if err := nf.OperationStore.RemoveTracker(ctx, obj); err != nil {
 		return errors.Wrap(err, errRemoveTracker)
 	}
 	return nf.Finalizer.RemoveFinalizer(ctx, obj)
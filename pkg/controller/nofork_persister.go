@@ -0,0 +1,249 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	tfsdk "github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// instanceStateSecretPrefix is the name prefix of the Secrets the
+	// SecretInstanceStatePersister keeps the instance states in.
+	instanceStateSecretPrefix = "upjet-tfstate-"
+	// instanceStateSecretKey is the key of the serialized instance state
+	// in the Secret data.
+	instanceStateSecretKey = "state"
+	// labelKeyInstanceStateOwner is the label key holding the UID of the
+	// managed resource that owns a persisted instance state.
+	labelKeyInstanceStateOwner = "upjet.crossplane.io/owner-uid"
+
+	errMarshalInstanceState   = "cannot marshal the Terraform instance state"
+	errUnmarshalInstanceState = "cannot unmarshal the Terraform instance state"
+	errGetStateSecret         = "cannot get the instance state Secret"
+	errCreateStateSecret      = "cannot create the instance state Secret"
+	errUpdateStateSecret      = "cannot update the instance state Secret"
+	errDeleteStateSecret      = "cannot delete the instance state Secret"
+	errReadStateFile          = "cannot read the instance state file"
+	errWriteStateFile         = "cannot write the instance state file"
+	errRemoveStateFile        = "cannot remove the instance state file"
+)
+
+// InstanceStatePersister persists the serialized Terraform instance states
+// of the managed resources, so that the no-fork external clients can restore
+// them losslessly after a provider restart or a leader election failover,
+// instead of reconstructing them from status.atProvider.
+type InstanceStatePersister interface {
+	// Get returns the persisted instance state of the specified object.
+	// Returns a nil slice and a nil error if no state has been persisted
+	// for the object.
+	Get(ctx context.Context, obj xpresource.Object) ([]byte, error)
+	// Put persists the supplied instance state of the specified object.
+	Put(ctx context.Context, obj xpresource.Object, state []byte) error
+	// Delete removes the persisted instance state of the specified object,
+	// if any.
+	Delete(ctx context.Context, obj xpresource.Object) error
+}
+
+// NopInstanceStatePersister does not persist any instance states.
+type NopInstanceStatePersister struct{}
+
+// Get always reports that no state has been persisted.
+func (NopInstanceStatePersister) Get(_ context.Context, _ xpresource.Object) ([]byte, error) {
+	return nil, nil
+}
+
+// Put does nothing.
+func (NopInstanceStatePersister) Put(_ context.Context, _ xpresource.Object, _ []byte) error {
+	return nil
+}
+
+// Delete does nothing.
+func (NopInstanceStatePersister) Delete(_ context.Context, _ xpresource.Object) error {
+	return nil
+}
+
+// persistedInstanceState is the serialized form of a tfsdk.InstanceState.
+// The cty.Value fields of the instance state are not persisted as they
+// are recomputed from the attributes and the resource schema.
+type persistedInstanceState struct {
+	ID         string            `json:"id"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// Meta is serialized as JSON like the Terraform plugin SDK does when it
+	// passes the instance state's private data to Terraform.
+	Meta    map[string]any `json:"meta,omitempty"`
+	Tainted bool           `json:"tainted,omitempty"`
+}
+
+func marshalInstanceState(s *tfsdk.InstanceState) ([]byte, error) {
+	b, err := json.Marshal(persistedInstanceState{
+		ID:         s.ID,
+		Attributes: s.Attributes,
+		Meta:       s.Meta,
+		Tainted:    s.Tainted,
+	})
+	return b, errors.Wrap(err, errMarshalInstanceState)
+}
+
+func unmarshalInstanceState(data []byte) (*tfsdk.InstanceState, error) {
+	ps := persistedInstanceState{}
+	if err := json.Unmarshal(data, &ps); err != nil {
+		return nil, errors.Wrap(err, errUnmarshalInstanceState)
+	}
+	return &tfsdk.InstanceState{
+		ID:         ps.ID,
+		Attributes: ps.Attributes,
+		Meta:       ps.Meta,
+		Tainted:    ps.Tainted,
+	}, nil
+}
+
+// SecretInstanceStatePersister persists the instance states in a Kubernetes
+// Secret per managed resource.
+type SecretInstanceStatePersister struct {
+	kube      client.Client
+	namespace string
+}
+
+// NewSecretInstanceStatePersister returns a new SecretInstanceStatePersister
+// that keeps the instance states in Secrets in the specified namespace.
+func NewSecretInstanceStatePersister(kube client.Client, namespace string) *SecretInstanceStatePersister {
+	return &SecretInstanceStatePersister{
+		kube:      kube,
+		namespace: namespace,
+	}
+}
+
+func (sp *SecretInstanceStatePersister) key(obj xpresource.Object) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: sp.namespace,
+		Name:      instanceStateSecretPrefix + string(obj.GetUID()),
+	}
+}
+
+// Get returns the instance state persisted in the Secret of the specified
+// object.
+func (sp *SecretInstanceStatePersister) Get(ctx context.Context, obj xpresource.Object) ([]byte, error) {
+	s := &v1.Secret{}
+	if err := sp.kube.Get(ctx, sp.key(obj), s); err != nil {
+		return nil, errors.Wrap(xpresource.IgnoreNotFound(err), errGetStateSecret)
+	}
+	return s.Data[instanceStateSecretKey], nil
+}
+
+// Put stores the instance state in the Secret of the specified object,
+// creating the Secret if it does not exist yet. If the GroupVersionKind of
+// the object can be resolved, the object is set as the owner of the Secret
+// so that the Secret is garbage collected with it.
+func (sp *SecretInstanceStatePersister) Put(ctx context.Context, obj xpresource.Object, state []byte) error {
+	key := sp.key(obj)
+	s := &v1.Secret{}
+	err := sp.kube.Get(ctx, key, s)
+	if xpresource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errGetStateSecret)
+	}
+	if kerrors.IsNotFound(err) {
+		s = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+				Labels: map[string]string{
+					labelKeyInstanceStateOwner: string(obj.GetUID()),
+				},
+			},
+			Type: v1.SecretTypeOpaque,
+			Data: map[string][]byte{instanceStateSecretKey: state},
+		}
+		if gvk, err := sp.kube.GroupVersionKindFor(obj); err == nil && !gvk.Empty() {
+			meta.AddOwnerReference(s, meta.AsOwner(meta.TypedReferenceTo(obj, gvk)))
+		}
+		return errors.Wrap(sp.kube.Create(ctx, s), errCreateStateSecret)
+	}
+	if s.Data == nil {
+		s.Data = make(map[string][]byte, 1)
+	}
+	s.Data[instanceStateSecretKey] = state
+	return errors.Wrap(sp.kube.Update(ctx, s), errUpdateStateSecret)
+}
+
+// Delete removes the Secret of the specified object.
+func (sp *SecretInstanceStatePersister) Delete(ctx context.Context, obj xpresource.Object) error {
+	key := sp.key(obj)
+	s := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+		},
+	}
+	return errors.Wrap(xpresource.IgnoreNotFound(sp.kube.Delete(ctx, s)), errDeleteStateSecret)
+}
+
+// FileInstanceStatePersister persists the instance states in a file per
+// managed resource under a local directory, which is expected to be backed
+// by a persistent volume.
+type FileInstanceStatePersister struct {
+	fs  afero.Afero
+	dir string
+}
+
+// NewFileInstanceStatePersister returns a new FileInstanceStatePersister
+// that keeps the instance states under the specified directory of the
+// supplied filesystem.
+func NewFileInstanceStatePersister(fs afero.Fs, dir string) *FileInstanceStatePersister {
+	return &FileInstanceStatePersister{
+		fs:  afero.Afero{Fs: fs},
+		dir: dir,
+	}
+}
+
+func (fp *FileInstanceStatePersister) path(obj xpresource.Object) string {
+	return filepath.Join(fp.dir, string(obj.GetUID())+".json")
+}
+
+// Get returns the instance state persisted in the file of the specified
+// object.
+func (fp *FileInstanceStatePersister) Get(_ context.Context, obj xpresource.Object) ([]byte, error) {
+	data, err := fp.fs.ReadFile(fp.path(obj))
+	if errors.Is(err, afero.ErrFileNotFound) {
+		return nil, nil
+	}
+	return data, errors.Wrap(err, errReadStateFile)
+}
+
+// Put writes the instance state to the file of the specified object. The
+// state is first written to a temporary file, which is then renamed, so that
+// a crash during the write does not leave a truncated state behind.
+func (fp *FileInstanceStatePersister) Put(_ context.Context, obj xpresource.Object, state []byte) error {
+	if err := fp.fs.MkdirAll(fp.dir, 0700); err != nil {
+		return errors.Wrap(err, errWriteStateFile)
+	}
+	p := fp.path(obj)
+	tmp := p + ".tmp"
+	if err := fp.fs.WriteFile(tmp, state, 0600); err != nil {
+		return errors.Wrap(err, errWriteStateFile)
+	}
+	return errors.Wrap(fp.fs.Rename(tmp, p), errWriteStateFile)
+}
+
+// Delete removes the file of the specified object.
+func (fp *FileInstanceStatePersister) Delete(_ context.Context, obj xpresource.Object) error {
+	err := fp.fs.Remove(fp.path(obj))
+	if errors.Is(err, afero.ErrFileNotFound) {
+		return nil
+	}
+	return errors.Wrap(err, errRemoveStateFile)
+}
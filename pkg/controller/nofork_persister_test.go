@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	xpfake "github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	tf "github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var persisterObj = &xpfake.Managed{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "example",
+		UID:  "1234",
+	},
+}
+
+func TestSecretInstanceStatePersisterGet(t *testing.T) {
+	type args struct {
+		kube client.Client
+	}
+	type want struct {
+		data []byte
+		err  error
+	}
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"NotFound": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+				},
+			},
+		},
+		"GetFailed": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(errBoom),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errGetStateSecret),
+			},
+		},
+		"Success": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+						if key.Name != "upjet-tfstate-1234" || key.Namespace != "upbound-system" {
+							return errBoom
+						}
+						obj.(*v1.Secret).Data = map[string][]byte{instanceStateSecretKey: []byte("state")}
+						return nil
+					},
+				},
+			},
+			want: want{
+				data: []byte("state"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			data, err := NewSecretInstanceStatePersister(tc.args.kube, "upbound-system").Get(context.TODO(), persisterObj)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want error, +got error:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, data); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want data, +got data:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestSecretInstanceStatePersisterPut(t *testing.T) {
+	type args struct {
+		kube client.Client
+	}
+	type want struct {
+		err error
+	}
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"Create": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+					MockGroupVersionKindFor: func(_ runtime.Object) (schema.GroupVersionKind, error) {
+						return schema.GroupVersionKind{}, nil
+					},
+					MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+						s := obj.(*v1.Secret)
+						if diff := cmp.Diff(map[string][]byte{instanceStateSecretKey: []byte("state")}, s.Data); diff != "" {
+							t.Errorf("Create(...): -want data, +got data:\n%s", diff)
+						}
+						if s.Labels[labelKeyInstanceStateOwner] != "1234" {
+							t.Errorf("Create(...): missing owner label")
+						}
+						return nil
+					},
+				},
+			},
+		},
+		"Update": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil),
+					MockUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+						if diff := cmp.Diff(map[string][]byte{instanceStateSecretKey: []byte("state")}, obj.(*v1.Secret).Data); diff != "" {
+							t.Errorf("Update(...): -want data, +got data:\n%s", diff)
+						}
+						return nil
+					},
+				},
+			},
+		},
+		"UpdateFailed": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet:    test.NewMockGetFn(nil),
+					MockUpdate: test.NewMockUpdateFn(errBoom),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errUpdateStateSecret),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := NewSecretInstanceStatePersister(tc.args.kube, "upbound-system").Put(context.TODO(), persisterObj, []byte("state"))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPut(...): -want error, +got error:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestFileInstanceStatePersister(t *testing.T) {
+	fp := NewFileInstanceStatePersister(afero.NewMemMapFs(), "/states")
+	data, err := fp.Get(context.TODO(), persisterObj)
+	if err != nil || data != nil {
+		t.Fatalf("Get(...): expected no state and no error, got %q and %v", data, err)
+	}
+	if err := fp.Put(context.TODO(), persisterObj, []byte("state")); err != nil {
+		t.Fatalf("Put(...): unexpected error: %v", err)
+	}
+	data, err = fp.Get(context.TODO(), persisterObj)
+	if err != nil {
+		t.Fatalf("Get(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]byte("state"), data); diff != "" {
+		t.Errorf("Get(...): -want data, +got data:\n%s", diff)
+	}
+	if err := fp.Delete(context.TODO(), persisterObj); err != nil {
+		t.Fatalf("Delete(...): unexpected error: %v", err)
+	}
+	if err := fp.Delete(context.TODO(), persisterObj); err != nil {
+		t.Fatalf("Delete(...): unexpected error for an already removed state: %v", err)
+	}
+}
+
+func TestAsyncTrackerPersistTfState(t *testing.T) {
+	fp := NewFileInstanceStatePersister(afero.NewMemMapFs(), "/states")
+	want := &tf.InstanceState{
+		ID:         "example-id",
+		Attributes: map[string]string{"name": "example"},
+		Meta: map[string]any{
+			"schema_version": "1",
+			"e2bfb730-ecaa-11e6-8f88-34363bc7c4c0": map[string]any{
+				"create": float64(1200000000000),
+			},
+		},
+	}
+	tr := NewAsyncTracker(WithAsyncTrackerPersister(fp))
+	tr.SetTfState(want)
+	if err := tr.PersistTfState(context.TODO(), persisterObj); err != nil {
+		t.Fatalf("PersistTfState(...): unexpected error: %v", err)
+	}
+
+	restored := NewAsyncTracker(WithAsyncTrackerPersister(fp))
+	got, err := restored.LoadTfState(context.TODO(), persisterObj)
+	if err != nil {
+		t.Fatalf("LoadTfState(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got, cmp.Comparer(func(a, b *tf.InstanceState) bool {
+		return a.Equal(b) && a.Tainted == b.Tainted
+	})); diff != "" {
+		t.Errorf("LoadTfState(...): -want state, +got state:\n%s", diff)
+	}
+
+	restored.SetTfState(nil)
+	if err := restored.PersistTfState(context.TODO(), persisterObj); err != nil {
+		t.Fatalf("PersistTfState(...): unexpected error: %v", err)
+	}
+	if data, _ := fp.Get(context.TODO(), persisterObj); data != nil {
+		t.Errorf("PersistTfState(...): expected the persisted state to be removed, got %q", data)
+	}
+}
@@ -5,15 +5,21 @@
 package controller
 
 import (
+	"bytes"
+	"context"
 	"sync"
 	"sync/atomic"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	tfsdk "github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/crossplane/upjet/pkg/config"
 	"github.com/crossplane/upjet/pkg/resource"
+	"github.com/crossplane/upjet/pkg/resource/json"
 	"github.com/crossplane/upjet/pkg/terraform"
 )
 
@@ -32,6 +38,11 @@ type AsyncTracker struct {
 	// deleted after a successful delete call so that the next observe can
 	// tell the managed reconciler that the resource no longer "exists".
 	isDeleted atomic.Bool
+	persister InstanceStatePersister
+	// persistMu serializes the writes to the persister and guards
+	// the last persisted state.
+	persistMu *sync.Mutex
+	persisted []byte
 }
 
 type AsyncTrackerOption func(manager *AsyncTracker)
@@ -42,11 +53,21 @@ func WithAsyncTrackerLogger(l logging.Logger) AsyncTrackerOption {
 		w.logger = l
 	}
 }
+
+// WithAsyncTrackerPersister sets the InstanceStatePersister of AsyncTracker.
+func WithAsyncTrackerPersister(p InstanceStatePersister) AsyncTrackerOption {
+	return func(w *AsyncTracker) {
+		w.persister = p
+	}
+}
+
 func NewAsyncTracker(opts ...AsyncTrackerOption) *AsyncTracker {
 	w := &AsyncTracker{
 		LastOperation: &terraform.Operation{},
 		logger:        logging.NewNopLogger(),
 		mu:            &sync.Mutex{},
+		persister:     NopInstanceStatePersister{},
+		persistMu:     &sync.Mutex{},
 	}
 	for _, f := range opts {
 		f(w)
@@ -81,6 +102,56 @@ func (a *AsyncTracker) GetTfID() string {
 	return a.tfState.ID
 }
 
+// LoadTfState returns the instance state persisted for the specified object.
+// Returns nil if no state has been persisted for the object. The returned
+// state is not set as the tracked state, and its cty.Value fields are
+// left unset.
+func (a *AsyncTracker) LoadTfState(ctx context.Context, obj xpresource.Object) (*tfsdk.InstanceState, error) {
+	a.persistMu.Lock()
+	defer a.persistMu.Unlock()
+	data, err := a.persister.Get(ctx, obj)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	s, err := unmarshalInstanceState(data)
+	if err != nil {
+		return nil, err
+	}
+	a.persisted = data
+	return s, nil
+}
+
+// PersistTfState persists the tracked instance state of the specified object
+// if it has changed since it was last persisted. If there is no tracked state
+// anymore, the persisted state is removed.
+func (a *AsyncTracker) PersistTfState(ctx context.Context, obj xpresource.Object) error {
+	a.persistMu.Lock()
+	defer a.persistMu.Unlock()
+	s := a.GetTfState()
+	if s == nil || s.ID == "" {
+		if a.persisted == nil {
+			return nil
+		}
+		if err := a.persister.Delete(ctx, obj); err != nil {
+			return err
+		}
+		a.persisted = nil
+		return nil
+	}
+	data, err := marshalInstanceState(s)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, a.persisted) {
+		return nil
+	}
+	if err := a.persister.Put(ctx, obj, data); err != nil {
+		return err
+	}
+	a.persisted = data
+	return nil
+}
+
 // IsDeleted returns whether the associated external resource
 // has logically been deleted.
 func (a *AsyncTracker) IsDeleted() bool {
@@ -94,16 +165,33 @@ func (a *AsyncTracker) SetDeleted(deleted bool) {
 }
 
 type OperationTrackerStore struct {
-	store  map[types.UID]*AsyncTracker
-	logger logging.Logger
-	mu     *sync.Mutex
+	store     map[types.UID]*AsyncTracker
+	logger    logging.Logger
+	mu        *sync.Mutex
+	persister InstanceStatePersister
 }
 
-func NewOperationStore(l logging.Logger) *OperationTrackerStore {
+// OperationTrackerStoreOption lets you configure OperationTrackerStore.
+type OperationTrackerStoreOption func(ops *OperationTrackerStore)
+
+// WithInstanceStatePersister configures the InstanceStatePersister the
+// trackers of the OperationTrackerStore persist their instance states with.
+// By default, the instance states are kept only in memory.
+func WithInstanceStatePersister(p InstanceStatePersister) OperationTrackerStoreOption {
+	return func(ops *OperationTrackerStore) {
+		ops.persister = p
+	}
+}
+
+func NewOperationStore(l logging.Logger, opts ...OperationTrackerStoreOption) *OperationTrackerStore {
 	ops := &OperationTrackerStore{
-		store:  map[types.UID]*AsyncTracker{},
-		logger: l,
-		mu:     &sync.Mutex{},
+		store:     map[types.UID]*AsyncTracker{},
+		logger:    l,
+		mu:        &sync.Mutex{},
+		persister: NopInstanceStatePersister{},
+	}
+	for _, f := range opts {
+		f(ops)
 	}
 
 	return ops
@@ -115,13 +203,56 @@ func (ops *OperationTrackerStore) Tracker(tr resource.Terraformed) *AsyncTracker
 	tracker, ok := ops.store[tr.GetUID()]
 	if !ok {
 		l := ops.logger.WithValues("trackerUID", tr.GetUID(), "resourceName", tr.GetName())
-		ops.store[tr.GetUID()] = NewAsyncTracker(WithAsyncTrackerLogger(l))
+		ops.store[tr.GetUID()] = NewAsyncTracker(WithAsyncTrackerLogger(l), WithAsyncTrackerPersister(ops.persister))
 		tracker = ops.store[tr.GetUID()]
 	}
 	return tracker
 }
 
-func (ops *OperationTrackerStore) RemoveTracker(obj xpresource.Object) error {
+// ReadState returns the attributes and the private state of the instance
+// state of the specified managed resource with the specified configuration,
+// as tracked or, if it's not tracked, as persisted. Returns nil attributes
+// if there is no known instance state.
+func (ops *OperationTrackerStore) ReadState(ctx context.Context, tr resource.Terraformed, cfg *config.Resource) ([]byte, []byte, error) {
+	ops.mu.Lock()
+	tracker, ok := ops.store[tr.GetUID()]
+	ops.mu.Unlock()
+	var s *tfsdk.InstanceState
+	if ok {
+		s = tracker.GetTfState()
+	}
+	if s == nil || s.ID == "" {
+		var err error
+		if s, err = NewAsyncTracker(WithAsyncTrackerPersister(ops.persister)).LoadTfState(ctx, tr); err != nil {
+			return nil, nil, errors.Wrap(err, "cannot load the persisted instance state")
+		}
+	}
+	if s == nil || s.ID == "" {
+		return nil, nil, nil
+	}
+	impliedType := cfg.TerraformResource.CoreConfigSchema().ImpliedType()
+	attrsAsCtyValue, err := s.AttrsAsObjectValue(impliedType)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not convert attrs to cty value")
+	}
+	stateValueMap, err := schema.StateValueToJSONMap(attrsAsCtyValue, impliedType)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not convert instance state value to JSON")
+	}
+	attr, err := json.JSParser.Marshal(stateValueMap)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot marshal the instance state attributes")
+	}
+	private, err := json.TFParser.Marshal(s.Meta)
+	return attr, private, errors.Wrap(err, errMarshalPrivateState)
+}
+
+// RemoveTracker removes the tracker of the specified object from the store
+// together with its persisted instance state.
+func (ops *OperationTrackerStore) RemoveTracker(ctx context.Context, obj xpresource.Object) error {
+	if err := ops.persister.Delete(ctx, obj); err != nil {
+		return errors.Wrap(err, "cannot delete the persisted instance state")
+	}
 	ops.mu.Lock()
 	defer ops.mu.Unlock()
 	delete(ops.store, obj.GetUID())
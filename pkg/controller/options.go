@@ -10,9 +10,12 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/upjet/pkg/config"
+	"github.com/crossplane/upjet/pkg/resource"
 	"github.com/crossplane/upjet/pkg/terraform"
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
 )
 
 // Options contains incriminating options for a given Upjet controller instance.
@@ -45,6 +48,60 @@ type Options struct {
 	// PollJitter adds the specified jitter to the configured reconcile period
 	// of the up-to-date resources in managed.Reconciler.
 	PollJitter time.Duration
+
+	// OperationLimiter, if set, limits the number of concurrently running
+	// asynchronous operations per managed resource kind and per
+	// ProviderConfig. It should be shared by all the controllers of
+	// the provider for the per ProviderConfig limits to be effective.
+	OperationLimiter *terraform.OperationLimiter
+
+	// StateSink, if set, stores the Terraform states exported for
+	// the managed resources whose external resources are orphaned, e.g.,
+	// with the Orphan deletion policy, so that they can be managed with
+	// Terraform again.
+	StateSink terraform.StateSink
+
+	// ErrorClassifier, if set, classifies the errors of the Terraform
+	// operations, e.g., to tell the terminal errors that are retried with
+	// a longer backoff from the retryable ones. If not set, the errors are
+	// classified with the tferrors.DefaultClassificationRules.
+	ErrorClassifier *tferrors.Classifier
+
+	// PrivateStateStore, if set, stores the Terraform private states of
+	// the managed resources, e.g., in Secrets instead of their annotations.
+	// It should be the same store configured for the WorkspaceStore with
+	// terraform.WithPrivateStateStore. If not set, the private states are
+	// stored in the annotations of the managed resources.
+	PrivateStateStore resource.PrivateStateStore
+
+	// SecretClients, if set, resolve the secret references of the sensitive
+	// parameters whose Secret names are prefixed with their schemes, e.g.,
+	// a resource.FileSecretClient keyed by resource.SchemeFile resolves
+	// a reference to the Secret named file:///mnt/secrets/db. The references
+	// without a scheme are resolved from the Kubernetes Secrets.
+	SecretClients map[string]resource.SecretClient
+}
+
+// StateExporter returns the StateExporter the Terraform states of
+// the orphaned managed resources with the specified configuration are
+// exported with, or nil if no StateSink is configured. The states are built
+// with the CLI configuration of the WorkspaceStore, if any, and with
+// the PrivateStateStore, falling back to the private state store of
+// the WorkspaceStore. The specified options, e.g., the StateReader of
+// the last known states, are applied last.
+func (o Options) StateExporter(kube client.Client, cfg *config.Resource, exporterOpts ...terraform.OrphanStateExporterOption) terraform.StateExporter {
+	if o.StateSink == nil {
+		return nil
+	}
+	var opts []terraform.OrphanStateExporterOption
+	if o.WorkspaceStore != nil {
+		opts = append(opts, terraform.WithExporterCLI(o.WorkspaceStore.CLI()), terraform.WithExporterPrivateStateStore(o.WorkspaceStore.PrivateStateStore()))
+	}
+	if o.PrivateStateStore != nil {
+		opts = append(opts, terraform.WithExporterPrivateStateStore(o.PrivateStateStore))
+	}
+	opts = append(opts, exporterOpts...)
+	return terraform.NewOrphanStateExporter(kube, o.SetupFn, cfg, o.StateSink, opts...)
 }
 
 // ESSOptions for External Secret Stores.
@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crossplane/upjet/pkg/controller/handler"
+	"github.com/crossplane/upjet/pkg/resource"
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
+)
+
+const (
+	errFmtBackedOff = "the Terraform operations are backed off after a terminal error: %s: %s"
+)
+
+// backOffTerminalError sets the Stalled condition of the specified managed
+// resource and backs off its Terraform operations with the specified event
+// handler if the specified error of a synchronous Terraform operation is
+// terminal, so that the operations are retried with the longer backoff of
+// the handler.TerminalErrorRateLimiter rather than with the backoff of
+// the managed reconciler. Returns the specified error.
+func backOffTerminalError(eh *handler.EventHandler, mg xpresource.Managed, err error) error {
+	if !tferrors.IsTerminal(err) {
+		return err
+	}
+	mg.SetConditions(resource.StalledCondition(err))
+	if eh != nil {
+		eh.BackOff(mg.GetName(), mg.GetGeneration())
+	}
+	return err
+}
+
+// backedOffError returns an error if the Terraform operations on
+// the specified managed resource are backed off after a terminal error
+// and its generation has not changed since then, in which case
+// the operations are to be skipped.
+func backedOffError(eh *handler.EventHandler, mg xpresource.Managed) error {
+	c := mg.GetCondition(resource.TypeStalled)
+	if eh == nil || c.Status != corev1.ConditionTrue || !eh.IsBackedOff(mg.GetName(), mg.GetGeneration()) {
+		return nil
+	}
+	return errors.Errorf(errFmtBackedOff, c.Reason, c.Message)
+}
+
+// clearTerminalError clears the Stalled condition of the specified managed
+// resource, if set, and resets the backoff of its Terraform operations
+// after a successful synchronous Terraform operation.
+func clearTerminalError(eh *handler.EventHandler, mg xpresource.Managed) {
+	if mg.GetCondition(resource.TypeStalled).Status != corev1.ConditionTrue {
+		return
+	}
+	mg.SetConditions(resource.RecoveredCondition())
+	if eh != nil {
+		eh.Forget(handler.TerminalErrorRateLimiter, mg.GetName())
+	}
+}
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/crossplane/upjet/pkg/controller/handler"
+	"github.com/crossplane/upjet/pkg/resource"
+	"github.com/crossplane/upjet/pkg/resource/fake"
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
+)
+
+func TestTerminalErrorBackOff(t *testing.T) {
+	terminalErr := tferrors.NewClassifier(tferrors.DefaultClassificationRules()...).Classify(errors.New("AccessDenied: not authorized to perform: rds:CreateDBInstance"))
+	type args struct {
+		err        error
+		generation int64
+	}
+	type want struct {
+		stalled   corev1.ConditionStatus
+		backedOff bool
+	}
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"TerminalError": {
+			reason: "The Terraform operations should be backed off after a terminal error.",
+			args: args{
+				err: terminalErr,
+			},
+			want: want{
+				stalled:   corev1.ConditionTrue,
+				backedOff: true,
+			},
+		},
+		"NonTerminalError": {
+			reason: "The Terraform operations should not be backed off after an error that is not terminal.",
+			args: args{
+				err: errBoom,
+			},
+			want: want{
+				stalled: corev1.ConditionUnknown,
+			},
+		},
+		"GenerationChanged": {
+			reason: "A change in the generation of the resource should end its backoff.",
+			args: args{
+				err:        terminalErr,
+				generation: 1,
+			},
+			want: want{
+				stalled: corev1.ConditionTrue,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tr := &fake.Terraformed{}
+			tr.SetName("name")
+			eh := handler.NewEventHandler(handler.WithLogger(logging.NewNopLogger()))
+			q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer q.ShutDown()
+			eh.Generic(context.TODO(), event.GenericEvent{Object: tr}, q)
+
+			if err := backOffTerminalError(eh, tr, tc.args.err); !errors.Is(err, tc.args.err) {
+				t.Errorf("\n%s\nbackOffTerminalError(...): want the specified error, got: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.stalled, tr.GetCondition(resource.TypeStalled).Status); diff != "" {
+				t.Errorf("\n%s\nbackOffTerminalError(...): -want Stalled status, +got Stalled status:\n%s", tc.reason, diff)
+			}
+			tr.SetGeneration(tc.args.generation)
+			if diff := cmp.Diff(tc.want.backedOff, backedOffError(eh, tr) != nil); diff != "" {
+				t.Errorf("\n%s\nbackedOffError(...): -want backed off, +got backed off:\n%s", tc.reason, diff)
+			}
+
+			clearTerminalError(eh, tr)
+			if diff := cmp.Diff(corev1.ConditionTrue, tr.GetCondition(resource.TypeStalled).Status); diff == "" {
+				t.Errorf("\n%s\nclearTerminalError(...): want the Stalled condition to be cleared", tc.reason)
+			}
+			if err := backedOffError(eh, tr); err != nil {
+				t.Errorf("\n%s\nclearTerminalError(...): want the backoff to be reset, got: %v", tc.reason, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package importer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/crossplane/upjet/pkg/config"
+)
+
+const (
+	errNoImportIDs   = "at least one import ID must be supplied"
+	errFmtReadIDFile = "cannot read the import IDs from file %q"
+)
+
+// Run parses the given command-line arguments and writes the managed
+// resources generated for the requested import IDs to the supplied writer.
+// It's meant to be called from a provider's own importer command, which
+// supplies the provider configuration and a Reader configured with the
+// provider's credentials. The following flags are supported:
+//
+//	--resource-type, -t: The Terraform resource type, e.g. aws_vpc.
+//	--id, -i:            An import ID. Can be repeated.
+//	--id-file, -f:       A file containing one import ID per line.
+//	--provider-config:   The ProviderConfig referenced by the resources.
+func Run(ctx context.Context, pc *config.Provider, r Reader, args []string, out io.Writer, opts ...Option) error {
+	var (
+		app               = kingpin.New("importer", "Generates managed resources for the existing external resources with the given Terraform import IDs.")
+		resourceType      = app.Flag("resource-type", "Terraform resource type of the resources to be imported.").Short('t').Required().String()
+		importIDs         = app.Flag("id", "Terraform import ID of a resource to be imported.").Short('i').Strings()
+		idFile            = app.Flag("id-file", "Path of a file containing one Terraform import ID per line.").Short('f').String()
+		providerConfigRef = app.Flag("provider-config", "Name of the ProviderConfig to be referenced by the generated resources.").String()
+	)
+	if _, err := app.Parse(args); err != nil {
+		return err
+	}
+	ids := *importIDs
+	if *idFile != "" {
+		fileIDs, err := readImportIDs(*idFile)
+		if err != nil {
+			return errors.Wrapf(err, errFmtReadIDFile, *idFile)
+		}
+		ids = append(ids, fileIDs...)
+	}
+	if len(ids) == 0 {
+		return errors.New(errNoImportIDs)
+	}
+	if *providerConfigRef != "" {
+		opts = append(opts, WithProviderConfigRef(*providerConfigRef))
+	}
+	// the successfully imported resources are written even if some of
+	// the resources could not be imported
+	resources, importErr := New(pc, r, opts...).Import(ctx, *resourceType, ids...)
+	if err := Write(out, resources); err != nil {
+		return err
+	}
+	return importErr
+}
+
+func readImportIDs(path string) ([]string, error) {
+	f, err := os.Open(path) //nolint:gosec // the path is supplied by the user on purpose
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck // read-only file
+	var ids []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if id := strings.TrimSpace(s.Text()); id != "" && !strings.HasPrefix(id, "#") {
+			ids = append(ids, id)
+		}
+	}
+	return ids, s.Err()
+}
@@ -0,0 +1,250 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package importer generates managed resource manifests for the existing
+// external resources identified by their Terraform import IDs.
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"regexp"
+	"strings"
+
+	xpmeta "github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/upjet/pkg/config"
+	"github.com/crossplane/upjet/pkg/terraform"
+	"github.com/crossplane/upjet/pkg/types/name"
+)
+
+const (
+	errFmtNoResource  = "resource %q is not configured in the provider"
+	errFmtReadState   = "cannot read the state of the resource with import ID %q"
+	errFmtGetExternal = "cannot get the external name of the resource with import ID %q"
+	errFmtGetID       = "cannot get the Terraform ID of the resource with import ID %q"
+	errFmtIDMismatch  = "Terraform ID %q computed from external name %q does not match the Terraform ID %q of the resource with import ID %q"
+	errFmtEmptyName   = "cannot name the managed resource of the resource with import ID %q"
+	errMarshal        = "cannot marshal the managed resource"
+	errWrite          = "cannot write the managed resource"
+
+	maxNameLength = 63
+	// generatedNamePrefix is the prefix of the names generated for
+	// the external names that do not contain any valid name characters.
+	generatedNamePrefix = "imported-"
+	generatedHashLength = 10
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// NameFn returns the name of the managed resource to be generated for an
+// external resource with the given external name.
+type NameFn func(externalName string) string
+
+// DefaultNameFn converts the given external name to a valid Kubernetes object
+// name by lower-casing it, replacing the unsupported characters with dashes
+// and truncating it to 63 characters. If the external name does not contain
+// any valid name characters, a name is generated from its hash.
+func DefaultNameFn(externalName string) string {
+	n := invalidNameChars.ReplaceAllString(strings.ToLower(externalName), "-")
+	if len(n) > maxNameLength {
+		n = n[:maxNameLength]
+	}
+	if n = strings.Trim(n, "-."); n != "" {
+		return n
+	}
+	h := sha256.Sum256([]byte(externalName))
+	return generatedNamePrefix + hex.EncodeToString(h[:])[:generatedHashLength]
+}
+
+// Option configures an Importer.
+type Option func(i *Importer)
+
+// WithSetup configures the Terraform setup passed to the GetIDFn of the
+// resources while verifying their computed Terraform IDs.
+func WithSetup(ts terraform.Setup) Option {
+	return func(i *Importer) {
+		i.setup = ts
+	}
+}
+
+// WithNameFn configures the function used to name the generated managed
+// resources.
+func WithNameFn(fn NameFn) Option {
+	return func(i *Importer) {
+		i.nameFn = fn
+	}
+}
+
+// WithProviderConfigRef configures the name of the ProviderConfig referenced
+// by the generated managed resources.
+func WithProviderConfigRef(name string) Option {
+	return func(i *Importer) {
+		i.providerConfigRef = name
+	}
+}
+
+// Importer generates managed resources for the existing external resources.
+type Importer struct {
+	provider          *config.Provider
+	reader            Reader
+	setup             terraform.Setup
+	nameFn            NameFn
+	providerConfigRef string
+}
+
+// New returns a new Importer that generates the managed resources of the
+// given provider using the states read by the supplied Reader.
+func New(pc *config.Provider, r Reader, opts ...Option) *Importer {
+	i := &Importer{
+		provider: pc,
+		reader:   r,
+		nameFn:   DefaultNameFn,
+	}
+	for _, o := range opts {
+		o(i)
+	}
+	return i
+}
+
+// Import reads the external resources of the given Terraform resource type
+// with the supplied import IDs and returns the corresponding managed
+// resources with their spec.forProvider populated from the observed states.
+// A failure to import a resource does not stop the import of the others:
+// the managed resources of the successfully imported ones are returned
+// together with an aggregate of the errors of the failed ones.
+func (i *Importer) Import(ctx context.Context, resourceType string, importIDs ...string) ([]*unstructured.Unstructured, error) {
+	cfg, ok := i.provider.Resources[resourceType]
+	if !ok {
+		return nil, errors.Errorf(errFmtNoResource, resourceType)
+	}
+	result := make([]*unstructured.Unstructured, 0, len(importIDs))
+	var errs []error
+	for _, id := range importIDs {
+		u, err := i.importResource(ctx, cfg, id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		result = append(result, u)
+	}
+	return result, kerrors.NewAggregate(errs)
+}
+
+func (i *Importer) importResource(ctx context.Context, cfg *config.Resource, importID string) (*unstructured.Unstructured, error) {
+	state, err := i.reader.Read(ctx, cfg, importID)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtReadState, importID)
+	}
+	en, err := cfg.ExternalName.GetExternalNameFn(state)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtGetExternal, importID)
+	}
+	id, err := cfg.ExternalName.GetIDFn(ctx, en, state, i.setup.Map())
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtGetID, importID)
+	}
+	// the import ID is not necessarily the Terraform ID of the resource,
+	// e.g., the ID of a resource might be computed from multiple
+	// attributes, so the computed ID is verified against the read state.
+	if stateID, ok := state["id"].(string); ok && id != stateID {
+		return nil, errors.Errorf(errFmtIDMismatch, id, en, stateID, importID)
+	}
+	n := i.nameFn(en)
+	if n == "" {
+		return nil, errors.Errorf(errFmtEmptyName, importID)
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(i.provider.APIGroup(cfg) + "/" + cfg.Version)
+	u.SetKind(cfg.Kind)
+	u.SetName(n)
+	xpmeta.SetExternalName(u, en)
+	spec := map[string]any{
+		"forProvider": forProvider(cfg, state),
+	}
+	if i.providerConfigRef != "" {
+		spec["providerConfigRef"] = map[string]any{
+			"name": i.providerConfigRef,
+		}
+	}
+	u.Object["spec"] = spec
+	return u, nil
+}
+
+// forProvider converts the given Terraform state into the spec.forProvider
+// of the managed resource. The computed-only, sensitive and omitted
+// attributes are not generated as parameters, so they are skipped.
+func forProvider(cfg *config.Resource, state map[string]any) map[string]any {
+	omitted := make(map[string]struct{}, len(cfg.ExternalName.OmittedFields)+1)
+	omitted["id"] = struct{}{}
+	for _, f := range cfg.ExternalName.OmittedFields {
+		omitted[f] = struct{}{}
+	}
+	return convertParameters(cfg.TerraformResource.Schema, state, omitted)
+}
+
+func convertParameters(sch map[string]*schema.Schema, state map[string]any, omitted map[string]struct{}) map[string]any {
+	params := make(map[string]any, len(state))
+	for k, v := range state {
+		s, ok := sch[k]
+		if !ok || v == nil || s.Sensitive || (s.Computed && !s.Optional) {
+			continue
+		}
+		if _, ok := omitted[k]; ok {
+			continue
+		}
+		if v = convertValue(s, v); v != nil {
+			params[name.NewFromSnake(k).LowerCamelComputed] = v
+		}
+	}
+	return params
+}
+
+func convertValue(s *schema.Schema, v any) any {
+	res, ok := s.Elem.(*schema.Resource)
+	if !ok {
+		return v
+	}
+	items, ok := v.([]any)
+	if !ok {
+		return v
+	}
+	result := make([]any, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		result = append(result, convertParameters(res.Schema, m, nil))
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// Write writes the given managed resources as a multi-document YAML stream.
+func Write(w io.Writer, resources []*unstructured.Unstructured) error {
+	for j, u := range resources {
+		b, err := yaml.Marshal(u.Object)
+		if err != nil {
+			return errors.Wrap(err, errMarshal)
+		}
+		if j > 0 {
+			b = append([]byte("---\n"), b...)
+		}
+		if _, err := w.Write(b); err != nil {
+			return errors.Wrap(err, errWrite)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,429 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package importer
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/upjet/pkg/config"
+)
+
+var errBoom = errors.New("boom")
+
+// stubResource is a Terraform resource whose read function serves the
+// attributes of the existing resources from an in-memory store.
+func stubResource(existing map[string]map[string]any) *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"cidr_block": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"password": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"arn": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"tag_spec": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key_name": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		ReadContext: func(_ context.Context, d *schema.ResourceData, _ any) diag.Diagnostics {
+			attrs, ok := existing[d.Id()]
+			if !ok {
+				d.SetId("")
+				return nil
+			}
+			for k, v := range attrs {
+				if err := d.Set(k, v); err != nil {
+					return diag.FromErr(err)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func stubProvider(existing map[string]map[string]any) *config.Provider {
+	return &config.Provider{
+		RootGroup: "stub.upbound.io",
+		Resources: map[string]*config.Resource{
+			"stub_network": {
+				Name:              "stub_network",
+				TerraformResource: stubResource(existing),
+				ShortGroup:        "ec2",
+				Version:           "v1beta1",
+				Kind:              "Network",
+				ExternalName:      config.IdentifierFromProvider,
+			},
+		},
+	}
+}
+
+func TestImport(t *testing.T) {
+	existing := map[string]map[string]any{
+		"Net_1": {
+			"name":       "net",
+			"cidr_block": "10.0.0.0/16",
+			"password":   "s3cr3t",
+			"arn":        "arn:stub:net-1",
+			"tag_spec": []any{
+				map[string]any{"key_name": "env"},
+			},
+		},
+	}
+	type args struct {
+		resourceType string
+		importIDs    []string
+		reader       Reader
+		getIDFn      config.GetIDFn
+		shortGroup   *string
+		opts         []Option
+	}
+	type want struct {
+		resources []*unstructured.Unstructured
+		err       error
+	}
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"Success": {
+			reason: "The managed resource should be generated with the parameters read from the stub provider.",
+			args: args{
+				resourceType: "stub_network",
+				importIDs:    []string{"Net_1"},
+				reader:       NewNoForkReader(nil),
+				opts:         []Option{WithProviderConfigRef("default")},
+			},
+			want: want{
+				resources: []*unstructured.Unstructured{
+					{
+						Object: map[string]any{
+							"apiVersion": "ec2.stub.upbound.io/v1beta1",
+							"kind":       "Network",
+							"metadata": map[string]any{
+								"name": "net-1",
+								"annotations": map[string]any{
+									"crossplane.io/external-name": "Net_1",
+								},
+							},
+							"spec": map[string]any{
+								"forProvider": map[string]any{
+									"name":      "net",
+									"cidrBlock": "10.0.0.0/16",
+									"tagSpec": []any{
+										map[string]any{"keyName": "env"},
+									},
+								},
+								"providerConfigRef": map[string]any{
+									"name": "default",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"NotFound": {
+			reason: "An error should be returned if the external resource does not exist.",
+			args: args{
+				resourceType: "stub_network",
+				importIDs:    []string{"net-2"},
+				reader:       NewNoForkReader(nil),
+			},
+			want: want{
+				resources: []*unstructured.Unstructured{},
+				err:       kerrors.NewAggregate([]error{errors.Wrapf(errors.Errorf(errFmtNotFound, "net-2"), errFmtReadState, "net-2")}),
+			},
+		},
+		"ReadError": {
+			reason: "An error should be returned if the state of the external resource cannot be read.",
+			args: args{
+				resourceType: "stub_network",
+				importIDs:    []string{"Net_1"},
+				reader: ReaderFn(func(_ context.Context, _ *config.Resource, _ string) (map[string]any, error) {
+					return nil, errBoom
+				}),
+			},
+			want: want{
+				resources: []*unstructured.Unstructured{},
+				err:       kerrors.NewAggregate([]error{errors.Wrapf(errBoom, errFmtReadState, "Net_1")}),
+			},
+		},
+		"PartialFailure": {
+			reason: "The resources that can be imported should be returned together with the errors of the ones that cannot be imported.",
+			args: args{
+				resourceType: "stub_network",
+				importIDs:    []string{"net-2", "Net_1", "net-3"},
+				reader: ReaderFn(func(_ context.Context, _ *config.Resource, importID string) (map[string]any, error) {
+					if importID != "Net_1" {
+						return nil, errBoom
+					}
+					return map[string]any{"id": importID, "cidr_block": "10.0.0.0/16"}, nil
+				}),
+			},
+			want: want{
+				resources: []*unstructured.Unstructured{
+					{
+						Object: map[string]any{
+							"apiVersion": "ec2.stub.upbound.io/v1beta1",
+							"kind":       "Network",
+							"metadata": map[string]any{
+								"name": "net-1",
+								"annotations": map[string]any{
+									"crossplane.io/external-name": "Net_1",
+								},
+							},
+							"spec": map[string]any{
+								"forProvider": map[string]any{
+									"cidrBlock": "10.0.0.0/16",
+								},
+							},
+						},
+					},
+				},
+				err: kerrors.NewAggregate([]error{
+					errors.Wrapf(errBoom, errFmtReadState, "net-2"),
+					errors.Wrapf(errBoom, errFmtReadState, "net-3"),
+				}),
+			},
+		},
+		"ImportIDNotTerraformID": {
+			reason: "The resource should be imported if the Terraform ID computed from the external name matches the ID in the state, even if the import ID differs.",
+			args: args{
+				resourceType: "stub_network",
+				importIDs:    []string{"region/imported-id"},
+				reader: ReaderFn(func(_ context.Context, _ *config.Resource, _ string) (map[string]any, error) {
+					return map[string]any{"id": "imported-id"}, nil
+				}),
+			},
+			want: want{
+				resources: []*unstructured.Unstructured{
+					{
+						Object: map[string]any{
+							"apiVersion": "ec2.stub.upbound.io/v1beta1",
+							"kind":       "Network",
+							"metadata": map[string]any{
+								"name": "imported-id",
+								"annotations": map[string]any{
+									"crossplane.io/external-name": "imported-id",
+								},
+							},
+							"spec": map[string]any{
+								"forProvider": map[string]any{},
+							},
+						},
+					},
+				},
+			},
+		},
+		"IDMismatch": {
+			reason: "An error should be returned if the Terraform ID computed from the external name does not match the ID in the state.",
+			args: args{
+				resourceType: "stub_network",
+				importIDs:    []string{"imported-id"},
+				reader: ReaderFn(func(_ context.Context, _ *config.Resource, _ string) (map[string]any, error) {
+					return map[string]any{"id": "imported-id"}, nil
+				}),
+				getIDFn: func(_ context.Context, externalName string, _ map[string]any, _ map[string]any) (string, error) {
+					return "prefix/" + externalName, nil
+				},
+			},
+			want: want{
+				resources: []*unstructured.Unstructured{},
+				err:       kerrors.NewAggregate([]error{errors.Errorf(errFmtIDMismatch, "prefix/imported-id", "imported-id", "imported-id", "imported-id")}),
+			},
+		},
+		"MixedCaseShortGroup": {
+			reason: "The API group should be derived from the lower-cased ShortGroup, as the generated APIs are.",
+			args: args{
+				resourceType: "stub_network",
+				importIDs:    []string{"imported-id"},
+				reader: ReaderFn(func(_ context.Context, _ *config.Resource, _ string) (map[string]any, error) {
+					return map[string]any{"id": "imported-id"}, nil
+				}),
+				shortGroup: ptr.To("EC2"),
+			},
+			want: want{
+				resources: []*unstructured.Unstructured{
+					{
+						Object: map[string]any{
+							"apiVersion": "ec2.stub.upbound.io/v1beta1",
+							"kind":       "Network",
+							"metadata": map[string]any{
+								"name": "imported-id",
+								"annotations": map[string]any{
+									"crossplane.io/external-name": "imported-id",
+								},
+							},
+							"spec": map[string]any{
+								"forProvider": map[string]any{},
+							},
+						},
+					},
+				},
+			},
+		},
+		"NoShortGroup": {
+			reason: "The resources without a ShortGroup should be imported in the RootGroup.",
+			args: args{
+				resourceType: "stub_network",
+				importIDs:    []string{"imported-id"},
+				reader: ReaderFn(func(_ context.Context, _ *config.Resource, _ string) (map[string]any, error) {
+					return map[string]any{"id": "imported-id"}, nil
+				}),
+				shortGroup: ptr.To(""),
+			},
+			want: want{
+				resources: []*unstructured.Unstructured{
+					{
+						Object: map[string]any{
+							"apiVersion": "stub.upbound.io/v1beta1",
+							"kind":       "Network",
+							"metadata": map[string]any{
+								"name": "imported-id",
+								"annotations": map[string]any{
+									"crossplane.io/external-name": "imported-id",
+								},
+							},
+							"spec": map[string]any{
+								"forProvider": map[string]any{},
+							},
+						},
+					},
+				},
+			},
+		},
+		"UnknownResource": {
+			reason: "An error should be returned if the resource type is not configured in the provider.",
+			args: args{
+				resourceType: "stub_unknown",
+				importIDs:    []string{"Net_1"},
+				reader:       NewNoForkReader(nil),
+			},
+			want: want{
+				err: errors.Errorf(errFmtNoResource, "stub_unknown"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pc := stubProvider(existing)
+			if tc.args.getIDFn != nil {
+				pc.Resources["stub_network"].ExternalName.GetIDFn = tc.args.getIDFn
+			}
+			if tc.args.shortGroup != nil {
+				pc.Resources["stub_network"].ShortGroup = *tc.args.shortGroup
+			}
+			i := New(pc, tc.args.reader, tc.args.opts...)
+			got, err := i.Import(context.TODO(), tc.args.resourceType, tc.args.importIDs...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nImport(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.resources, got); diff != "" {
+				t.Errorf("\n%s\nImport(...): -want resources, +got resources:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDefaultNameFn(t *testing.T) {
+	cases := map[string]struct {
+		reason       string
+		externalName string
+		want         string
+	}{
+		"Valid": {
+			reason:       "A valid external name should be used as is.",
+			externalName: "net-1",
+			want:         "net-1",
+		},
+		"Converted": {
+			reason:       "The unsupported characters should be replaced with dashes.",
+			externalName: "Net_1/Sub",
+			want:         "net-1-sub",
+		},
+		"NoValidCharacters": {
+			reason:       "A name should be generated if the external name does not contain any valid name characters.",
+			externalName: "__",
+			want:         "imported-9911f4d2b1",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, DefaultNameFn(tc.externalName)); diff != "" {
+				t.Errorf("\n%s\nDefaultNameFn(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	existing := map[string]map[string]any{
+		"net-1": {"cidr_block": "10.0.0.0/16"},
+		"net-2": {"cidr_block": "10.1.0.0/16"},
+	}
+	want := `apiVersion: ec2.stub.upbound.io/v1beta1
+kind: Network
+metadata:
+  annotations:
+    crossplane.io/external-name: net-1
+  name: net-1
+spec:
+  forProvider:
+    cidrBlock: 10.0.0.0/16
+---
+apiVersion: ec2.stub.upbound.io/v1beta1
+kind: Network
+metadata:
+  annotations:
+    crossplane.io/external-name: net-2
+  name: net-2
+spec:
+  forProvider:
+    cidrBlock: 10.1.0.0/16
+`
+	out := &bytes.Buffer{}
+	err := Run(context.TODO(), stubProvider(existing), NewNoForkReader(nil), []string{"-t", "stub_network", "-i", "net-1", "--id", "net-2"}, out)
+	if diff := cmp.Diff(nil, err, test.EquateErrors()); diff != "" {
+		t.Errorf("\nRun(...): -want error, +got error:\n%s", diff)
+	}
+	if diff := cmp.Diff(want, out.String()); diff != "" {
+		t.Errorf("\nRun(...): -want output, +got output:\n%s", diff)
+	}
+}
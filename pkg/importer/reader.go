@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package importer
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	tf "github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/upjet/pkg/config"
+)
+
+const (
+	errNoTerraformResource = "resource configuration does not have a Terraform resource schema"
+	errFmtImport           = "cannot import the Terraform resource with import ID %q"
+	errFmtRead             = "cannot read the Terraform resource with import ID %q"
+	errFmtNotFound         = "Terraform resource with import ID %q does not exist"
+	errConvertState        = "cannot convert the Terraform state to JSON"
+)
+
+// Reader reads the Terraform state of an existing external resource
+// identified by its Terraform import ID.
+type Reader interface {
+	Read(ctx context.Context, cfg *config.Resource, importID string) (map[string]any, error)
+}
+
+// ReaderFn is a function that satisfies the Reader interface.
+type ReaderFn func(ctx context.Context, cfg *config.Resource, importID string) (map[string]any, error)
+
+// Read reads the Terraform state of the external resource with the given
+// import ID.
+func (fn ReaderFn) Read(ctx context.Context, cfg *config.Resource, importID string) (map[string]any, error) {
+	return fn(ctx, cfg, importID)
+}
+
+// NoForkReader reads external resources by calling the importer and the read
+// functions of the Terraform plugin SDK resources in-process, i.e., without
+// forking the Terraform CLI.
+type NoForkReader struct {
+	meta any
+}
+
+// NewNoForkReader returns a new NoForkReader that uses the given configured
+// Terraform provider meta while calling the Terraform resources.
+func NewNoForkReader(meta any) *NoForkReader {
+	return &NoForkReader{
+		meta: meta,
+	}
+}
+
+// Read imports the external resource with the given import ID and refreshes
+// its state. The returned map is keyed by the Terraform attribute names.
+func (r *NoForkReader) Read(ctx context.Context, cfg *config.Resource, importID string) (map[string]any, error) {
+	res := cfg.TerraformResource
+	if res == nil {
+		return nil, errors.New(errNoTerraformResource)
+	}
+	s := &tf.InstanceState{
+		ID: importID,
+		Attributes: map[string]string{
+			"id": importID,
+		},
+	}
+	if res.Importer != nil {
+		imported, err := r.importState(ctx, res, s)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtImport, importID)
+		}
+		if imported != nil {
+			s = imported
+		}
+	}
+	newState, diags := res.RefreshWithoutUpgrade(ctx, s, r.meta)
+	if diags.HasError() {
+		return nil, errors.Errorf(errFmtRead+": %v", importID, diags)
+	}
+	if newState == nil || newState.ID == "" {
+		return nil, errors.Errorf(errFmtNotFound, importID)
+	}
+	impliedType := res.CoreConfigSchema().ImpliedType()
+	v, err := newState.AttrsAsObjectValue(impliedType)
+	if err != nil {
+		return nil, errors.Wrap(err, errConvertState)
+	}
+	m, err := schema.StateValueToJSONMap(v, impliedType)
+	return m, errors.Wrap(err, errConvertState)
+}
+
+func (r *NoForkReader) importState(ctx context.Context, res *schema.Resource, s *tf.InstanceState) (*tf.InstanceState, error) {
+	var (
+		data []*schema.ResourceData
+		err  error
+	)
+	switch {
+	case res.Importer.StateContext != nil:
+		data, err = res.Importer.StateContext(ctx, res.Data(s), r.meta)
+	case res.Importer.State != nil: //nolint:staticcheck // still used by some providers
+		data, err = res.Importer.State(res.Data(s), r.meta) //nolint:staticcheck // still used by some providers
+	default:
+		return nil, nil
+	}
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	return data[0].State(), nil
+}
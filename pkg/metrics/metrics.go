@@ -84,6 +84,15 @@ var (
 		Help:      "The number of running Terraform CLI and Terraform provider processes",
 	}, []string{"type"})
 
+	// WorkspaceDiskUsage is the total size of the Terraform workspace
+	// directories.
+	WorkspaceDiskUsage = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: promNSUpjet,
+		Subsystem: promSysTF,
+		Name:      "workspace_disk_usage_bytes",
+		Help:      "The total size of the Terraform workspace directories in bytes",
+	})
+
 	// TTRMeasurements are the time-to-readiness measurements for
 	// the managed resources.
 	TTRMeasurements = prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -93,6 +102,41 @@ var (
 		Help:      "Measures in seconds the time-to-readiness (TTR) for managed resources",
 		Buckets:   []float64{1, 5, 10, 15, 30, 60, 120, 300, 600, 1800, 3600},
 	}, []string{"group", "version", "kind"})
+
+	// QueuedOperations are the number of asynchronous operations waiting
+	// for the concurrency limits to allow them to run.
+	QueuedOperations = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: promNSUpjet,
+		Subsystem: promSysResource,
+		Name:      "queued_async_operations",
+		Help:      "The number of asynchronous operations queued due to the configured concurrency limits",
+	}, []string{"group", "version", "kind", "provider_config"})
+
+	// SetupCacheRequests are the number of Terraform setup cache lookups.
+	SetupCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNSUpjet,
+		Subsystem: promSysTF,
+		Name:      "setup_cache_requests_total",
+		Help:      "The number of Terraform setup cache lookups partitioned by their results",
+	}, []string{"result"})
+
+	// NativeProviderCrashes are the number of crashes and failed liveness
+	// probes of the shared native Terraform providers.
+	NativeProviderCrashes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNSUpjet,
+		Subsystem: promSysTF,
+		Name:      "native_provider_crashes_total",
+		Help:      "The number of shared native provider crashes partitioned by their reasons",
+	}, []string{"reason"})
+
+	// SharedProviders is the number of native provider processes run by
+	// the shared provider scheduler.
+	SharedProviders = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: promNSUpjet,
+		Subsystem: promSysTF,
+		Name:      "shared_native_providers",
+		Help:      "The number of native provider processes run by the shared provider scheduler",
+	})
 )
 
 var _ manager.Runnable = &MetricRecorder{}
@@ -174,5 +218,5 @@ func (r *MetricRecorder) Start(ctx context.Context) error {
 }
 
 func init() {
-	metrics.Registry.MustRegister(CLITime, CLIExecutions, TFProcesses, TTRMeasurements, ExternalAPITime, DeletionTime, ReconcileDelay)
+	metrics.Registry.MustRegister(CLITime, CLIExecutions, TFProcesses, WorkspaceDiskUsage, TTRMeasurements, ExternalAPITime, DeletionTime, ReconcileDelay, QueuedOperations, SetupCacheRequests, NativeProviderCrashes, SharedProviders)
 }
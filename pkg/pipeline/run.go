@@ -33,10 +33,7 @@ func Run(pc *config.Provider, rootDir string) { //nolint:gocyclo
 	// ec2.aws.upbound.io -> v1beta1 -> aws_vpc
 	resourcesGroups := map[string]map[string]map[string]*config.Resource{}
 	for name, resource := range pc.Resources {
-		group := pc.RootGroup
-		if resource.ShortGroup != "" {
-			group = strings.ToLower(resource.ShortGroup) + "." + pc.RootGroup
-		}
+		group := pc.APIGroup(resource)
 		if len(resourcesGroups[group]) == 0 {
 			resourcesGroups[group] = map[string]map[string]*config.Resource{}
 		}
@@ -61,6 +61,7 @@ func (tg *TerraformedGenerator) Generate(cfgs []*terraformedInput, apiVersion st
 			},
 			"LateInitializer": map[string]any{
 				"IgnoredFields": cfg.LateInitializer.GetIgnoredCanonicalFields(),
+				"Policies":      cfg.LateInitializer.GetCanonicalPolicies(),
 			},
 		}
 		index++
@@ -7,9 +7,11 @@ package resource
 import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/crossplane/upjet/pkg/config"
 	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
 )
 
@@ -17,6 +19,8 @@ import (
 const (
 	TypeLastAsyncOperation = "LastAsyncOperation"
 	TypeAsyncOperation     = "AsyncOperation"
+	TypeDeletionProtection = "DeletionProtection"
+	TypeStalled            = "Stalled"
 
 	ReasonApplyFailure       xpv1.ConditionReason = "ApplyFailure"
 	ReasonDestroyFailure     xpv1.ConditionReason = "DestroyFailure"
@@ -27,6 +31,14 @@ const (
 	ReasonOngoing            xpv1.ConditionReason = "Ongoing"
 	ReasonFinished           xpv1.ConditionReason = "Finished"
 	ReasonResourceUpToDate   xpv1.ConditionReason = "UpToDate"
+	ReasonDeletionBlocked    xpv1.ConditionReason = "DeletionBlocked"
+	ReasonDeletionAllowed    xpv1.ConditionReason = "DeletionAllowed"
+	ReasonRecovered          xpv1.ConditionReason = "Recovered"
+
+	// The reasons of the terminal failures, i.e., the failures that
+	// are not expected to be resolved by retrying the failed operations.
+	ReasonPermissionDenied     = xpv1.ConditionReason(tferrors.ClassPermissionDenied)
+	ReasonInvalidConfiguration = xpv1.ConditionReason(tferrors.ClassInvalidConfiguration)
 )
 
 // LastAsyncOperationCondition returns the condition depending on the content
@@ -40,6 +52,14 @@ func LastAsyncOperationCondition(err error) xpv1.Condition {
 			LastTransitionTime: metav1.Now(),
 			Reason:             ReasonSuccess,
 		}
+	case tferrors.IsTerminal(err):
+		return xpv1.Condition{
+			Type:               TypeLastAsyncOperation,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             xpv1.ConditionReason(tferrors.ClassOf(err)),
+			Message:            err.Error(),
+		}
 	case tferrors.IsApplyFailed(err):
 		return xpv1.Condition{
 			Type:               TypeLastAsyncOperation,
@@ -133,3 +153,69 @@ func SetUpToDateCondition(mg xpresource.Managed, upToDate bool) {
 		mg.SetConditions(UpToDateCondition())
 	}
 }
+
+// DeletionBlockedCondition returns the condition reporting that the deletion
+// of the managed resource is blocked by its deletion protection.
+func DeletionBlockedCondition(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDeletionProtection,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDeletionBlocked,
+		Message:            msg,
+	}
+}
+
+// DeletionAllowedCondition returns the condition reporting that the deletion
+// of the managed resource is no longer blocked by its deletion protection.
+func DeletionAllowedCondition() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDeletionProtection,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDeletionAllowed,
+	}
+}
+
+// StalledCondition returns the condition reporting that the synchronous
+// Terraform operations on the managed resource have failed with
+// the specified terminal error, with the class of the error as its reason.
+func StalledCondition(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeStalled,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             xpv1.ConditionReason(tferrors.ClassOf(err)),
+		Message:            err.Error(),
+	}
+}
+
+// RecoveredCondition returns the condition reporting that the synchronous
+// Terraform operations on the managed resource no longer fail with
+// a terminal error.
+func RecoveredCondition() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeStalled,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonRecovered,
+	}
+}
+
+// SetCustomConditions sets the custom conditions configured for the resource
+// computed from its observed Terraform state. Each condition is set with
+// the current time as its last transition time, which is kept only if
+// the condition has changed: SetConditions does not replace an existing
+// condition that is equal to the new one regardless of their last
+// transition times.
+func SetCustomConditions(mg xpresource.Conditioned, cfg *config.Resource, tfstate map[string]any) error {
+	for _, cc := range cfg.CustomConditions {
+		c, err := cc.Evaluate(tfstate)
+		if err != nil {
+			return errors.Wrap(err, "cannot compute the custom condition")
+		}
+		c.LastTransitionTime = metav1.Now()
+		mg.SetConditions(c)
+	}
+	return nil
+}
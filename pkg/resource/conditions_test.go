@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"regexp"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
+)
+
+func TestLastAsyncOperationCondition(t *testing.T) {
+	applyFailed := tferrors.NewApplyFailed(nil)
+	classifier := tferrors.NewClassifier(tferrors.ClassificationRule{
+		Class:        tferrors.ClassPermissionDenied,
+		MessageRegex: regexp.MustCompile("denied"),
+	})
+	cases := map[string]struct {
+		err  error
+		want xpv1.Condition
+	}{
+		"Success": {
+			want: xpv1.Condition{
+				Type:   TypeLastAsyncOperation,
+				Status: corev1.ConditionTrue,
+				Reason: ReasonSuccess,
+			},
+		},
+		"ApplyFailed": {
+			err: applyFailed,
+			want: xpv1.Condition{
+				Type:    TypeLastAsyncOperation,
+				Status:  corev1.ConditionFalse,
+				Reason:  ReasonApplyFailure,
+				Message: applyFailed.Error(),
+			},
+		},
+		"TerminalFailure": {
+			err: errors.Wrap(classifier.Classify(errors.New("access denied")), "apply failed"),
+			want: xpv1.Condition{
+				Type:    TypeLastAsyncOperation,
+				Status:  corev1.ConditionFalse,
+				Reason:  ReasonPermissionDenied,
+				Message: "apply failed: access denied",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := LastAsyncOperationCondition(tc.err)
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(xpv1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("\n%s\nLastAsyncOperationCondition(...): -want, +got:\n%s", name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/upjet/pkg/config"
+)
+
+const (
+	templateKeyState      = "state"
+	templateKeyParameters = "parameters"
+
+	errGetTemplates          = "cannot get the connection details templates"
+	errGetTemplateParameters = "cannot get the parameters for the connection details templates"
+	errFmtExecuteTemplate    = "cannot render the connection details template for the key %q"
+)
+
+// renderConnectionDetailsTemplates renders the connection details templates
+// of the specified configuration over the Terraform state attributes and
+// the parameters of the specified resource. The null values are removed from
+// the objects the templates are rendered over, so that a template referring
+// to a null value fails to render like one referring to a missing key, and
+// incomplete credentials are not published.
+func renderConnectionDetailsTemplates(cfg *config.Resource, attr map[string]any, tr Terraformed) (map[string][]byte, error) {
+	templates, err := cfg.Sensitive.GetConnectionDetailsTemplates()
+	if err != nil {
+		return nil, errors.Wrap(err, errGetTemplates)
+	}
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	params, err := tr.GetParameters()
+	if err != nil {
+		return nil, errors.Wrap(err, errGetTemplateParameters)
+	}
+	data := map[string]any{
+		templateKeyState:      withoutNulls(attr),
+		templateKeyParameters: withoutNulls(params),
+	}
+	conn := make(map[string][]byte, len(templates))
+	for k, t := range templates {
+		buff := &bytes.Buffer{}
+		if err := t.Execute(buff, data); err != nil {
+			return nil, errors.Wrapf(err, errFmtExecuteTemplate, k)
+		}
+		conn[k] = buff.Bytes()
+	}
+	return conn, nil
+}
+
+// withoutNulls returns a copy of the specified object without the null
+// values of its fields and of the fields of its nested objects.
+func withoutNulls(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]any, len(m))
+	for k, v := range m {
+		if v = valueWithoutNulls(v); v != nil {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+func valueWithoutNulls(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		return withoutNulls(t)
+	case []any:
+		// the null elements of the lists are kept so that the indices of
+		// the other elements do not change.
+		l := make([]any, len(t))
+		for i, e := range t {
+			l[i] = valueWithoutNulls(e)
+		}
+		return l
+	default:
+		return v
+	}
+}
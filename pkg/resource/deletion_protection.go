@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/upjet/pkg/config"
+)
+
+const (
+	// AnnotationKeyDeletionProtection is the key of the annotation that
+	// protects a managed resource from deletion when set to "true", or
+	// allows the deletion of a managed resource whose kind is protected by
+	// default when set to "false".
+	AnnotationKeyDeletionProtection = "upjet.crossplane.io/deletion-protection"
+
+	errFmtGetNativeProtection     = "cannot get the observed value of the native deletion protection argument %q"
+	errFmtDisableNativeProtection = "cannot turn off the native deletion protection argument %q"
+
+	fmtProtectedByAnnotation = "The managed resource is protected from deletion by the %s annotation"
+	fmtProtectedByDefault    = "The managed resources of this kind are protected from deletion, set the %s annotation to \"false\" to allow the deletion"
+)
+
+// GetDeletionProtection returns a message explaining why the specified
+// managed resource is protected from deletion, or an empty string if it
+// can be deleted.
+func GetDeletionProtection(tr Terraformed, cfg *config.Resource) string {
+	switch tr.GetAnnotations()[AnnotationKeyDeletionProtection] {
+	case "true":
+		return fmt.Sprintf(fmtProtectedByAnnotation, AnnotationKeyDeletionProtection)
+	case "false":
+		return ""
+	default:
+		if cfg.DeletionProtection.Enabled {
+			return fmt.Sprintf(fmtProtectedByDefault, AnnotationKeyDeletionProtection)
+		}
+		return ""
+	}
+}
+
+// NativeDeletionProtectionEnabled reports whether the observed native
+// deletion protection argument of the specified managed resource is enabled.
+func NativeDeletionProtectionEnabled(tr Terraformed, cfg *config.Resource) (bool, error) {
+	if cfg.DeletionProtection.Attribute == "" {
+		return false, nil
+	}
+	obs, err := tr.GetObservation()
+	if err != nil {
+		return false, errors.Wrap(err, "cannot get the observation")
+	}
+	v, err := fieldpath.Pave(obs).GetBool(cfg.DeletionProtection.Attribute)
+	switch {
+	case fieldpath.IsNotFound(err):
+		return false, nil
+	case err != nil:
+		return false, errors.Wrapf(err, errFmtGetNativeProtection, cfg.DeletionProtection.Attribute)
+	default:
+		return v, nil
+	}
+}
+
+// DisableNativeDeletionProtection turns off the native deletion protection
+// argument in the specified parameters of a managed resource if it's being
+// deleted, it's not protected from deletion by its annotation, and its
+// observed native deletion protection is enabled. This allows the clients to
+// turn off the argument in the external resource before destroying it.
+// The other observed arguments are reset to their observed values so that
+// the pending changes in the spec are not applied together with the turned
+// off argument. Only the specified parameters, e.g., the ones the Terraform
+// configuration is generated from, are modified so that the turned off
+// argument is never persisted into the spec of the managed resource, which
+// would otherwise revert the deletion protection if the deletion is
+// canceled. It reports whether the parameters have been modified.
+func DisableNativeDeletionProtection(tr Terraformed, cfg *config.Resource, params map[string]any) (bool, error) {
+	if tr.GetDeletionTimestamp() == nil || GetDeletionProtection(tr, cfg) != "" {
+		return false, nil
+	}
+	enabled, err := NativeDeletionProtectionEnabled(tr, cfg)
+	if err != nil || !enabled {
+		return false, err
+	}
+	obs, err := tr.GetObservation()
+	if err != nil {
+		return false, errors.Wrap(err, "cannot get the observation")
+	}
+	resetToObserved(params, obs)
+	if err := fieldpath.Pave(params).SetValue(cfg.DeletionProtection.Attribute, false); err != nil {
+		return false, errors.Wrapf(err, errFmtDisableNativeProtection, cfg.DeletionProtection.Attribute)
+	}
+	return true, nil
+}
+
+// resetToObserved sets the specified parameters to their observed values.
+// The parameters that are not observed, e.g., the sensitive ones, are kept
+// as they are, and the objects are reset field by field so that no computed
+// field of an observed object ends up in the parameters.
+func resetToObserved(params, obs map[string]any) {
+	for k, v := range params {
+		o, ok := obs[k]
+		if !ok || o == nil {
+			continue
+		}
+		params[k] = resetValueToObserved(v, o)
+	}
+}
+
+func resetValueToObserved(v, o any) any {
+	switch p := v.(type) {
+	case map[string]any:
+		if om, ok := o.(map[string]any); ok {
+			resetToObserved(p, om)
+		}
+		return p
+	case []any:
+		ol, ok := o.([]any)
+		if !ok {
+			return p
+		}
+		// the elements of a block list are reset one by one if the number
+		// of the blocks has not changed, and the other lists are replaced
+		// with their observed values.
+		for i := range p {
+			if _, ok := p[i].(map[string]any); !ok {
+				return o
+			}
+		}
+		if len(p) != len(ol) {
+			return p
+		}
+		for i := range p {
+			p[i] = resetValueToObserved(p[i], ol[i])
+		}
+		return p
+	default:
+		return o
+	}
+}
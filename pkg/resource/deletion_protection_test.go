@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"testing"
+
+	xpfake "github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/upjet/pkg/config"
+	"github.com/crossplane/upjet/pkg/resource/fake"
+)
+
+func TestDisableNativeDeletionProtection(t *testing.T) {
+	deleted := metav1.Now()
+	cfg := &config.Resource{
+		DeletionProtection: config.DeletionProtection{Attribute: "deletion_protection"},
+	}
+	type args struct {
+		tr *fake.Terraformed
+	}
+	type want struct {
+		disabled bool
+		params   map[string]any
+		err      error
+	}
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"NotDeleted": {
+			reason: "The parameters of a managed resource that's not being deleted should not be modified.",
+			args: args{
+				tr: &fake.Terraformed{
+					Observable:      fake.Observable{Observation: map[string]any{"deletion_protection": true}},
+					Parameterizable: fake.Parameterizable{Parameters: map[string]any{"deletion_protection": true}},
+				},
+			},
+			want: want{
+				params: map[string]any{"deletion_protection": true},
+			},
+		},
+		"ProtectedByAnnotation": {
+			reason: "The parameters of a managed resource protected by its annotation should not be modified.",
+			args: args{
+				tr: &fake.Terraformed{
+					Managed: xpfake.Managed{ObjectMeta: metav1.ObjectMeta{
+						DeletionTimestamp: &deleted,
+						Annotations:       map[string]string{AnnotationKeyDeletionProtection: "true"},
+					}},
+					Observable:      fake.Observable{Observation: map[string]any{"deletion_protection": true}},
+					Parameterizable: fake.Parameterizable{Parameters: map[string]any{"deletion_protection": true}},
+				},
+			},
+			want: want{
+				params: map[string]any{"deletion_protection": true},
+			},
+		},
+		"ObservedDisabled": {
+			reason: "The parameters should not be modified if the native deletion protection is observed to be disabled.",
+			args: args{
+				tr: &fake.Terraformed{
+					Managed:         xpfake.Managed{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted}},
+					Observable:      fake.Observable{Observation: map[string]any{"deletion_protection": false}},
+					Parameterizable: fake.Parameterizable{Parameters: map[string]any{"deletion_protection": true}},
+				},
+			},
+			want: want{
+				params: map[string]any{"deletion_protection": true},
+			},
+		},
+		"Disabled": {
+			reason: "The native deletion protection argument should be turned off if it's observed to be enabled.",
+			args: args{
+				tr: &fake.Terraformed{
+					Managed:         xpfake.Managed{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted}},
+					Observable:      fake.Observable{Observation: map[string]any{"deletion_protection": true}},
+					Parameterizable: fake.Parameterizable{Parameters: map[string]any{"deletion_protection": true, "name": "db"}},
+				},
+			},
+			want: want{
+				disabled: true,
+				params:   map[string]any{"deletion_protection": false, "name": "db"},
+			},
+		},
+		"PendingChangesReset": {
+			reason: "The other observed arguments should be reset to their observed values so that only the native deletion protection argument is changed.",
+			args: args{
+				tr: &fake.Terraformed{
+					Managed: xpfake.Managed{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted}},
+					Observable: fake.Observable{Observation: map[string]any{
+						"deletion_protection": true,
+						"instance_class":      "db.t3.micro",
+						"arn":                 "arn:aws:rds:db",
+						"tags":                map[string]any{"team": "a"},
+						"subnet_ids":          []any{"subnet-1"},
+						"ingress":             []any{map[string]any{"port": float64(5432), "id": "computed"}},
+					}},
+					Parameterizable: fake.Parameterizable{Parameters: map[string]any{
+						"deletion_protection": true,
+						"instance_class":      "db.t3.large",
+						"password":            "secret",
+						"tags":                map[string]any{"team": "b"},
+						"subnet_ids":          []any{"subnet-1", "subnet-2"},
+						"ingress":             []any{map[string]any{"port": float64(5433)}},
+					}},
+				},
+			},
+			want: want{
+				disabled: true,
+				params: map[string]any{
+					"deletion_protection": false,
+					"instance_class":      "db.t3.micro",
+					"password":            "secret",
+					"tags":                map[string]any{"team": "a"},
+					"subnet_ids":          []any{"subnet-1"},
+					"ingress":             []any{map[string]any{"port": float64(5432)}},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			params := make(map[string]any, len(tc.args.tr.Parameters))
+			for k, v := range tc.args.tr.Parameters {
+				params[k] = v
+			}
+			disabled, err := DisableNativeDeletionProtection(tc.args.tr, cfg, params)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDisableNativeDeletionProtection(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.disabled, disabled); diff != "" {
+				t.Errorf("\n%s\nDisableNativeDeletionProtection(...): -want disabled, +got disabled:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.params, params); diff != "" {
+				t.Errorf("\n%s\nDisableNativeDeletionProtection(...): -want params, +got params:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(true, tc.args.tr.Parameters["deletion_protection"]); diff != "" {
+				t.Errorf("\n%s\nDisableNativeDeletionProtection(...): the parameters of the managed resource should not be modified:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/crossplane/upjet/pkg/config"
+	"github.com/crossplane/upjet/pkg/types/name"
+)
+
+const (
+	prefixParameters  = "spec.forProvider."
+	prefixObservation = "status.atProvider."
+
+	wildcardIndex = "[*]"
+)
+
+var (
+	// indexRegex matches the list indices and the map keys in Terraform
+	// attribute paths, which are not converted to camel case.
+	indexRegex = regexp.MustCompile(`\[[^\]]*\]`)
+)
+
+// DiagnosticFieldPath returns the field path of the managed resource field
+// with the specified Terraform attribute path, e.g.,
+// spec.forProvider.ingress[0].fromPort for ingress[0].from_port, so that
+// the Terraform diagnostics can be reported against the fields they are
+// about. The sensitive attributes are mapped to their secret references
+// using the specified connection details mapping of the managed resource and
+// the sensitive field paths of the specified configuration, and
+// the computed-only attributes are mapped to the status.atProvider fields.
+// Returns an empty string if the attribute path is empty.
+func DiagnosticFieldPath(cfg *config.Resource, connectionDetailsMapping map[string]string, attributePath string) string {
+	if attributePath == "" {
+		return ""
+	}
+	indices := indexRegex.FindAllString(attributePath, -1)
+	tfPath := indexRegex.ReplaceAllString(attributePath, wildcardIndex)
+	if xpPath, ok := sensitiveFieldPath(cfg, connectionDetailsMapping, tfPath); ok {
+		for _, i := range indices {
+			if !strings.Contains(xpPath, wildcardIndex) {
+				break
+			}
+			xpPath = strings.Replace(xpPath, wildcardIndex, i, 1)
+		}
+		return xpPath
+	}
+	var b strings.Builder
+	prefix := prefixParameters
+	if isObservation(cfg, attributePath) {
+		prefix = prefixObservation
+	}
+	b.WriteString(prefix)
+	last := 0
+	for _, loc := range indexRegex.FindAllStringIndex(attributePath, -1) {
+		b.WriteString(camelFields(attributePath[last:loc[0]]))
+		b.WriteString(attributePath[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(camelFields(attributePath[last:]))
+	return b.String()
+}
+
+// sensitiveFieldPath returns the field path of the secret reference or
+// the observed field of the sensitive attribute with the specified
+// wildcarded Terraform path, if the attribute is sensitive.
+func sensitiveFieldPath(cfg *config.Resource, connectionDetailsMapping map[string]string, tfPath string) (string, bool) {
+	if xpPath, ok := connectionDetailsMapping[tfPath]; ok {
+		return xpPath, true
+	}
+	if cfg == nil {
+		return "", false
+	}
+	xpPath, ok := cfg.Sensitive.GetFieldPaths()[tfPath]
+	return xpPath, ok
+}
+
+// isObservation reports whether the top-level attribute of the specified
+// Terraform attribute path is a computed-only attribute, which is generated
+// as a status.atProvider field.
+func isObservation(cfg *config.Resource, attributePath string) bool {
+	if cfg == nil || cfg.TerraformResource == nil {
+		return false
+	}
+	attr := attributePath
+	if i := strings.IndexAny(attr, ".["); i != -1 {
+		attr = attr[:i]
+	}
+	s, ok := cfg.TerraformResource.Schema[attr]
+	return ok && isComputedOnly(s)
+}
+
+func isComputedOnly(s *schema.Schema) bool {
+	return s.Computed && !s.Optional && !s.Required
+}
+
+func camelFields(s string) string {
+	fields := strings.Split(s, ".")
+	for i, f := range fields {
+		if f != "" {
+			fields[i] = name.NewFromSnake(f).LowerCamelComputed
+		}
+	}
+	return strings.Join(fields, ".")
+}
@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/crossplane/upjet/pkg/config"
+)
+
+func TestDiagnosticFieldPath(t *testing.T) {
+	cfg := &config.Resource{
+		TerraformResource: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"cidr_block": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"arn": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"tags": {
+					Type:     schema.TypeMap,
+					Optional: true,
+					Computed: true,
+				},
+			},
+		},
+	}
+	type args struct {
+		cfg           *config.Resource
+		mapping       map[string]string
+		attributePath string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   string
+	}{
+		"Empty": {
+			reason: "An empty attribute path should not be mapped to a field.",
+		},
+		"TopLevel": {
+			reason: "A top-level attribute should be mapped to its parameter.",
+			args: args{
+				cfg:           cfg,
+				attributePath: "cidr_block",
+			},
+			want: "spec.forProvider.cidrBlock",
+		},
+		"Nested": {
+			reason: "The list indices should be kept while mapping a nested attribute.",
+			args: args{
+				attributePath: "ingress[0].from_port",
+			},
+			want: "spec.forProvider.ingress[0].fromPort",
+		},
+		"MapKey": {
+			reason: "The map keys should not be converted to camel case.",
+			args: args{
+				cfg:           cfg,
+				attributePath: `tags["cost_center"]`,
+			},
+			want: `spec.forProvider.tags["cost_center"]`,
+		},
+		"Observation": {
+			reason: "A computed-only attribute should be mapped to its observed field.",
+			args: args{
+				cfg:           cfg,
+				attributePath: "arn",
+			},
+			want: "status.atProvider.arn",
+		},
+		"Sensitive": {
+			reason: "A sensitive attribute should be mapped to its secret reference.",
+			args: args{
+				cfg: cfg,
+				mapping: map[string]string{
+					"user[*].password": "spec.forProvider.user[*].passwordSecretRef",
+				},
+				attributePath: "user[1].password",
+			},
+			want: "spec.forProvider.user[1].passwordSecretRef",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, DiagnosticFieldPath(tc.args.cfg, tc.args.mapping, tc.args.attributePath)); diff != "" {
+				t.Errorf("\n%s\nDiagnosticFieldPath(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -5,9 +5,11 @@
 package resource
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"runtime/debug"
+	"sort"
 	"strings"
 
 	xpmeta "github.com/crossplane/crossplane-runtime/pkg/meta"
@@ -24,6 +26,24 @@ const (
 	// arbitrary metadata, usually details about schema version.
 	AnnotationKeyPrivateRawAttribute = "upjet.crossplane.io/provider-meta"
 
+	// AnnotationKeyLateInitializedFields is the key of the annotation that
+	// records the canonical names of the late-initialized fields of
+	// a managed resource, separated by commas, so that they can be told apart
+	// from the fields set by the users.
+	AnnotationKeyLateInitializedFields = "upjet.crossplane.io/late-initialized-fields"
+
+	// AnnotationKeyResetLateInitializedFields is the key of the annotation
+	// that requests the late-initialized fields of a managed resource to be
+	// removed from its spec.forProvider when set to "true".
+	AnnotationKeyResetLateInitializedFields = "upjet.crossplane.io/reset-late-initialized-fields"
+
+	// AnnotationKeyClearedLateInitializedFields is the key of the annotation
+	// that records the canonical names of the late-initialized fields
+	// removed from the spec.forProvider of a managed resource with
+	// a reset, separated by commas, so that they are not late-initialized
+	// again.
+	AnnotationKeyClearedLateInitializedFields = "upjet.crossplane.io/cleared-late-initialized-fields"
+
 	// AnnotationKeyTestResource is used for marking an MR as test for automated tests
 	AnnotationKeyTestResource = "upjet.upbound.io/test"
 
@@ -37,6 +57,8 @@ const (
 	errFmtPanic               = "recovered from panic: %v\n%s"
 	errFmtMapElemNotSupported = "map items of kind %q is not supported for canonical name: %s"
 	errFmtNotPtrToStruct      = "%s must be of a pointer to struct type: %#v"
+	errFmtNoForProvider       = "cannot find the spec.forProvider field of the object of type %T"
+	errFmtResetField          = "cannot reset the late-initialized field %q"
 
 	fmtCanonical = "%s.%s"
 )
@@ -45,6 +67,18 @@ const (
 type GenericLateInitializer struct {
 	valueFilters []ValueFilter
 	nameFilters  []NameFilter
+	policies     map[string]config.LateInitPolicy
+	// initialized is the set of the fields late-initialized in the previous
+	// late-initializations.
+	initialized map[string]struct{}
+	// cleared is the set of the fields removed from the spec with a reset,
+	// which are never late-initialized again.
+	cleared map[string]struct{}
+	// assigned are the fields late-initialized by this late-initializer.
+	assigned []string
+	// collectionDepth is the depth of the slices and maps being
+	// late-initialized, whose items are not recorded separately.
+	collectionDepth int
 }
 
 // SetCriticalAnnotations sets the critical annotations of the resource and reports
@@ -65,6 +99,26 @@ func SetCriticalAnnotations(tr metav1.Object, cfg *config.Resource, tfstate map[
 	return true, nil
 }
 
+// SetCriticalState sets the external name annotation of the resource, stores
+// its private state with the specified PrivateStateStore and reports whether
+// the resource has been modified.
+func SetCriticalState(ctx context.Context, tr metav1.Object, cfg *config.Resource, tfstate map[string]any, privateRaw string, s PrivateStateStore) (bool, error) {
+	name, err := cfg.ExternalName.GetExternalNameFn(tfstate)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot get external name")
+	}
+	updated := false
+	if tr.GetAnnotations()[xpmeta.AnnotationKeyExternalName] != name {
+		xpmeta.SetExternalName(tr, name)
+		updated = true
+	}
+	stateUpdated, err := s.Set(ctx, tr, privateRaw)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot store the private state")
+	}
+	return updated || stateUpdated, nil
+}
+
 // GenericLateInitializerOption are options that control the late-initialization
 // behavior of a Terraformed resource.
 type GenericLateInitializerOption func(l *GenericLateInitializer)
@@ -79,6 +133,164 @@ func NewGenericLateInitializer(opts ...GenericLateInitializerOption) *GenericLat
 	return l
 }
 
+// WithPolicy returns a GenericLateInitializerOption that configures
+// the late-initialization policy of the field with the specified canonical
+// name.
+func WithPolicy(cname string, p config.LateInitPolicy) GenericLateInitializerOption {
+	return func(l *GenericLateInitializer) {
+		if p == config.LateInitNever {
+			l.nameFilters = append(l.nameFilters, nameFilter(cname))
+			return
+		}
+		if l.policies == nil {
+			l.policies = make(map[string]config.LateInitPolicy)
+		}
+		l.policies[cname] = p
+	}
+}
+
+// WithLateInitializedFields returns a GenericLateInitializerOption that
+// configures the canonical names of the fields that have previously been
+// late-initialized, which are skipped if their policy is
+// config.LateInitOnce.
+func WithLateInitializedFields(cnames ...string) GenericLateInitializerOption {
+	return func(l *GenericLateInitializer) {
+		if l.initialized == nil {
+			l.initialized = make(map[string]struct{}, len(cnames))
+		}
+		for _, n := range cnames {
+			l.initialized[n] = struct{}{}
+		}
+	}
+}
+
+// WithClearedFields returns a GenericLateInitializerOption that configures
+// the canonical names of the fields that have been removed from the spec
+// with a reset, which are skipped regardless of their policy.
+func WithClearedFields(cnames ...string) GenericLateInitializerOption {
+	return func(l *GenericLateInitializer) {
+		if l.cleared == nil {
+			l.cleared = make(map[string]struct{}, len(cnames))
+		}
+		for _, n := range cnames {
+			l.cleared[n] = struct{}{}
+		}
+	}
+}
+
+// LateInitializedFields returns the canonical names of the fields
+// late-initialized by the last LateInitialize call.
+func (li *GenericLateInitializer) LateInitializedFields() []string {
+	return li.assigned
+}
+
+// GetLateInitializedFields returns the canonical names of the fields of
+// the specified object recorded as late-initialized.
+func GetLateInitializedFields(o metav1.Object) []string {
+	return getFields(o, AnnotationKeyLateInitializedFields)
+}
+
+// GetClearedLateInitializedFields returns the canonical names of the fields
+// of the specified object recorded as removed with a reset.
+func GetClearedLateInitializedFields(o metav1.Object) []string {
+	return getFields(o, AnnotationKeyClearedLateInitializedFields)
+}
+
+// RecordLateInitializedFields adds the specified canonical names to the
+// late-initialized fields recorded on the specified object.
+func RecordLateInitializedFields(o metav1.Object, cnames ...string) {
+	recordFields(o, AnnotationKeyLateInitializedFields, cnames...)
+}
+
+// getFields returns the canonical names recorded in the specified
+// annotation of the specified object.
+func getFields(o metav1.Object, key string) []string {
+	v := o.GetAnnotations()[key]
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+// recordFields adds the specified canonical names to the ones recorded in
+// the specified annotation of the specified object.
+func recordFields(o metav1.Object, key string, cnames ...string) {
+	if len(cnames) == 0 {
+		return
+	}
+	fields := getFields(o, key)
+	for _, n := range cnames {
+		if !contains(fields, n) {
+			fields = append(fields, n)
+		}
+	}
+	sort.Strings(fields)
+	xpmeta.AddAnnotations(o, map[string]string{
+		key: strings.Join(fields, ","),
+	})
+}
+
+// ResetLateInitializedFields removes the recorded late-initialized fields
+// from the spec.forProvider of the specified object if requested with
+// the AnnotationKeyResetLateInitializedFields annotation, and reports
+// whether the object has been modified. The removed fields are recorded in
+// the AnnotationKeyClearedLateInitializedFields annotation, so that they
+// are not late-initialized again, and the annotations recording the
+// late-initialized fields and requesting the reset are removed from
+// the object.
+func ResetLateInitializedFields(o metav1.Object) (bool, error) {
+	if o.GetAnnotations()[AnnotationKeyResetLateInitializedFields] != "true" {
+		return false, nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(o))
+	if v.Kind() != reflect.Struct || v.FieldByName("Spec").Kind() != reflect.Struct {
+		return false, errors.Errorf(errFmtNoForProvider, o)
+	}
+	fp := v.FieldByName("Spec").FieldByName("ForProvider")
+	if fp.Kind() != reflect.Struct {
+		return false, errors.Errorf(errFmtNoForProvider, o)
+	}
+	fields := GetLateInitializedFields(o)
+	for _, cname := range fields {
+		if err := resetField(fp, strings.Split(cname, ".")); err != nil {
+			return false, errors.Wrapf(err, errFmtResetField, cname)
+		}
+	}
+	recordFields(o, AnnotationKeyClearedLateInitializedFields, fields...)
+	xpmeta.RemoveAnnotations(o, AnnotationKeyLateInitializedFields, AnnotationKeyResetLateInitializedFields)
+	return true, nil
+}
+
+// resetField sets the field at the specified path of canonical names in
+// the specified struct to its zero value. The late-initialized fields are
+// recorded only through pointers to structs and a nil pointer on the path
+// implies that the field has already been reset.
+func resetField(v reflect.Value, path []string) error {
+	f := v.FieldByName(path[0])
+	switch {
+	case !f.IsValid():
+		return errors.Errorf("no field named %q", path[0])
+	case len(path) == 1:
+		f.Set(reflect.Zero(f.Type()))
+		return nil
+	case f.Kind() != reflect.Ptr || f.Type().Elem().Kind() != reflect.Struct:
+		return errors.Errorf("field %q is not a pointer to a struct", path[0])
+	case f.IsNil():
+		return nil
+	default:
+		return resetField(f.Elem(), path[1:])
+	}
+}
+
+func contains(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
 // NameFilter defines a late-initialization filter on CR field canonical names.
 // Fields with matching cnames will not be processed during late-initialization
 type NameFilter func(string) bool
@@ -202,6 +414,7 @@ func (li *GenericLateInitializer) LateInitialize(desiredObject, observedObject a
 			err = errors.Errorf(errFmtPanic, r, debug.Stack())
 		}
 	}()
+	li.assigned = nil
 	changed, err = li.handleStruct("", desiredObject, observedObject)
 	return
 }
@@ -235,10 +448,13 @@ func (li *GenericLateInitializer) handleStruct(parentName string, desiredObject
 		desiredKeepField := false
 		var err error
 
-		if !desiredFieldValue.IsZero() {
+		if _, ok := li.cleared[cName]; ok {
+			continue
+		}
+		policy := li.policies[cName]
+		if _, ok := li.initialized[cName]; ok && policy == config.LateInitOnce {
 			continue
 		}
-
 		for _, f := range li.valueFilters {
 			if f(cName, observedStructField, observedFieldValue) {
 				// corresponding field value is filtered
@@ -249,6 +465,17 @@ func (li *GenericLateInitializer) handleStruct(parentName string, desiredObject
 		if filtered {
 			continue
 		}
+		// the fields with the Always policy follow their observed values,
+		// a desired value is kept if there is no observed value.
+		reset := false
+		if policy == config.LateInitAlways && observedFieldValue.IsValid() && !observedFieldValue.IsZero() &&
+			!reflect.DeepEqual(desiredFieldValue.Interface(), observedFieldValue.Interface()) {
+			desiredFieldValue.Set(reflect.Zero(desiredFieldValue.Type()))
+			reset = true
+		}
+		if !desiredFieldValue.IsZero() {
+			continue
+		}
 
 		switch desiredStructField.Type.Kind() { //nolint:exhaustive
 		// handle pointer struct field
@@ -265,8 +492,13 @@ func (li *GenericLateInitializer) handleStruct(parentName string, desiredObject
 		if err != nil {
 			return false, err
 		}
+		// the fields of the nested structs are recorded separately
+		if desiredKeepField && li.collectionDepth == 0 &&
+			!(desiredStructField.Type.Kind() == reflect.Ptr && observedFieldValue.Elem().Kind() == reflect.Struct) {
+			li.assigned = append(li.assigned, cName)
+		}
 
-		fieldAssigned = fieldAssigned || desiredKeepField
+		fieldAssigned = fieldAssigned || desiredKeepField || reset
 	}
 
 	return fieldAssigned, nil
@@ -308,6 +540,10 @@ func (li *GenericLateInitializer) handleSlice(cName string, desiredFieldValue, o
 	if observedFieldValue.IsNil() || !desiredFieldValue.IsNil() {
 		return false, nil
 	}
+	li.collectionDepth++
+	defer func() {
+		li.collectionDepth--
+	}()
 	// initialize with an empty slice
 	v := desiredFieldValue.Interface()
 	desiredFieldValue.Set(reflect.MakeSlice(reflect.ValueOf(&v).Elem().Elem().Type(), 0, observedFieldValue.Len()))
@@ -351,6 +587,10 @@ func (li *GenericLateInitializer) handleMap(cName string, desiredFieldValue, obs
 	if observedFieldValue.IsNil() || !desiredFieldValue.IsNil() {
 		return false, nil
 	}
+	li.collectionDepth++
+	defer func() {
+		li.collectionDepth--
+	}()
 	// initialize with an empty map
 	v := desiredFieldValue.Interface()
 	desiredFieldValue.Set(reflect.MakeMap(reflect.ValueOf(&v).Elem().Elem().Type()))
@@ -8,6 +8,9 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/upjet/pkg/config"
 )
 
 func TestLateInitialize(t *testing.T) {
@@ -538,3 +541,217 @@ func TestLateInitialize(t *testing.T) {
 		})
 	}
 }
+
+func TestLateInitializePolicies(t *testing.T) {
+	type nested struct {
+		F1 *string
+		F2 []*string
+	}
+	type params struct {
+		F1 *string
+		F2 *string
+		F3 *nested
+	}
+	desired, observed, user := "desired", "observed", "user"
+	type args struct {
+		desiredObject  *params
+		observedObject *params
+		opts           []GenericLateInitializerOption
+	}
+	type want struct {
+		modified bool
+		object   *params
+		fields   []string
+	}
+	tests := map[string]struct {
+		args args
+		want want
+	}{
+		"NoPolicy": {
+			args: args{
+				desiredObject: &params{F1: &desired},
+				observedObject: &params{
+					F1: &observed,
+					F2: &observed,
+					F3: &nested{F1: &observed, F2: []*string{&observed}},
+				},
+			},
+			want: want{
+				modified: true,
+				object: &params{
+					F1: &desired,
+					F2: &observed,
+					F3: &nested{F1: &observed, F2: []*string{&observed}},
+				},
+				fields: []string{"F2", "F3.F1", "F3.F2"},
+			},
+		},
+		"Once": {
+			args: args{
+				desiredObject:  &params{},
+				observedObject: &params{F1: &observed, F2: &observed},
+				opts: []GenericLateInitializerOption{
+					WithPolicy("F1", config.LateInitOnce),
+					WithPolicy("F2", config.LateInitOnce),
+					WithLateInitializedFields("F1"),
+				},
+			},
+			want: want{
+				modified: true,
+				object:   &params{F2: &observed},
+				fields:   []string{"F2"},
+			},
+		},
+		"Always": {
+			args: args{
+				desiredObject:  &params{F1: &user, F2: &user},
+				observedObject: &params{F1: &observed, F2: &observed},
+				opts: []GenericLateInitializerOption{
+					WithPolicy("F1", config.LateInitAlways),
+				},
+			},
+			want: want{
+				modified: true,
+				object:   &params{F1: &observed, F2: &user},
+				fields:   []string{"F1"},
+			},
+		},
+		"AlwaysWithoutObservedValue": {
+			args: args{
+				desiredObject:  &params{F1: &user},
+				observedObject: &params{},
+				opts: []GenericLateInitializerOption{
+					WithPolicy("F1", config.LateInitAlways),
+				},
+			},
+			want: want{
+				object: &params{F1: &user},
+			},
+		},
+		"Cleared": {
+			args: args{
+				desiredObject:  &params{},
+				observedObject: &params{F1: &observed, F2: &observed},
+				opts: []GenericLateInitializerOption{
+					WithPolicy("F1", config.LateInitAlways),
+					WithClearedFields("F1", "F2"),
+				},
+			},
+			want: want{
+				object: &params{},
+			},
+		},
+		"Never": {
+			args: args{
+				desiredObject:  &params{},
+				observedObject: &params{F1: &observed},
+				opts: []GenericLateInitializerOption{
+					WithPolicy("F1", config.LateInitNever),
+				},
+			},
+			want: want{
+				object: &params{},
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			li := NewGenericLateInitializer(tc.args.opts...)
+			got, err := li.LateInitialize(tc.args.desiredObject, tc.args.observedObject)
+			if err != nil {
+				t.Fatalf("\n%s\nLateInitialize(...): unexpected error: %v", name, err)
+			}
+			if got != tc.want.modified {
+				t.Errorf("\n%s\nLateInitialize(...): want modified %v, got %v", name, tc.want.modified, got)
+			}
+			if diff := cmp.Diff(tc.want.object, tc.args.desiredObject); diff != "" {
+				t.Errorf("\n%s\nLateInitialize(...): -want object, +got object:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.fields, li.LateInitializedFields()); diff != "" {
+				t.Errorf("\n%s\nLateInitializedFields(): -want, +got:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestRecordLateInitializedFields(t *testing.T) {
+	o := &metav1.ObjectMeta{Annotations: map[string]string{AnnotationKeyLateInitializedFields: "F2"}}
+	RecordLateInitializedFields(o, "F3.F1", "F1", "F2")
+	want := []string{"F1", "F2", "F3.F1"}
+	if diff := cmp.Diff(want, GetLateInitializedFields(o)); diff != "" {
+		t.Errorf("GetLateInitializedFields(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestResetLateInitializedFields(t *testing.T) {
+	type nested struct {
+		F1 *string
+	}
+	type params struct {
+		F1 *string
+		F2 *string
+		F3 *nested
+	}
+	type spec struct {
+		ForProvider params
+	}
+	type object struct {
+		metav1.ObjectMeta
+		Spec spec
+	}
+	v := "value"
+	type want struct {
+		reset bool
+		err   error
+		obj   *object
+	}
+	tests := map[string]struct {
+		obj  *object
+		want want
+	}{
+		"NotRequested": {
+			obj: &object{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationKeyLateInitializedFields: "F1"}},
+				Spec:       spec{ForProvider: params{F1: &v}},
+			},
+			want: want{
+				obj: &object{
+					ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationKeyLateInitializedFields: "F1"}},
+					Spec:       spec{ForProvider: params{F1: &v}},
+				},
+			},
+		},
+		"Reset": {
+			obj: &object{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					AnnotationKeyLateInitializedFields:      "F1,F3.F1",
+					AnnotationKeyResetLateInitializedFields: "true",
+				}},
+				Spec: spec{ForProvider: params{F1: &v, F2: &v, F3: &nested{F1: &v}}},
+			},
+			want: want{
+				reset: true,
+				obj: &object{
+					ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+						AnnotationKeyClearedLateInitializedFields: "F1,F3.F1",
+					}},
+					Spec: spec{ForProvider: params{F2: &v, F3: &nested{}}},
+				},
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ResetLateInitializedFields(tc.obj)
+			if diff := cmp.Diff(tc.want.err, err); diff != "" {
+				t.Errorf("\n%s\nResetLateInitializedFields(...): -want error, +got error:\n%s", name, diff)
+			}
+			if got != tc.want.reset {
+				t.Errorf("\n%s\nResetLateInitializedFields(...): want reset %v, got %v", name, tc.want.reset, got)
+			}
+			if diff := cmp.Diff(tc.want.obj, tc.obj); diff != "" {
+				t.Errorf("\n%s\nResetLateInitializedFields(...): -want object, +got object:\n%s", name, diff)
+			}
+		})
+	}
+}
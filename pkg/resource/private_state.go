@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	xpmeta "github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SecretKeyPrivateState is the key of the Terraform private state in
+	// the Secrets of the SecretPrivateStateStore.
+	SecretKeyPrivateState = "privateState"
+
+	fmtPrivateStateSecretName = "%s-private-state"
+
+	errGetPrivateStateSecret    = "cannot get the private state Secret"
+	errCreatePrivateStateSecret = "cannot create the private state Secret"
+	errUpdatePrivateStateSecret = "cannot update the private state Secret"
+)
+
+// PrivateStateStore stores the Terraform private state of the managed
+// resources, i.e., the private attribute of the Terraform state, which is
+// used by the providers to store arbitrary metadata, usually details about
+// the schema version. The private states are stored either in
+// the annotations of the managed resources with the
+// AnnotationPrivateStateStore, or in per-resource Secrets with
+// the SecretPrivateStateStore. There is no store for a status field, as
+// the generated status types have no field for the private state.
+type PrivateStateStore interface {
+	// Get returns the private state of the specified managed resource.
+	Get(ctx context.Context, tr metav1.Object) (string, error)
+	// Set stores the private state of the specified managed resource and
+	// reports whether the managed resource has been modified, in which case
+	// the caller is responsible for updating it.
+	Set(ctx context.Context, tr metav1.Object, privateState string) (bool, error)
+}
+
+// AnnotationPrivateStateStore stores the private states in the
+// upjet.crossplane.io/provider-meta annotations of the managed resources.
+type AnnotationPrivateStateStore struct{}
+
+// NewAnnotationPrivateStateStore returns a new AnnotationPrivateStateStore.
+func NewAnnotationPrivateStateStore() AnnotationPrivateStateStore {
+	return AnnotationPrivateStateStore{}
+}
+
+// Get returns the private state in the annotations of the managed resource.
+func (AnnotationPrivateStateStore) Get(_ context.Context, tr metav1.Object) (string, error) {
+	return tr.GetAnnotations()[AnnotationKeyPrivateRawAttribute], nil
+}
+
+// Set sets the private state annotation of the managed resource.
+func (AnnotationPrivateStateStore) Set(_ context.Context, tr metav1.Object, privateState string) (bool, error) {
+	if v, ok := tr.GetAnnotations()[AnnotationKeyPrivateRawAttribute]; ok && v == privateState {
+		return false, nil
+	}
+	xpmeta.AddAnnotations(tr, map[string]string{
+		AnnotationKeyPrivateRawAttribute: privateState,
+	})
+	return true, nil
+}
+
+// SecretPrivateStateStore stores the private states in per-resource Secrets
+// in a namespace, keeping them out of the managed resource objects. The
+// private states of the existing managed resources are migrated from their
+// annotations the first time they are stored.
+type SecretPrivateStateStore struct {
+	kube      client.Client
+	namespace string
+}
+
+// NewSecretPrivateStateStore returns a new SecretPrivateStateStore that
+// stores the private states in the specified namespace.
+func NewSecretPrivateStateStore(kube client.Client, namespace string) *SecretPrivateStateStore {
+	return &SecretPrivateStateStore{
+		kube:      kube,
+		namespace: namespace,
+	}
+}
+
+func (s *SecretPrivateStateStore) key(tr metav1.Object) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: s.namespace,
+		Name:      fmt.Sprintf(fmtPrivateStateSecretName, tr.GetUID()),
+	}
+}
+
+// groupVersionKindFor returns the GroupVersionKind of the specified managed
+// resource. The TypeMeta of the typed objects fetched from the API server is
+// usually empty, in which case the GroupVersionKind is resolved from
+// the scheme of the client. Returns an empty GroupVersionKind if it cannot
+// be resolved.
+func (s *SecretPrivateStateStore) groupVersionKindFor(tr metav1.Object) schema.GroupVersionKind {
+	o, ok := tr.(runtime.Object)
+	if !ok {
+		return schema.GroupVersionKind{}
+	}
+	if gvk := o.GetObjectKind().GroupVersionKind(); gvk.Kind != "" {
+		return gvk
+	}
+	gvk, err := s.kube.GroupVersionKindFor(o)
+	if err != nil {
+		return schema.GroupVersionKind{}
+	}
+	return gvk
+}
+
+// Get returns the private state in the Secret of the managed resource or,
+// if the Secret does not exist yet, in its annotations.
+func (s *SecretPrivateStateStore) Get(ctx context.Context, tr metav1.Object) (string, error) {
+	sec := &corev1.Secret{}
+	err := s.kube.Get(ctx, s.key(tr), sec)
+	if kerrors.IsNotFound(err) {
+		return tr.GetAnnotations()[AnnotationKeyPrivateRawAttribute], nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, errGetPrivateStateSecret)
+	}
+	return string(sec.Data[SecretKeyPrivateState]), nil
+}
+
+// Set stores the private state in the Secret of the managed resource and
+// removes the private state annotation from the managed resource, if any.
+func (s *SecretPrivateStateStore) Set(ctx context.Context, tr metav1.Object, privateState string) (bool, error) {
+	sec := &corev1.Secret{}
+	err := s.kube.Get(ctx, s.key(tr), sec)
+	switch {
+	case kerrors.IsNotFound(err):
+		sec = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.namespace,
+				Name:      s.key(tr).Name,
+			},
+			Data: map[string][]byte{
+				SecretKeyPrivateState: []byte(privateState),
+			},
+		}
+		// the Secret is garbage collected together with the managed
+		// resource, if its kind can be resolved
+		if gvk := s.groupVersionKindFor(tr); !gvk.Empty() {
+			xpmeta.AddOwnerReference(sec, xpmeta.AsOwner(xpmeta.TypedReferenceTo(tr, gvk)))
+		}
+		if err := s.kube.Create(ctx, sec); err != nil {
+			return false, errors.Wrap(err, errCreatePrivateStateSecret)
+		}
+	case err != nil:
+		return false, errors.Wrap(err, errGetPrivateStateSecret)
+	case string(sec.Data[SecretKeyPrivateState]) != privateState:
+		if sec.Data == nil {
+			sec.Data = map[string][]byte{}
+		}
+		sec.Data[SecretKeyPrivateState] = []byte(privateState)
+		if err := s.kube.Update(ctx, sec); err != nil {
+			return false, errors.Wrap(err, errUpdatePrivateStateSecret)
+		}
+	}
+	if _, ok := tr.GetAnnotations()[AnnotationKeyPrivateRawAttribute]; !ok {
+		return false, nil
+	}
+	xpmeta.RemoveAnnotations(tr, AnnotationKeyPrivateRawAttribute)
+	return true, nil
+}
@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"context"
+	"testing"
+
+	xpmeta "github.com/crossplane/crossplane-runtime/pkg/meta"
+	xpfake "github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func privateStateSecretGetFn(data string) test.MockGetFn {
+	return func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+		obj.(*corev1.Secret).Data = map[string][]byte{
+			SecretKeyPrivateState: []byte(data),
+		}
+		return nil
+	}
+}
+
+func managedWithPrivateState(privateState *string) *xpfake.Managed {
+	mg := &xpfake.Managed{}
+	mg.SetUID("uid")
+	if privateState != nil {
+		xpmeta.AddAnnotations(mg, map[string]string{AnnotationKeyPrivateRawAttribute: *privateState})
+	}
+	return mg
+}
+
+func TestSecretPrivateStateStoreGet(t *testing.T) {
+	legacy := "legacy"
+	type args struct {
+		kube client.Client
+		mg   metav1.Object
+	}
+	type want struct {
+		privateState string
+		err          error
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"FromSecret": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: privateStateSecretGetFn("stored"),
+				},
+				mg: managedWithPrivateState(&legacy),
+			},
+			want: want{
+				privateState: "stored",
+			},
+		},
+		"FromAnnotation": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "uid-private-state")),
+				},
+				mg: managedWithPrivateState(&legacy),
+			},
+			want: want{
+				privateState: legacy,
+			},
+		},
+		"GetError": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(errBoom),
+				},
+				mg: managedWithPrivateState(nil),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errGetPrivateStateSecret),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewSecretPrivateStateStore(tc.args.kube, "ns").Get(context.TODO(), tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want error, +got error:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.privateState, got); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want privateState, +got privateState:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestSecretPrivateStateStoreSet(t *testing.T) {
+	legacy := "legacy"
+	type args struct {
+		kube         *test.MockClient
+		mg           metav1.Object
+		privateState string
+	}
+	type want struct {
+		updated    bool
+		annotation bool
+		err        error
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"CreateAndMigrate": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "uid-private-state")),
+					MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+						s := obj.(*corev1.Secret)
+						if s.GetNamespace() != "ns" || s.GetName() != "uid-private-state" || string(s.Data[SecretKeyPrivateState]) != "new" {
+							return errors.Errorf("unexpected Secret: %v", s)
+						}
+						// the owner's kind is resolved from the scheme as
+						// the TypeMeta of the managed resource is empty
+						if len(s.OwnerReferences) != 1 || s.OwnerReferences[0].Kind != "Managed" || s.OwnerReferences[0].UID != "uid" {
+							return errors.Errorf("unexpected owner references: %v", s.OwnerReferences)
+						}
+						return nil
+					},
+					MockGroupVersionKindFor: test.NewMockGroupVersionKindForFn(nil, schema.GroupVersionKind{Group: "example.upbound.io", Version: "v1", Kind: "Managed"}),
+				},
+				mg:           managedWithPrivateState(&legacy),
+				privateState: "new",
+			},
+			want: want{
+				updated: true,
+			},
+		},
+		"Unchanged": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: privateStateSecretGetFn("stored"),
+				},
+				mg:           managedWithPrivateState(nil),
+				privateState: "stored",
+			},
+		},
+		"Update": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: privateStateSecretGetFn("stored"),
+					MockUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+						if string(obj.(*corev1.Secret).Data[SecretKeyPrivateState]) != "new" {
+							return errors.New("unexpected private state")
+						}
+						return nil
+					},
+				},
+				mg:           managedWithPrivateState(nil),
+				privateState: "new",
+			},
+		},
+		"UpdateError": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet:    privateStateSecretGetFn("stored"),
+					MockUpdate: test.NewMockUpdateFn(errBoom),
+				},
+				mg:           managedWithPrivateState(&legacy),
+				privateState: "new",
+			},
+			want: want{
+				annotation: true,
+				err:        errors.Wrap(errBoom, errUpdatePrivateStateSecret),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			updated, err := NewSecretPrivateStateStore(tc.args.kube, "ns").Set(context.TODO(), tc.args.mg, tc.args.privateState)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSet(...): -want error, +got error:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.updated, updated); diff != "" {
+				t.Errorf("\n%s\nSet(...): -want updated, +got updated:\n%s", name, diff)
+			}
+			_, ok := tc.args.mg.GetAnnotations()[AnnotationKeyPrivateRawAttribute]
+			if diff := cmp.Diff(tc.want.annotation, ok); diff != "" {
+				t.Errorf("\n%s\nSet(...): -want annotation, +got annotation:\n%s", name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+const (
+	schemeSeparator = "://"
+
+	// SchemeFile is the scheme of the secret references resolved from
+	// the filesystem, e.g., from the volumes of a CSI secret store driver.
+	SchemeFile = "file"
+
+	errFmtUnknownScheme   = "no secret client is configured for the scheme %q"
+	errFmtOutsideRoot     = "secret path %q is outside of the root directory %q"
+	errFmtReadSecretDir   = "cannot read the secret directory %q"
+	errFmtReadSecretFile  = "cannot read the secret file %q"
+	errFmtInvalidSecretID = "invalid secret key %q"
+)
+
+// SecretClientChain is a SecretClient that resolves the secret references
+// with the SecretClients configured for their schemes. The scheme of
+// a secret reference is the prefix of its name that is followed by ://,
+// e.g., the scheme of file:///mnt/secrets/db is file, and the name passed to
+// the scheme's SecretClient is /mnt/secrets/db. The secret references
+// without a scheme are resolved with the default SecretClient, which
+// usually reads the Kubernetes Secrets.
+type SecretClientChain struct {
+	defaultClient SecretClient
+	clients       map[string]SecretClient
+}
+
+// SecretClientChainOption configures a SecretClientChain.
+type SecretClientChainOption func(c *SecretClientChain)
+
+// WithSchemeSecretClient configures the SecretClient that resolves
+// the secret references with the specified scheme, e.g., a Vault or
+// an External Secret Store plugin client.
+func WithSchemeSecretClient(scheme string, c SecretClient) SecretClientChainOption {
+	return func(chain *SecretClientChain) {
+		chain.clients[scheme] = c
+	}
+}
+
+// NewSecretClientChain returns a new SecretClientChain with the specified
+// default SecretClient.
+func NewSecretClientChain(defaultClient SecretClient, opts ...SecretClientChainOption) *SecretClientChain {
+	c := &SecretClientChain{
+		defaultClient: defaultClient,
+		clients:       map[string]SecretClient{},
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// clientFor returns the SecretClient for the specified secret reference
+// together with the reference to be passed to it.
+func (c *SecretClientChain) clientFor(ref v1.SecretReference) (SecretClient, v1.SecretReference, error) {
+	scheme, name, ok := strings.Cut(ref.Name, schemeSeparator)
+	if !ok {
+		return c.defaultClient, ref, nil
+	}
+	sc, ok := c.clients[scheme]
+	if !ok {
+		return nil, ref, errors.Errorf(errFmtUnknownScheme, scheme)
+	}
+	ref.Name = name
+	return sc, ref, nil
+}
+
+// GetSecretData gets and returns the data of the referenced secret.
+func (c *SecretClientChain) GetSecretData(ctx context.Context, ref *v1.SecretReference) (map[string][]byte, error) {
+	sc, r, err := c.clientFor(*ref)
+	if err != nil {
+		return nil, err
+	}
+	return sc.GetSecretData(ctx, &r)
+}
+
+// GetSecretValue gets and returns the value for the key of the referenced
+// secret.
+func (c *SecretClientChain) GetSecretValue(ctx context.Context, sel v1.SecretKeySelector) ([]byte, error) {
+	sc, r, err := c.clientFor(sel.SecretReference)
+	if err != nil {
+		return nil, err
+	}
+	sel.SecretReference = r
+	return sc.GetSecretValue(ctx, sel)
+}
+
+// FileSecretClient is a SecretClient that reads the secrets from
+// a directory tree, such as the volumes mounted by a CSI secret store driver.
+// The name of a secret reference is the path of a directory under the root
+// directory and the keys of the secret are the names of the files in it.
+type FileSecretClient struct {
+	fs   afero.Afero
+	root string
+}
+
+// FileSecretClientOption configures a FileSecretClient.
+type FileSecretClientOption func(c *FileSecretClient)
+
+// WithSecretFileSystem configures the filesystem the FileSecretClient reads
+// the secrets from. Used mostly for testing.
+func WithSecretFileSystem(fs afero.Fs) FileSecretClientOption {
+	return func(c *FileSecretClient) {
+		c.fs = afero.Afero{Fs: fs}
+	}
+}
+
+// NewFileSecretClient returns a new FileSecretClient that only reads
+// the secrets under the specified root directory.
+func NewFileSecretClient(root string, opts ...FileSecretClientOption) *FileSecretClient {
+	c := &FileSecretClient{
+		fs:   afero.Afero{Fs: afero.NewOsFs()},
+		root: filepath.Clean(root),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *FileSecretClient) dir(ref v1.SecretReference) (string, error) {
+	dir := filepath.Clean(ref.Name)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(c.root, dir)
+	}
+	if dir != c.root && !strings.HasPrefix(dir, c.root+string(filepath.Separator)) {
+		return "", errors.Errorf(errFmtOutsideRoot, ref.Name, c.root)
+	}
+	return dir, nil
+}
+
+// GetSecretData returns the contents of the files in the referenced
+// directory keyed by their names.
+func (c *FileSecretClient) GetSecretData(_ context.Context, ref *v1.SecretReference) (map[string][]byte, error) {
+	dir, err := c.dir(*ref)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := c.fs.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtReadSecretDir, dir)
+	}
+	data := make(map[string][]byte, len(infos))
+	for _, info := range infos {
+		// skip the subdirectories and the hidden files, such as the ones
+		// created by the atomic writers of the Kubernetes volumes.
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		p := filepath.Join(dir, info.Name())
+		v, err := c.fs.ReadFile(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtReadSecretFile, p)
+		}
+		data[info.Name()] = v
+	}
+	return data, nil
+}
+
+// GetSecretValue returns the contents of the file with the selected key
+// in the referenced directory.
+func (c *FileSecretClient) GetSecretValue(_ context.Context, sel v1.SecretKeySelector) ([]byte, error) {
+	dir, err := c.dir(sel.SecretReference)
+	if err != nil {
+		return nil, err
+	}
+	if sel.Key == "" || strings.ContainsRune(sel.Key, filepath.Separator) || sel.Key == ".." {
+		return nil, errors.Errorf(errFmtInvalidSecretID, sel.Key)
+	}
+	p := filepath.Join(dir, sel.Key)
+	v, err := c.fs.ReadFile(p)
+	return v, errors.Wrapf(err, errFmtReadSecretFile, p)
+}
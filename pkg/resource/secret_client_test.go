@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+type fakeSecretClient struct {
+	data map[string]map[string][]byte
+}
+
+func (f *fakeSecretClient) GetSecretData(_ context.Context, ref *v1.SecretReference) (map[string][]byte, error) {
+	return f.data[ref.Name], nil
+}
+
+func (f *fakeSecretClient) GetSecretValue(_ context.Context, sel v1.SecretKeySelector) ([]byte, error) {
+	return f.data[sel.Name][sel.Key], nil
+}
+
+func TestSecretClientChainGetSecretValue(t *testing.T) {
+	chain := NewSecretClientChain(&fakeSecretClient{
+		data: map[string]map[string][]byte{"db": {"password": []byte("kube")}},
+	}, WithSchemeSecretClient("vault", &fakeSecretClient{
+		data: map[string]map[string][]byte{"secret/db": {"password": []byte("vault")}},
+	}))
+	type want struct {
+		value []byte
+		err   error
+	}
+	cases := map[string]struct {
+		sel  v1.SecretKeySelector
+		want want
+	}{
+		"NoScheme": {
+			sel: v1.SecretKeySelector{SecretReference: v1.SecretReference{Name: "db"}, Key: "password"},
+			want: want{
+				value: []byte("kube"),
+			},
+		},
+		"Scheme": {
+			sel: v1.SecretKeySelector{SecretReference: v1.SecretReference{Name: "vault://secret/db"}, Key: "password"},
+			want: want{
+				value: []byte("vault"),
+			},
+		},
+		"UnknownScheme": {
+			sel: v1.SecretKeySelector{SecretReference: v1.SecretReference{Name: "ess://db"}, Key: "password"},
+			want: want{
+				err: errors.Errorf(errFmtUnknownScheme, "ess"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := chain.GetSecretValue(context.TODO(), tc.sel)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetSecretValue(...): -want error, +got error:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.value, got); diff != "" {
+				t.Errorf("\n%s\nGetSecretValue(...): -want value, +got value:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestFileSecretClient(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "/mnt/secrets/db/password", []byte("secret"), 0600)
+	_ = afero.WriteFile(fs, "/mnt/secrets/db/user", []byte("admin"), 0600)
+	_ = afero.WriteFile(fs, "/mnt/secrets/db/..data", []byte("ignored"), 0600)
+	_ = afero.WriteFile(fs, "/etc/passwd", []byte("root"), 0600)
+	c := NewFileSecretClient("/mnt/secrets", WithSecretFileSystem(fs))
+
+	type want struct {
+		data map[string][]byte
+		err  error
+	}
+	cases := map[string]struct {
+		ref  v1.SecretReference
+		want want
+	}{
+		"AbsolutePath": {
+			ref: v1.SecretReference{Name: "/mnt/secrets/db"},
+			want: want{
+				data: map[string][]byte{"password": []byte("secret"), "user": []byte("admin")},
+			},
+		},
+		"RelativePath": {
+			ref: v1.SecretReference{Name: "db"},
+			want: want{
+				data: map[string][]byte{"password": []byte("secret"), "user": []byte("admin")},
+			},
+		},
+		"OutsideRoot": {
+			ref: v1.SecretReference{Name: "../../etc"},
+			want: want{
+				err: errors.Errorf(errFmtOutsideRoot, "../../etc", "/mnt/secrets"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := c.GetSecretData(context.TODO(), &tc.ref)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetSecretData(...): -want error, +got error:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, got); diff != "" {
+				t.Errorf("\n%s\nGetSecretData(...): -want data, +got data:\n%s", name, diff)
+			}
+		})
+	}
+}
@@ -42,6 +42,7 @@ const (
 
 	errGetAdditionalConnectionDetails = "cannot get additional connection details"
 	errFmtCannotOverrideExistingKey   = "overriding a reserved connection key (%q) is not allowed"
+	errFmtConflictingTemplateKey      = "the connection details template key %q conflicts with an additional connection details key"
 )
 
 var reEndsWithIndex *regexp.Regexp
@@ -74,6 +75,20 @@ func GetConnectionDetails(attr map[string]any, tr Terraformed, cfg *config.Resou
 	if err != nil {
 		return nil, errors.Wrap(err, errGetAdditionalConnectionDetails)
 	}
+	tmpl, err := renderConnectionDetailsTemplates(cfg, attr, tr)
+	if err != nil {
+		return nil, err
+	}
+	if add == nil {
+		add = tmpl
+	} else {
+		for k, v := range tmpl {
+			if _, ok := add[k]; ok {
+				return nil, errors.Errorf(errFmtConflictingTemplateKey, k)
+			}
+			add[k] = v
+		}
+	}
 	for k, v := range add {
 		if _, ok := conn[k]; ok {
 			// We return error if a custom key tries to override an existing
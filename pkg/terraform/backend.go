@@ -0,0 +1,282 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/pkg/errors"
+	k8sExec "k8s.io/utils/exec"
+
+	"github.com/crossplane/upjet/pkg/metrics"
+	"github.com/crossplane/upjet/pkg/resource/json"
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
+)
+
+// msgNonExistentImport is the error message of terraform import for
+// the external resources that do not exist.
+const msgNonExistentImport = "Cannot import non-existent remote object"
+
+// Invocation holds the context of a single Backend operation run for
+// a Workspace.
+type Invocation struct {
+	// Dir is the directory of the Workspace, which holds its configuration
+	// and state files.
+	Dir string
+	// Env holds the additional environment variables of the operation, such
+	// as the reattach configuration of a shared provider.
+	Env []string
+	// Mode is the execution mode of the operation.
+	Mode ExecMode
+	// Logger is the logger of the Workspace.
+	Logger logging.Logger
+	// FilterFn removes the sensitive information from the Terraform output
+	// before it's logged or reported in an error.
+	FilterFn func(string) string
+}
+
+func (inv Invocation) filter(s string) string {
+	if inv.FilterFn == nil {
+		return s
+	}
+	return inv.FilterFn(s)
+}
+
+func (inv Invocation) logger() logging.Logger {
+	if inv.Logger == nil {
+		return logging.NewNopLogger()
+	}
+	return inv.Logger
+}
+
+// Backend runs the Terraform operations of the Workspaces. The operations
+// read the configuration of a Workspace from, and write its state into,
+// the Workspace's directory. The failures of the operations are reported
+// with the typed errors of the terraform/errors package. CLIBackend, which
+// is the default, runs the CLI directly and TFExecBackend runs it with
+// hashicorp/terraform-exec. The fake Backend in the terraform/fake package
+// can be used for testing the controllers without the CLI. A Backend is
+// configured with WithWorkspaceBackend, or with WithBackend for all
+// the Workspaces of a WorkspaceStore.
+type Backend interface {
+	// Init initializes the Workspace, upgrading its dependencies if upgrade
+	// is true.
+	Init(ctx context.Context, inv Invocation, upgrade bool) error
+	// Apply applies the configuration of the Workspace.
+	Apply(ctx context.Context, inv Invocation) error
+	// Destroy destroys the resources of the Workspace.
+	Destroy(ctx context.Context, inv Invocation) error
+	// Refresh updates the state of the Workspace with the current state of
+	// its resources without making any changes to them.
+	Refresh(ctx context.Context, inv Invocation) error
+	// Plan compares the configuration of the Workspace with its state
+	// without refreshing the state.
+	Plan(ctx context.Context, inv Invocation) (PlanResult, error)
+	// Import imports the external resource with the specified ID into
+	// the state of the Workspace at the specified address. Returns false
+	// if the external resource does not exist.
+	Import(ctx context.Context, inv Invocation, address, id string) (bool, error)
+	// StatePull returns the state of the Workspace stored in its Terraform
+	// backend. Returns an empty state if no state has been stored yet.
+	StatePull(ctx context.Context, inv Invocation) ([]byte, error)
+	// StatePush stores the state file at the specified path in
+	// the Terraform backend of the Workspace.
+	StatePush(ctx context.Context, inv Invocation, path string) error
+	// StateRemove removes the resource at the specified address from
+	// the state of the Workspace.
+	StateRemove(ctx context.Context, inv Invocation, address string) error
+}
+
+// CLIBackendOption lets you configure a CLIBackend.
+type CLIBackendOption func(b *CLIBackend)
+
+// WithCLIBackendExecutor sets the executor the CLIBackend runs the CLI with.
+func WithCLIBackendExecutor(e k8sExec.Interface) CLIBackendOption {
+	return func(b *CLIBackend) {
+		b.executor = e
+	}
+}
+
+// WithCLIBackendCLI sets the CLI, e.g., Terraform or OpenTofu, the CLIBackend
+// runs.
+func WithCLIBackendCLI(c CLI) CLIBackendOption {
+	return func(b *CLIBackend) {
+		b.cli = c
+	}
+}
+
+// CLIBackend is a Backend that runs the operations by executing the Terraform
+// CLI and parsing its JSON output.
+type CLIBackend struct {
+	executor k8sExec.Interface
+	cli      CLI
+}
+
+// NewCLIBackend returns a new CLIBackend.
+func NewCLIBackend(opts ...CLIBackendOption) *CLIBackend {
+	b := &CLIBackend{
+		executor: k8sExec.New(),
+		cli:      NewTerraformCLI(),
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// Init runs terraform init.
+func (b *CLIBackend) Init(ctx context.Context, inv Invocation, upgrade bool) error {
+	args := []string{"init", "-input=false"}
+	if upgrade {
+		args = []string{"init", "-upgrade", "-input=false"}
+	}
+	out, err := b.run(ctx, inv, args...)
+	inv.logger().Debug("init ended", "upgrade", upgrade, "out", inv.filter(string(out)))
+	return errors.Wrap(err, inv.filter(string(out)))
+}
+
+// Apply runs terraform apply.
+func (b *CLIBackend) Apply(ctx context.Context, inv Invocation) error {
+	out, err := b.run(ctx, inv, "apply", "-auto-approve", "-input=false", "-lock=false", "-json")
+	inv.logger().Debug("apply ended", "out", inv.filter(string(out)))
+	if err != nil {
+		return tferrors.NewApplyFailed(out)
+	}
+	return nil
+}
+
+// Destroy runs terraform destroy.
+func (b *CLIBackend) Destroy(ctx context.Context, inv Invocation) error {
+	out, err := b.run(ctx, inv, "destroy", "-auto-approve", "-input=false", "-lock=false", "-json")
+	inv.logger().Debug("destroy ended", "out", inv.filter(string(out)))
+	if err != nil {
+		return tferrors.NewDestroyFailed(out)
+	}
+	return nil
+}
+
+// Refresh runs terraform apply -refresh-only.
+func (b *CLIBackend) Refresh(ctx context.Context, inv Invocation) error {
+	out, err := b.run(ctx, inv, "apply", "-refresh-only", "-auto-approve", "-input=false", "-lock=false", "-json")
+	inv.logger().Debug("refresh ended", "out", inv.filter(string(out)))
+	if err != nil {
+		return tferrors.NewRefreshFailed(out)
+	}
+	return nil
+}
+
+// Plan runs terraform plan and parses its change summary.
+func (b *CLIBackend) Plan(ctx context.Context, inv Invocation) (PlanResult, error) {
+	out, err := b.run(ctx, inv, "plan", "-refresh=false", "-input=false", "-lock=false", "-json")
+	inv.logger().Debug("plan ended", "out", inv.filter(string(out)))
+	if err != nil {
+		return PlanResult{}, tferrors.NewPlanFailed(out)
+	}
+	return parsePlanSummary(out)
+}
+
+// parsePlanSummary parses the change summary in the specified JSON output of
+// terraform plan.
+func parsePlanSummary(out []byte) (PlanResult, error) {
+	line := ""
+	for _, l := range strings.Split(string(out), "\n") {
+		if strings.Contains(l, `"type":"change_summary"`) {
+			line = l
+			break
+		}
+	}
+	if line == "" {
+		return PlanResult{}, errors.Errorf("cannot find the change summary line in plan log: %s", string(out))
+	}
+	type plan struct {
+		Changes struct {
+			Add    float64 `json:"add,omitempty"`
+			Change float64 `json:"change,omitempty"`
+		} `json:"changes,omitempty"`
+	}
+	p := &plan{}
+	if err := json.JSParser.Unmarshal([]byte(line), p); err != nil {
+		return PlanResult{}, errors.Wrap(err, "cannot unmarshal change summary json")
+	}
+	return PlanResult{
+		Exists:   p.Changes.Add == 0,
+		UpToDate: p.Changes.Change == 0,
+	}, nil
+}
+
+// Import runs terraform import.
+func (b *CLIBackend) Import(ctx context.Context, inv Invocation, address, id string) (bool, error) {
+	out, err := b.run(ctx, inv, "import", "-input=false", "-lock=false", address, id)
+	inv.logger().Debug("import ended", "out", inv.filter(string(out)))
+	if err != nil {
+		// Note(turkenh): This is not a great way to check if the resource does not exist, but it is the only
+		// way we can do it for now. Terraform import does not return a proper exit code for this case or
+		// does not support -json flag to parse the returning error in a better way.
+		// https://github.com/hashicorp/terraform/blob/93f9cff99ffbb8d536b276a1be40a2c45ca4a67f/internal/terraform/node_resource_import.go#L235
+		if strings.Contains(string(out), msgNonExistentImport) {
+			return false, nil
+		}
+		return false, errors.WithMessage(errors.New("import failed"), inv.filter(string(out)))
+	}
+	return true, nil
+}
+
+// StatePull runs terraform state pull.
+func (b *CLIBackend) StatePull(ctx context.Context, inv Invocation) ([]byte, error) {
+	out, err := b.output(ctx, inv, "state", "pull")
+	return out, errors.Wrap(err, "cannot pull the state")
+}
+
+// StatePush runs terraform state push.
+func (b *CLIBackend) StatePush(ctx context.Context, inv Invocation, path string) error {
+	out, err := b.run(ctx, inv, "state", "push", "-lock=false", path)
+	inv.logger().Debug("state push ended", "out", inv.filter(string(out)))
+	return errors.Wrapf(err, "cannot push the state: %s", inv.filter(string(out)))
+}
+
+// StateRemove runs terraform state rm.
+func (b *CLIBackend) StateRemove(ctx context.Context, inv Invocation, address string) error {
+	out, err := b.run(ctx, inv, "state", "rm", "-lock=false", address)
+	inv.logger().Debug("state rm ended", "out", inv.filter(string(out)))
+	return errors.Wrapf(err, "cannot remove the resource from the state: %s", inv.filter(string(out)))
+}
+
+// output runs the CLI and returns its standard output, which is not
+// mixed with its logs.
+func (b *CLIBackend) output(ctx context.Context, inv Invocation, args ...string) ([]byte, error) {
+	return b.execute(ctx, inv, false, args...)
+}
+
+// run runs the CLI and returns its combined output.
+func (b *CLIBackend) run(ctx context.Context, inv Invocation, args ...string) ([]byte, error) {
+	return b.execute(ctx, inv, true, args...)
+}
+
+// execute runs the CLI with the specified arguments, recording the execution
+// metrics, and returns its standard output, combined with its standard error
+// if combined is true. The CLI is
+// interrupted and then killed if the specified context is done before
+// it terminates.
+func (b *CLIBackend) execute(ctx context.Context, inv Invocation, combined bool, args ...string) ([]byte, error) {
+	inv.logger().Debug("Running terraform", "binary", b.cli.binary(), "args", args)
+	// the process is killed with killCtx only if it does not terminate
+	// in time after it's interrupted when ctx is done.
+	killCtx, kill := context.WithCancel(context.Background())
+	defer kill()
+	cmd := b.executor.CommandContext(killCtx, b.cli.binary(), b.cli.args(args...)...)
+	cmd.SetEnv(append(os.Environ(), inv.Env...))
+	cmd.SetDir(inv.Dir)
+	metrics.CLIExecutions.WithLabelValues(args[0], inv.Mode.String()).Inc()
+	start := time.Now()
+	defer func() {
+		metrics.CLITime.WithLabelValues(args[0], inv.Mode.String()).Observe(time.Since(start).Seconds())
+		metrics.CLIExecutions.WithLabelValues(args[0], inv.Mode.String()).Dec()
+	}()
+	return commandOutput(ctx, cmd, kill, combined)
+}
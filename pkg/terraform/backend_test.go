@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/pkg/errors"
+	k8sExec "k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+
+	"github.com/crossplane/upjet/pkg/resource/json"
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
+)
+
+const (
+	// fakeTerraform is a fake Terraform CLI for the TFExecBackend tests.
+	// terraform-exec checks the version of the CLI before running
+	// the commands with the -json flag.
+	fakeTerraform = `#!/bin/sh
+case "$1" in
+version)
+  echo '{"terraform_version":"1.5.5","platform":"linux_amd64","provider_selections":{},"terraform_outdated":false}' ;;
+plan)
+  echo '{"@level":"info","type":"change_summary","changes":{"add":0,"change":1,"remove":0,"operation":"plan"}}' ;;
+apply)
+  echo "$TF_REATTACH_PROVIDERS" > reattach
+  echo "logs" >&2
+  echo '` + applyErrorLog + `'
+  exit 1 ;;
+import)
+  echo 'Error: Cannot import non-existent remote object' >&2
+  exit 1 ;;
+esac
+`
+	applyErrorLog = `{"@level":"error","@message":"Error: boom","diagnostic":{"severity":"error","summary":"boom","detail":"failed"},"type":"diagnostic"}`
+	reattachEnv   = `{"registry.terraform.io/hashicorp/aws":{"Protocol":"grpc","ProtocolVersion":5,"Pid":1,"Test":true,"Addr":{"Network":"unix","String":"/tmp/socket"}}}`
+)
+
+func TestCLIBackendStatePull(t *testing.T) {
+	type want struct {
+		out string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		cmd    func() k8sExec.Cmd
+		cancel bool
+		want   want
+	}{
+		"Successful": {
+			reason: "The standard output of the CLI should be returned as the state.",
+			cmd: func() k8sExec.Cmd {
+				return newScriptedCmd(func() ([]byte, []byte, error) {
+					return []byte(tfstate), []byte("logs"), nil
+				})
+			},
+			want: want{
+				out: tfstate,
+			},
+		},
+		"Interrupted": {
+			reason: "The CLI should be interrupted if the context is done before the state is pulled.",
+			cmd: func() k8sExec.Cmd {
+				return newInterruptibleCmd("interrupted")
+			},
+			cancel: true,
+			want: want{
+				out: "interrupted",
+				err: errors.Wrap(errBoom, "cannot pull the state"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.cancel {
+				cancel()
+			}
+			b := NewCLIBackend(WithCLIBackendExecutor(&testingexec.FakeExec{
+				CommandScript: []testingexec.FakeCommandAction{
+					func(_ string, _ ...string) k8sExec.Cmd {
+						return tc.cmd()
+					},
+				},
+			}))
+			out, err := b.StatePull(ctx, Invocation{Dir: directory})
+			if diff := cmp.Diff(tc.want.out, string(out)); diff != "" {
+				t.Errorf("\n%s\nStatePull(...): -want output, +got output:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nStatePull(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestTFExecBackend(t *testing.T) {
+	type want struct {
+		result any
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		op     func(ctx context.Context, b *TFExecBackend, inv Invocation) (any, error)
+		want   want
+	}{
+		"Plan": {
+			reason: "The change summary in the JSON output of terraform plan should be parsed.",
+			op: func(ctx context.Context, b *TFExecBackend, inv Invocation) (any, error) {
+				return b.Plan(ctx, inv)
+			},
+			want: want{
+				result: PlanResult{Exists: true, UpToDate: false},
+			},
+		},
+		"ApplyFailed": {
+			reason: "The diagnostics in the JSON output of terraform apply, and not the logs on the standard error, should be reported.",
+			op: func(ctx context.Context, b *TFExecBackend, inv Invocation) (any, error) {
+				return nil, b.Apply(ctx, inv)
+			},
+			want: want{
+				err: tferrors.NewApplyFailed([]byte(applyErrorLog + "\n")),
+			},
+		},
+		"ImportNonExistent": {
+			reason: "A non-existent resource should be reported without an error.",
+			op: func(ctx context.Context, b *TFExecBackend, inv Invocation) (any, error) {
+				return b.Import(ctx, inv, "aws_vpc.example", "id")
+			},
+			want: want{
+				result: false,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			cli := filepath.Join(dir, "terraform")
+			if err := os.WriteFile(cli, []byte(fakeTerraform), 0700); err != nil {
+				t.Fatalf("cannot write the fake Terraform CLI: %v", err)
+			}
+			b := NewTFExecBackend(WithTFExecBackendCLI(CLI{Path: cli}))
+			result, err := tc.op(context.TODO(), b, Invocation{Dir: dir, Env: []string{envReattachConfig + "=" + reattachEnv}})
+			if diff := cmp.Diff(tc.want.result, result); diff != "" {
+				t.Errorf("\n%s\n-want result, +got result:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\n-want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestTFExecBackendReattach(t *testing.T) {
+	dir := t.TempDir()
+	cli := filepath.Join(dir, "terraform")
+	if err := os.WriteFile(cli, []byte(fakeTerraform), 0700); err != nil {
+		t.Fatalf("cannot write the fake Terraform CLI: %v", err)
+	}
+	b := NewTFExecBackend(WithTFExecBackendCLI(CLI{Path: cli}))
+	_ = b.Apply(context.TODO(), Invocation{Dir: dir, Env: []string{envReattachConfig + "=" + reattachEnv}})
+	got, err := os.ReadFile(filepath.Join(dir, "reattach"))
+	if err != nil {
+		t.Fatalf("cannot read the reattach configuration passed to the CLI: %v", err)
+	}
+	want, gotInfo := tfexec.ReattachInfo{}, tfexec.ReattachInfo{}
+	if err := json.JSParser.Unmarshal([]byte(reattachEnv), &want); err != nil {
+		t.Fatalf("cannot unmarshal the reattach configuration: %v", err)
+	}
+	if err := json.JSParser.Unmarshal(got, &gotInfo); err != nil {
+		t.Fatalf("cannot unmarshal the reattach configuration passed to the CLI: %v", err)
+	}
+	if diff := cmp.Diff(want, gotInfo); diff != "" {
+		t.Errorf("Apply(...): -want reattach configuration, +got reattach configuration:\n%s", diff)
+	}
+}
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"strings"
+)
+
+const (
+	defaultCLIPath      = "terraform"
+	defaultRegistryHost = "registry.terraform.io"
+)
+
+// nestedCommands are the CLI commands that have subcommands, e.g., the state
+// command of "state pull".
+var nestedCommands = map[string]struct{}{
+	"state":     {},
+	"workspace": {},
+	"providers": {},
+}
+
+// CLI is the command-line tool the Terraform workspaces are run with,
+// e.g., Terraform or OpenTofu.
+type CLI struct {
+	// Path is the name or the path of the CLI binary. If it's a name,
+	// the binary is looked up in the PATH. Defaults to "terraform".
+	Path string
+
+	// RequiredVersion is the version constraint of the CLI, which is written
+	// as terraform.required_version in the generated main.tf.json files,
+	// e.g., ">= 1.6.0". Not set if empty.
+	RequiredVersion string
+
+	// RegistryHost is the hostname of the registry the provider is sourced
+	// from, which is used in the provider addresses written in the Terraform
+	// state files. Defaults to "registry.terraform.io".
+	RegistryHost string
+
+	// ExtraArgs are the extra arguments, keyed by the full subcommand,
+	// passed to the CLI right after the subcommand, e.g.,
+	// {"init": {"-plugin-dir=/plugins"}, "state pull": {"-no-color"}}.
+	ExtraArgs map[string][]string
+
+	// Settings are the extra settings written into the terraform block of
+	// the generated main.tf.json files, such as the "encryption" block of
+	// OpenTofu's state encryption. The settings cannot override
+	// the required_providers and required_version settings.
+	Settings map[string]any
+}
+
+// NewTerraformCLI returns a CLI that runs the Terraform binary.
+func NewTerraformCLI() CLI {
+	return CLI{
+		Path:         defaultCLIPath,
+		RegistryHost: defaultRegistryHost,
+	}
+}
+
+// NewOpenTofuCLI returns a CLI that runs the OpenTofu binary and sources
+// the providers from the OpenTofu registry.
+func NewOpenTofuCLI() CLI {
+	return CLI{
+		Path:         "tofu",
+		RegistryHost: "registry.opentofu.org",
+	}
+}
+
+func (c CLI) binary() string {
+	if c.Path == "" {
+		return defaultCLIPath
+	}
+	return c.Path
+}
+
+func (c CLI) registryHost() string {
+	if c.RegistryHost == "" {
+		return defaultRegistryHost
+	}
+	return c.RegistryHost
+}
+
+// args returns the arguments of the CLI invocation with the supplied
+// arguments, which start with the subcommand, e.g., "state pull", after
+// inserting the configured extra arguments of the subcommand.
+func (c CLI) args(args ...string) []string {
+	n := 1
+	if _, ok := nestedCommands[args[0]]; ok && len(args) > 1 && !strings.HasPrefix(args[1], "-") {
+		n = 2
+	}
+	extra := c.ExtraArgs[strings.Join(args[:n], " ")]
+	if len(extra) == 0 {
+		return args
+	}
+	result := make([]string, 0, len(args)+len(extra))
+	result = append(result, args[:n]...)
+	result = append(result, extra...)
+	return append(result, args[n:]...)
+}
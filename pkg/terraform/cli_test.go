@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCLIArgs(t *testing.T) {
+	type args struct {
+		cli  CLI
+		args []string
+	}
+	type want struct {
+		binary string
+		args   []string
+	}
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"Default": {
+			args: args{
+				args: []string{"apply", "-auto-approve"},
+			},
+			want: want{
+				binary: "terraform",
+				args:   []string{"apply", "-auto-approve"},
+			},
+		},
+		"ExtraArgsOfOtherSubcommand": {
+			args: args{
+				cli: CLI{
+					Path:      "tofu",
+					ExtraArgs: map[string][]string{"init": {"-plugin-dir=/plugins"}},
+				},
+				args: []string{"apply", "-auto-approve"},
+			},
+			want: want{
+				binary: "tofu",
+				args:   []string{"apply", "-auto-approve"},
+			},
+		},
+		"ExtraArgs": {
+			args: args{
+				cli: CLI{
+					ExtraArgs: map[string][]string{"init": {"-plugin-dir=/plugins", "-get=false"}},
+				},
+				args: []string{"init", "-input=false"},
+			},
+			want: want{
+				binary: "terraform",
+				args:   []string{"init", "-plugin-dir=/plugins", "-get=false", "-input=false"},
+			},
+		},
+		"ExtraArgsOfNestedSubcommand": {
+			args: args{
+				cli: CLI{
+					ExtraArgs: map[string][]string{"state pull": {"-no-color"}, "state": {"-ignored"}},
+				},
+				args: []string{"state", "pull"},
+			},
+			want: want{
+				binary: "terraform",
+				args:   []string{"state", "pull", "-no-color"},
+			},
+		},
+		"ExtraArgsOfOtherNestedSubcommand": {
+			args: args{
+				cli: CLI{
+					ExtraArgs: map[string][]string{"state pull": {"-no-color"}},
+				},
+				args: []string{"state", "rm", "-lock=false", "aws_vpc.vpc"},
+			},
+			want: want{
+				binary: "terraform",
+				args:   []string{"state", "rm", "-lock=false", "aws_vpc.vpc"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want.binary, tc.args.cli.binary()); diff != "" {
+				t.Errorf("\n%s\nbinary(): -want, +got:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.args, tc.args.cli.args(tc.args.args...)); diff != "" {
+				t.Errorf("\n%s\nargs(...): -want, +got:\n%s", name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package errors
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// ErrorClass is the class of a Terraform error, which determines whether
+// the failed operation is worth retrying.
+type ErrorClass string
+
+const (
+	// ClassUnknown is the class of the errors that are not classified.
+	ClassUnknown ErrorClass = ""
+	// ClassThrottling is the class of the errors caused by the rate limits
+	// of the external APIs.
+	ClassThrottling ErrorClass = "Throttling"
+	// ClassTransient is the class of the errors caused by the transient
+	// network or external API problems.
+	ClassTransient ErrorClass = "Transient"
+	// ClassPermissionDenied is the class of the errors caused by
+	// the insufficient permissions of the provider's credentials.
+	ClassPermissionDenied ErrorClass = "PermissionDenied"
+	// ClassInvalidConfiguration is the class of the errors caused by
+	// an invalid configuration of the resource.
+	ClassInvalidConfiguration ErrorClass = "InvalidConfiguration"
+)
+
+// Terminal returns whether the errors of the class are not expected to be
+// resolved by retrying the failed operation without a change in
+// the configuration or in the permissions.
+func (c ErrorClass) Terminal() bool {
+	return c == ClassPermissionDenied || c == ClassInvalidConfiguration
+}
+
+// ClassificationRule classifies the errors that match it either with
+// the error message or with the summaries of their Terraform diagnostics.
+type ClassificationRule struct {
+	// Class is the class of the errors matching the rule.
+	Class ErrorClass
+	// MessageRegex, if set, matches the error messages.
+	MessageRegex *regexp.Regexp
+	// Summaries, if set, are the Terraform diagnostic summaries to match.
+	Summaries []string
+}
+
+func (r ClassificationRule) matches(err error) bool {
+	if r.MessageRegex != nil && r.MessageRegex.MatchString(err.Error()) {
+		return true
+	}
+	for _, d := range Diagnostics(err) {
+		for _, s := range r.Summaries {
+			if d.Summary == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DefaultClassificationRules returns the classification rules for
+// the common Terraform and cloud API errors.
+func DefaultClassificationRules() []ClassificationRule {
+	return []ClassificationRule{
+		{
+			Class:        ClassThrottling,
+			MessageRegex: regexp.MustCompile(`(?i)(throttl|rate exceeded|rate limit|too many requests|RequestLimitExceeded|status ?code:? ?429)`),
+		},
+		{
+			// the expired temporary credentials, e.g., STS session tokens,
+			// are expected to be refreshed and are not terminal.
+			Class:        ClassTransient,
+			MessageRegex: regexp.MustCompile(`(?i)(ExpiredToken|token (has |is )?expired|expired token)`),
+		},
+		{
+			Class:        ClassPermissionDenied,
+			MessageRegex: regexp.MustCompile(`(?i)(access ?denied|UnauthorizedOperation|permission denied|AuthorizationFailed|status ?code:? ?403)`),
+		},
+		{
+			Class: ClassInvalidConfiguration,
+			Summaries: []string{
+				"Missing required argument",
+				"Unsupported argument",
+				"Invalid value",
+				"Invalid reference",
+				"Incorrect attribute value type",
+				"Conflicting configuration arguments",
+			},
+			MessageRegex: regexp.MustCompile(`(?i)(ValidationError|InvalidParameterValue|invalid parameter|MalformedPolicyDocument)`),
+		},
+		{
+			Class:        ClassTransient,
+			MessageRegex: regexp.MustCompile(`(?i)(connection reset|connection refused|i/o timeout|TLS handshake timeout|no such host|unexpected EOF|temporarily unavailable|ServiceUnavailable|status ?code:? ?50[234])`),
+		},
+	}
+}
+
+// Classifier classifies the Terraform errors with a list of rules,
+// the first matching rule determining the class of an error.
+type Classifier struct {
+	rules []ClassificationRule
+}
+
+// NewClassifier returns a Classifier with the specified rules. Providers
+// can extend the default rules by prepending their own rules to
+// the DefaultClassificationRules.
+func NewClassifier(rules ...ClassificationRule) *Classifier {
+	return &Classifier{
+		rules: rules,
+	}
+}
+
+// Classify annotates the specified error with its class, if any of
+// the rules matches it. Returns nil if the error is nil.
+func (c *Classifier) Classify(err error) error {
+	if c == nil || err == nil {
+		return err
+	}
+	for _, r := range c.rules {
+		if r.matches(err) {
+			return &classifiedError{
+				error: err,
+				class: r.Class,
+			}
+		}
+	}
+	return err
+}
+
+type classifiedError struct {
+	error
+	class ErrorClass
+}
+
+func (c *classifiedError) Unwrap() error {
+	return c.error
+}
+
+// ClassOf returns the class the specified error chain has been annotated
+// with, or ClassUnknown if it has not been classified.
+func ClassOf(err error) ErrorClass {
+	c := &classifiedError{}
+	if !errors.As(err, &c) {
+		return ClassUnknown
+	}
+	return c.class
+}
+
+// IsTerminal returns whether the specified error chain has been classified
+// as a terminal error.
+func IsTerminal(err error) bool {
+	return ClassOf(err).Terminal()
+}
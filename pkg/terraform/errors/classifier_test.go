@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package errors
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+func TestClassify(t *testing.T) {
+	type args struct {
+		rules []ClassificationRule
+		err   error
+	}
+	type want struct {
+		class    ErrorClass
+		terminal bool
+	}
+	tests := map[string]struct {
+		args args
+		want want
+	}{
+		"NilError": {
+			args: args{
+				rules: DefaultClassificationRules(),
+			},
+		},
+		"Unclassified": {
+			args: args{
+				rules: DefaultClassificationRules(),
+				err:   errorBoom,
+			},
+		},
+		"Throttling": {
+			args: args{
+				rules: DefaultClassificationRules(),
+				err:   errors.New("ThrottlingException: Rate exceeded"),
+			},
+			want: want{
+				class: ClassThrottling,
+			},
+		},
+		"Transient": {
+			args: args{
+				rules: DefaultClassificationRules(),
+				err:   errors.New("dial tcp 10.0.0.1:443: i/o timeout"),
+			},
+			want: want{
+				class: ClassTransient,
+			},
+		},
+		"PermissionDenied": {
+			args: args{
+				rules: DefaultClassificationRules(),
+				err:   errors.Wrap(errors.New("AccessDenied: User is not authorized to perform ec2:CreateVpc"), "cannot apply"),
+			},
+			want: want{
+				class:    ClassPermissionDenied,
+				terminal: true,
+			},
+		},
+		"ExpiredToken": {
+			args: args{
+				rules: DefaultClassificationRules(),
+				err:   errors.New("ExpiredTokenException: The security token included in the request is expired"),
+			},
+			want: want{
+				class: ClassTransient,
+			},
+		},
+		"Unauthorized": {
+			args: args{
+				rules: DefaultClassificationRules(),
+				err:   errors.New("Unauthorized: the server has asked for the client to provide credentials"),
+			},
+		},
+		"InvalidConfigurationDiagnostic": {
+			args: args{
+				rules: DefaultClassificationRules(),
+				err:   NewApplyFailed(errorLog),
+			},
+			want: want{
+				class:    ClassInvalidConfiguration,
+				terminal: true,
+			},
+		},
+		"CustomRule": {
+			args: args{
+				rules: append([]ClassificationRule{{
+					Class:        ClassTransient,
+					MessageRegex: regexp.MustCompile(`OperationInProgress`),
+				}}, DefaultClassificationRules()...),
+				err: errors.New("OperationInProgress: another operation is in progress"),
+			},
+			want: want{
+				class: ClassTransient,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewClassifier(tt.args.rules...).Classify(tt.args.err)
+			if tt.args.err == nil && err != nil {
+				t.Errorf("\nClassify(...): unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want.class, ClassOf(err)); diff != "" {
+				t.Errorf("\nClassOf(...): -want class, +got class:\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want.terminal, IsTerminal(errors.Wrap(err, "wrapped"))); diff != "" {
+				t.Errorf("\nIsTerminal(...): -want terminal, +got terminal:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClassOfAsyncFailed(t *testing.T) {
+	classified := NewClassifier(DefaultClassificationRules()...).Classify(errors.New("AccessDenied: User is not authorized to perform ec2:CreateVpc"))
+	tests := map[string]struct {
+		err error
+	}{
+		"AsyncCreateFailed": {
+			err: NewAsyncCreateFailed(classified),
+		},
+		"AsyncUpdateFailed": {
+			err: NewAsyncUpdateFailed(classified),
+		},
+		"AsyncDeleteFailed": {
+			err: NewAsyncDeleteFailed(classified),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(ClassPermissionDenied, ClassOf(tt.err)); diff != "" {
+				t.Errorf("\nClassOf(...): -want class, +got class:\n%s", diff)
+			}
+			if !IsTerminal(tt.err) {
+				t.Errorf("\nIsTerminal(...): want a terminal error, got a non-terminal error")
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ package errors
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	jsoniter "github.com/json-iterator/go"
@@ -16,8 +17,18 @@ const (
 	levelError = "error"
 )
 
+// argumentRegex matches the argument name in the details of
+// the diagnostics about the missing or unsupported arguments.
+var argumentRegex = regexp.MustCompile(`^The argument "([^"]+)" is`)
+
 type tfError struct {
-	message string
+	message     string
+	diagnostics []Diagnostic
+}
+
+// Diagnostics returns the diagnostics reported by Terraform.
+func (t *tfError) Diagnostics() []Diagnostic {
+	return t.diagnostics
 }
 
 type applyFailed struct {
@@ -37,6 +48,71 @@ type LogDiagnostic struct {
 	Severity string `json:"severity"`
 	Summary  string `json:"summary"`
 	Detail   string `json:"detail"`
+	// Address is the address of the resource the diagnostic is about,
+	// e.g., aws_vpc.example.
+	Address string `json:"address,omitempty"`
+	// Attribute is the path of the attribute the diagnostic is about,
+	// e.g., ingress[0].from_port, optionally prefixed with Address.
+	Attribute string `json:"attribute,omitempty"`
+}
+
+// Diagnostic is a structured Terraform diagnostic.
+type Diagnostic struct {
+	// Severity is either error or warning.
+	Severity string
+	// Summary is the short description of the diagnostic.
+	Summary string
+	// Detail is the detailed description of the diagnostic.
+	Detail string
+	// AttributePath is the Terraform path of the attribute the diagnostic
+	// is about, e.g., ingress[0].from_port, if known.
+	AttributePath string
+}
+
+// Message returns the summary and the detail of the diagnostic.
+func (d Diagnostic) Message() string {
+	if d.Detail == "" {
+		return d.Summary
+	}
+	return fmt.Sprintf("%s: %s", d.Summary, d.Detail)
+}
+
+type diagnosticsError struct {
+	error
+	diagnostics []Diagnostic
+}
+
+func (d *diagnosticsError) Unwrap() error {
+	return d.error
+}
+
+// Diagnostics returns the diagnostics the error is annotated with.
+func (d *diagnosticsError) Diagnostics() []Diagnostic {
+	return d.diagnostics
+}
+
+// WithDiagnostics annotates the specified error with the specified
+// diagnostics. Returns nil if the error is nil.
+func WithDiagnostics(err error, diagnostics []Diagnostic) error {
+	if err == nil {
+		return nil
+	}
+	return &diagnosticsError{
+		error:       err,
+		diagnostics: diagnostics,
+	}
+}
+
+// Diagnostics returns the Terraform diagnostics in the specified error chain,
+// if any.
+func Diagnostics(err error) []Diagnostic {
+	var d interface {
+		Diagnostics() []Diagnostic
+	}
+	if !errors.As(err, &d) {
+		return nil
+	}
+	return d.Diagnostics()
 }
 
 func (t *tfError) Error() string {
@@ -55,6 +131,9 @@ func newTFError(message string, logs []byte) (string, *tfError) {
 
 	messages := make([]string, 0, len(tfLogs))
 	for _, l := range tfLogs {
+		if l != nil && l.Diagnostic.Summary != "" {
+			tfError.diagnostics = append(tfError.diagnostics, newDiagnostic(l.Diagnostic))
+		}
 		// only use error logs
 		if l == nil || l.Level != levelError {
 			continue
@@ -69,6 +148,22 @@ func newTFError(message string, logs []byte) (string, *tfError) {
 	return "", tfError
 }
 
+func newDiagnostic(d LogDiagnostic) Diagnostic {
+	result := Diagnostic{
+		Severity: d.Severity,
+		Summary:  d.Summary,
+		Detail:   d.Detail,
+	}
+	if d.Attribute != "" {
+		result.AttributePath = strings.TrimPrefix(d.Attribute, d.Address+".")
+		return result
+	}
+	if m := argumentRegex.FindStringSubmatch(d.Detail); m != nil {
+		result.AttributePath = m[1]
+	}
+	return result
+}
+
 func parseTerraformLogs(logs []byte) ([]*TerraformLog, error) {
 	logLines := strings.Split(string(logs), "\n")
 	tfLogs := make([]*TerraformLog, 0, len(logLines))
@@ -165,6 +260,7 @@ func IsPlanFailed(err error) bool {
 type retrySchedule struct {
 	invocationCount int
 	ttl             int
+	evictionReason  string
 }
 
 func NewRetryScheduleError(invocationCount, ttl int) error {
@@ -174,7 +270,18 @@ func NewRetryScheduleError(invocationCount, ttl int) error {
 	}
 }
 
+// NewDrainingScheduleError returns a new retry error for the scheduler,
+// which is returned while an evicted native provider is draining.
+func NewDrainingScheduleError(evictionReason string) error {
+	return &retrySchedule{
+		evictionReason: evictionReason,
+	}
+}
+
 func (r *retrySchedule) Error() string {
+	if r.evictionReason != "" {
+		return fmt.Sprintf("native provider has been evicted and is draining: %s", r.evictionReason)
+	}
 	return fmt.Sprintf("native provider reuse budget has been exceeded: invocationCount: %d, ttl: %d", r.invocationCount, r.ttl)
 }
 
@@ -199,6 +306,10 @@ func NewAsyncCreateFailed(err error) error {
 	}
 }
 
+func (e *asyncCreateFailed) Unwrap() error {
+	return e.error
+}
+
 // IsAsyncCreateFailed returns whether error is due to failure of
 // an async create operation.
 func IsAsyncCreateFailed(err error) bool {
@@ -220,6 +331,10 @@ func NewAsyncUpdateFailed(err error) error {
 	}
 }
 
+func (e *asyncUpdateFailed) Unwrap() error {
+	return e.error
+}
+
 // IsAsyncUpdateFailed returns whether error is due to failure of
 // an async update operation.
 func IsAsyncUpdateFailed(err error) bool {
@@ -241,6 +356,10 @@ func NewAsyncDeleteFailed(err error) error {
 	}
 }
 
+func (e *asyncDeleteFailed) Unwrap() error {
+	return e.error
+}
+
 // IsAsyncDeleteFailed returns whether error is due to failure of
 // an async delete operation.
 func IsAsyncDeleteFailed(err error) bool {
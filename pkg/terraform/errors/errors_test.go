@@ -609,3 +609,71 @@ func TestIsAsyncDeleteFailed(t *testing.T) {
 		})
 	}
 }
+
+func TestDiagnostics(t *testing.T) {
+	attributeLog := []byte(`{"@level":"error","@message":"Error: Invalid value","diagnostic":{"severity":"error","summary":"Invalid value","detail":"expected a valid port","address":"aws_security_group.example","attribute":"aws_security_group.example.ingress[0].from_port","snippet":{"context":"resource.aws_security_group.example","code":"        \"from_port\": 70000,"}},"type":"diagnostic"}`)
+	type args struct {
+		err error
+	}
+	tests := map[string]struct {
+		args args
+		want []Diagnostic
+	}{
+		"NoDiagnostics": {
+			args: args{
+				err: errorBoom,
+			},
+		},
+		"ApplyFailed": {
+			args: args{
+				err: errors.Wrap(NewApplyFailed(errorLog), "cannot apply"),
+			},
+			want: []Diagnostic{
+				{
+					Severity:      "error",
+					Summary:       "Missing required argument",
+					Detail:        `The argument "location" is required, but no definition was found.`,
+					AttributePath: "location",
+				},
+				{
+					Severity:      "error",
+					Summary:       "Missing required argument",
+					Detail:        `The argument "name" is required, but no definition was found.`,
+					AttributePath: "name",
+				},
+			},
+		},
+		"NestedAttribute": {
+			args: args{
+				err: NewPlanFailed(attributeLog),
+			},
+			want: []Diagnostic{
+				{
+					Severity:      "error",
+					Summary:       "Invalid value",
+					Detail:        "expected a valid port",
+					AttributePath: "ingress[0].from_port",
+				},
+			},
+		},
+		"WithDiagnostics": {
+			args: args{
+				err: errors.Wrap(WithDiagnostics(errorBoom, []Diagnostic{{Severity: "error", Summary: "boom", AttributePath: "tags[key]"}}), "cannot create"),
+			},
+			want: []Diagnostic{
+				{
+					Severity:      "error",
+					Summary:       "boom",
+					AttributePath: "tags[key]",
+				},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, Diagnostics(tt.args.err)); diff != "" {
+				t.Errorf("\nDiagnostics(...): -want diagnostics, +got diagnostics:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package fake
+
+import (
+	"context"
+
+	"github.com/crossplane/upjet/pkg/terraform"
+)
+
+// Backend is a mock terraform.Backend. The operations whose mock functions
+// are not set succeed without doing anything, Import reports that
+// the imported resource exists and StatePull returns an empty state.
+type Backend struct {
+	MockInit    func(ctx context.Context, inv terraform.Invocation, upgrade bool) error
+	MockApply   func(ctx context.Context, inv terraform.Invocation) error
+	MockDestroy func(ctx context.Context, inv terraform.Invocation) error
+	MockRefresh func(ctx context.Context, inv terraform.Invocation) error
+	MockPlan    func(ctx context.Context, inv terraform.Invocation) (terraform.PlanResult, error)
+	MockImport  func(ctx context.Context, inv terraform.Invocation, address, id string) (bool, error)
+
+	MockStatePull   func(ctx context.Context, inv terraform.Invocation) ([]byte, error)
+	MockStatePush   func(ctx context.Context, inv terraform.Invocation, path string) error
+	MockStateRemove func(ctx context.Context, inv terraform.Invocation, address string) error
+}
+
+// Init is a mock.
+func (b *Backend) Init(ctx context.Context, inv terraform.Invocation, upgrade bool) error {
+	if b.MockInit == nil {
+		return nil
+	}
+	return b.MockInit(ctx, inv, upgrade)
+}
+
+// Apply is a mock.
+func (b *Backend) Apply(ctx context.Context, inv terraform.Invocation) error {
+	if b.MockApply == nil {
+		return nil
+	}
+	return b.MockApply(ctx, inv)
+}
+
+// Destroy is a mock.
+func (b *Backend) Destroy(ctx context.Context, inv terraform.Invocation) error {
+	if b.MockDestroy == nil {
+		return nil
+	}
+	return b.MockDestroy(ctx, inv)
+}
+
+// Refresh is a mock.
+func (b *Backend) Refresh(ctx context.Context, inv terraform.Invocation) error {
+	if b.MockRefresh == nil {
+		return nil
+	}
+	return b.MockRefresh(ctx, inv)
+}
+
+// Plan is a mock.
+func (b *Backend) Plan(ctx context.Context, inv terraform.Invocation) (terraform.PlanResult, error) {
+	if b.MockPlan == nil {
+		return terraform.PlanResult{Exists: true, UpToDate: true}, nil
+	}
+	return b.MockPlan(ctx, inv)
+}
+
+// Import is a mock.
+func (b *Backend) Import(ctx context.Context, inv terraform.Invocation, address, id string) (bool, error) {
+	if b.MockImport == nil {
+		return true, nil
+	}
+	return b.MockImport(ctx, inv, address, id)
+}
+
+// StatePull is a mock.
+func (b *Backend) StatePull(ctx context.Context, inv terraform.Invocation) ([]byte, error) {
+	if b.MockStatePull == nil {
+		return nil, nil
+	}
+	return b.MockStatePull(ctx, inv)
+}
+
+// StatePush is a mock.
+func (b *Backend) StatePush(ctx context.Context, inv terraform.Invocation, path string) error {
+	if b.MockStatePush == nil {
+		return nil
+	}
+	return b.MockStatePush(ctx, inv, path)
+}
+
+// StateRemove is a mock.
+func (b *Backend) StateRemove(ctx context.Context, inv terraform.Invocation, address string) error {
+	if b.MockStateRemove == nil {
+		return nil
+	}
+	return b.MockStateRemove(ctx, inv, address)
+}
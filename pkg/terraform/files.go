@@ -34,6 +34,7 @@ const (
 	errUnmarshalTFState  = "cannot unmarshal tfstate file"
 	errFmtNonString      = "cannot work with a non-string id: %s"
 	errReadMainTF        = "cannot read main.tf.json file"
+	errGetPrivateState   = "cannot get the private state"
 )
 
 // FileProducerOption allows you to configure FileProducer
@@ -53,6 +54,31 @@ func WithFileProducerFeatures(f *feature.Flags) FileProducerOption {
 	}
 }
 
+// WithFileProducerCLI configures the CLI, e.g., Terraform or OpenTofu,
+// the files are produced for.
+func WithFileProducerCLI(c CLI) FileProducerOption {
+	return func(fp *FileProducer) {
+		fp.cli = c
+	}
+}
+
+// WithFileProducerStateBackend configures the Terraform backend the state
+// is stored in.
+func WithFileProducerStateBackend(b *StateBackend) FileProducerOption {
+	return func(fp *FileProducer) {
+		fp.stateBackend = b
+	}
+}
+
+// WithFileProducerPrivateStateStore configures the store the private state
+// of the Terraform state is read from. Defaults to the annotations of
+// the managed resource.
+func WithFileProducerPrivateStateStore(s resource.PrivateStateStore) FileProducerOption {
+	return func(fp *FileProducer) {
+		fp.privateStateStore = s
+	}
+}
+
 // NewFileProducer returns a new FileProducer.
 func NewFileProducer(ctx context.Context, client resource.SecretClient, dir string, tr resource.Terraformed, ts Setup, cfg *config.Resource, opts ...FileProducerOption) (*FileProducer, error) {
 	fp := &FileProducer{
@@ -62,6 +88,9 @@ func NewFileProducer(ctx context.Context, client resource.SecretClient, dir stri
 		Config:   cfg,
 		fs:       afero.Afero{Fs: afero.NewOsFs()},
 		features: &feature.Flags{},
+		cli:      NewTerraformCLI(),
+
+		privateStateStore: resource.NewAnnotationPrivateStateStore(),
 	}
 	for _, f := range opts {
 		f(fp)
@@ -100,6 +129,9 @@ func NewFileProducer(ctx context.Context, client resource.SecretClient, dir stri
 	if err = resource.GetSensitiveParameters(ctx, client, tr, params, tr.GetConnectionDetailsMapping()); err != nil {
 		return nil, errors.Wrap(err, "cannot get sensitive parameters")
 	}
+	if _, err := resource.DisableNativeDeletionProtection(tr, cfg, params); err != nil {
+		return nil, errors.Wrap(err, "cannot turn off the native deletion protection")
+	}
 	fp.Config.ExternalName.SetIdentifierArgumentFn(params, meta.GetExternalName(tr))
 	fp.parameters = params
 
@@ -123,11 +155,15 @@ type FileProducer struct {
 	Dir      string
 	Config   *config.Resource
 
-	parameters  map[string]any
-	observation map[string]any
-	ignored     []string
-	fs          afero.Afero
-	features    *feature.Flags
+	parameters   map[string]any
+	observation  map[string]any
+	ignored      []string
+	fs           afero.Afero
+	features     *feature.Flags
+	cli          CLI
+	stateBackend *StateBackend
+
+	privateStateStore resource.PrivateStateStore
 }
 
 // WriteMainTF writes the content main configuration file that has the desired
@@ -153,15 +189,26 @@ func (fp *FileProducer) WriteMainTF() (ProviderHandle, error) {
 	// Note(turkenh): To use third party providers, we need to configure
 	// provider name in required_providers.
 	providerSource := strings.Split(fp.Setup.Requirement.Source, "/")
-	m := map[string]any{
-		"terraform": map[string]any{
-			"required_providers": map[string]any{
-				providerSource[len(providerSource)-1]: map[string]string{
-					"source":  fp.Setup.Requirement.Source,
-					"version": fp.Setup.Requirement.Version,
-				},
-			},
+	tfBlock := make(map[string]any, len(fp.cli.Settings)+2)
+	for k, v := range fp.cli.Settings {
+		tfBlock[k] = v
+	}
+	tfBlock["required_providers"] = map[string]any{
+		providerSource[len(providerSource)-1]: map[string]string{
+			"source":  fp.Setup.Requirement.Source,
+			"version": fp.Setup.Requirement.Version,
 		},
+	}
+	if fp.cli.RequiredVersion != "" {
+		tfBlock["required_version"] = fp.cli.RequiredVersion
+	}
+	if fp.stateBackend != nil {
+		tfBlock["backend"] = map[string]any{
+			fp.stateBackend.Type: fp.stateBackend.ConfigFn(fp.Resource),
+		}
+	}
+	m := map[string]any{
+		"terraform": tfBlock,
 		"provider": map[string]any{
 			providerSource[len(providerSource)-1]: fp.Setup.Configuration,
 		},
@@ -184,7 +231,7 @@ func (fp *FileProducer) WriteMainTF() (ProviderHandle, error) {
 
 // EnsureTFState writes the Terraform state that should exist in the filesystem
 // to start any Terraform operation.
-func (fp *FileProducer) EnsureTFState(_ context.Context, tfID string) error {
+func (fp *FileProducer) EnsureTFState(ctx context.Context, tfID string) error {
 	// TODO(muvaf): Reduce the cyclomatic complexity by separating the attributes
 	// generation into its own function/interface.
 	empty, err := fp.isStateEmpty()
@@ -214,38 +261,46 @@ func (fp *FileProducer) EnsureTFState(_ context.Context, tfID string) error {
 		return errors.Wrap(err, errMarshalAttributes)
 	}
 	var privateRaw []byte
-	if pr, ok := fp.Resource.GetAnnotations()[resource.AnnotationKeyPrivateRawAttribute]; ok {
+	pr, err := fp.privateStateStore.Get(ctx, fp.Resource)
+	if err != nil {
+		return errors.Wrap(err, errGetPrivateState)
+	}
+	if pr != "" {
 		privateRaw = []byte(pr)
 	}
 	if privateRaw, err = insertTimeoutsMeta(privateRaw, timeouts(fp.Config.OperationTimeouts)); err != nil {
 		return errors.Wrap(err, errInsertTimeouts)
 	}
+	s := newResourceStateV4(fp.Resource, fp.Setup, fp.cli.registryHost(), attr, privateRaw)
+	rawState, err := json.JSParser.Marshal(s)
+	if err != nil {
+		return errors.Wrap(err, errMarshalState)
+	}
+	return errors.Wrap(fp.fs.WriteFile(filepath.Join(fp.Dir, "terraform.tfstate"), rawState, 0600), errWriteTFStateFile)
+}
+
+// newResourceStateV4 returns the Terraform state of the specified resource
+// with the specified attributes and private state.
+func newResourceStateV4(tr resource.Terraformed, ts Setup, registryHost string, attr, privateRaw []byte) *json.StateV4 {
 	s := json.NewStateV4()
-	s.TerraformVersion = fp.Setup.Version
-	s.Lineage = string(fp.Resource.GetUID())
+	s.TerraformVersion = ts.Version
+	s.Lineage = string(tr.GetUID())
 	s.Resources = []json.ResourceStateV4{
 		{
-			Mode: "managed",
-			Type: fp.Resource.GetTerraformResourceType(),
-			Name: fp.Resource.GetName(),
-			// TODO(muvaf): we should get the full URL from Dockerfile since
-			// providers don't have to be hosted in registry.terraform.io
-			ProviderConfig: fmt.Sprintf(`provider["registry.terraform.io/%s"]`, fp.Setup.Requirement.Source),
+			Mode:           "managed",
+			Type:           tr.GetTerraformResourceType(),
+			Name:           tr.GetName(),
+			ProviderConfig: fmt.Sprintf(`provider["%s/%s"]`, registryHost, ts.Requirement.Source),
 			Instances: []json.InstanceObjectStateV4{
 				{
-					SchemaVersion: uint64(fp.Resource.GetTerraformSchemaVersion()),
+					SchemaVersion: uint64(tr.GetTerraformSchemaVersion()),
 					PrivateRaw:    privateRaw,
 					AttributesRaw: attr,
 				},
 			},
 		},
 	}
-
-	rawState, err := json.JSParser.Marshal(s)
-	if err != nil {
-		return errors.Wrap(err, errMarshalState)
-	}
-	return errors.Wrap(fp.fs.WriteFile(filepath.Join(fp.Dir, "terraform.tfstate"), rawState, 0600), errWriteTFStateFile)
+	return s
 }
 
 // isStateEmpty returns whether the Terraform state includes a resource or not.
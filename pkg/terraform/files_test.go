@@ -297,6 +297,8 @@ func TestWriteMainTF(t *testing.T) {
 		cfg *config.Resource
 		s   Setup
 		f   *feature.Flags
+		cli CLI
+		sb  *StateBackend
 	}
 	type want struct {
 		maintf string
@@ -408,6 +410,74 @@ func TestWriteMainTF(t *testing.T) {
 				maintf: `{"provider":{"provider-test":null},"resource":{"":{"":{"lifecycle":{"prevent_destroy":true},"name":"some-id","param":"paramval"}}},"terraform":{"required_providers":{"provider-test":{"source":"my-company/namespace/provider-test","version":"1.2.3"}}}}`,
 			},
 		},
+		"OpenTofu": {
+			reason: "The CLI's required version and extra settings such as state encryption should be written into maintf file",
+			args: args{
+				tr: &fake.Terraformed{
+					Managed: xpfake.Managed{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								meta.AnnotationKeyExternalName: "some-id",
+							},
+						},
+					},
+					Parameterizable: fake.Parameterizable{Parameters: map[string]any{
+						"param": "paramval",
+					}},
+				},
+				cfg: config.DefaultResource("upjet_resource", nil, nil),
+				s: Setup{
+					Requirement: ProviderRequirement{
+						Source:  "hashicorp/provider-test",
+						Version: "1.2.3",
+					},
+				},
+				cli: func() CLI {
+					c := NewOpenTofuCLI()
+					c.RequiredVersion = ">= 1.7.0"
+					c.Settings = map[string]any{
+						"encryption": map[string]any{
+							"key_provider": map[string]any{"pbkdf2": map[string]any{"key": map[string]any{"passphrase": "secret"}}},
+						},
+						"required_version": "should-be-overwritten",
+					}
+					return c
+				}(),
+			},
+			want: want{
+				maintf: `{"provider":{"provider-test":null},"resource":{"":{"":{"lifecycle":{"prevent_destroy":true},"name":"some-id","param":"paramval"}}},"terraform":{"encryption":{"key_provider":{"pbkdf2":{"key":{"passphrase":"secret"}}}},"required_providers":{"provider-test":{"source":"hashicorp/provider-test","version":"1.2.3"}},"required_version":">= 1.7.0"}}`,
+			},
+		},
+		"StateBackend": {
+			reason: "The configured state backend should be written into maintf file",
+			args: args{
+				tr: &fake.Terraformed{
+					Managed: xpfake.Managed{
+						ObjectMeta: metav1.ObjectMeta{
+							UID: "1234",
+							Annotations: map[string]string{
+								meta.AnnotationKeyExternalName: "some-id",
+							},
+						},
+					},
+					Parameterizable: fake.Parameterizable{Parameters: map[string]any{}},
+				},
+				cfg: config.DefaultResource("upjet_resource", nil, nil),
+				s: Setup{
+					Requirement: ProviderRequirement{
+						Source:  "hashicorp/provider-test",
+						Version: "1.2.3",
+					},
+				},
+				sb: func() *StateBackend {
+					b := NewKubernetesStateBackend(nil, "upbound-system")
+					return &b
+				}(),
+			},
+			want: want{
+				maintf: `{"provider":{"provider-test":null},"resource":{"":{"":{"lifecycle":{"prevent_destroy":true},"name":"some-id"}}},"terraform":{"backend":{"kubernetes":{"in_cluster_config":true,"namespace":"upbound-system","secret_suffix":"1234"}},"required_providers":{"provider-test":{"source":"hashicorp/provider-test","version":"1.2.3"}}}}`,
+			},
+		},
 		"SuccessManagementPolicies": {
 			reason: "Management policies enabled with ignore changes resources and merging initProvider should be able to write everything it has into maintf file",
 			args: args{
@@ -471,7 +541,7 @@ func TestWriteMainTF(t *testing.T) {
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			fs := afero.NewMemMapFs()
-			fp, err := NewFileProducer(context.TODO(), nil, dir, tc.args.tr, tc.args.s, tc.args.cfg, WithFileSystem(fs), WithFileProducerFeatures(tc.args.f))
+			fp, err := NewFileProducer(context.TODO(), nil, dir, tc.args.tr, tc.args.s, tc.args.cfg, WithFileSystem(fs), WithFileProducerFeatures(tc.args.f), WithFileProducerCLI(tc.args.cli), WithFileProducerStateBackend(tc.args.sb))
 			if err != nil {
 				t.Errorf("cannot initialize a file producer: %s", err.Error())
 			}
@@ -7,6 +7,7 @@ package terraform
 import (
 	"context"
 
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/pkg/errors"
 )
@@ -22,12 +23,37 @@ type StoreCleaner interface {
 
 // TODO(muvaf): A FinalizerChain in crossplane-runtime?
 
+// WorkspaceFinalizerOption configures a WorkspaceFinalizer.
+type WorkspaceFinalizerOption func(wf *WorkspaceFinalizer)
+
+// WithFinalizerStateExporter configures the StateExporter the Terraform
+// states of the orphaned managed resources are exported with before their
+// workspaces are removed.
+func WithFinalizerStateExporter(e StateExporter) WorkspaceFinalizerOption {
+	return func(wf *WorkspaceFinalizer) {
+		wf.StateExporter = e
+	}
+}
+
+// WithFinalizerLogger configures the logger the failures to export
+// the Terraform states of the orphaned managed resources are logged with.
+func WithFinalizerLogger(l logging.Logger) WorkspaceFinalizerOption {
+	return func(wf *WorkspaceFinalizer) {
+		wf.logger = l
+	}
+}
+
 // NewWorkspaceFinalizer returns a new WorkspaceFinalizer.
-func NewWorkspaceFinalizer(ws StoreCleaner, af xpresource.Finalizer) *WorkspaceFinalizer {
-	return &WorkspaceFinalizer{
+func NewWorkspaceFinalizer(ws StoreCleaner, af xpresource.Finalizer, opts ...WorkspaceFinalizerOption) *WorkspaceFinalizer {
+	wf := &WorkspaceFinalizer{
 		Finalizer: af,
 		Store:     ws,
+		logger:    logging.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(wf)
 	}
+	return wf
 }
 
 // WorkspaceFinalizer removes the workspace from the workspace store and only
@@ -35,6 +61,10 @@ func NewWorkspaceFinalizer(ws StoreCleaner, af xpresource.Finalizer) *WorkspaceF
 type WorkspaceFinalizer struct {
 	xpresource.Finalizer
 	Store StoreCleaner
+	// StateExporter, if set, exports the Terraform states of the orphaned
+	// managed resources.
+	StateExporter StateExporter
+	logger        logging.Logger
 }
 
 // AddFinalizer to the supplied Managed resource.
@@ -45,6 +75,7 @@ func (wf *WorkspaceFinalizer) AddFinalizer(ctx context.Context, obj xpresource.O
 // RemoveFinalizer removes the workspace from workspace store before removing
 // the finalizer.
 func (wf *WorkspaceFinalizer) RemoveFinalizer(ctx context.Context, obj xpresource.Object) error {
+	TryExportOrphanedState(ctx, wf.StateExporter, obj, wf.logger)
 	if err := wf.Store.Remove(obj); err != nil {
 		return errors.Wrap(err, errRemoveWorkspace)
 	}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"testing"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
@@ -85,6 +86,7 @@ func TestRemoveFinalizer(t *testing.T) {
 	type args struct {
 		finalizer xpresource.Finalizer
 		store     StoreCleaner
+		exporter  StateExporter
 		obj       xpresource.Object
 	}
 	type want struct {
@@ -109,6 +111,25 @@ func TestRemoveFinalizer(t *testing.T) {
 				},
 			},
 		},
+		"ExportFails": {
+			reason: "The failures to export the state of an orphaned resource should not block the finalizer removal.",
+			args: args{
+				store: &StoreFns{
+					RemoveFn: func(_ xpresource.Object) error {
+						return nil
+					},
+				},
+				finalizer: xpresource.FinalizerFns{
+					RemoveFinalizerFn: func(_ context.Context, _ xpresource.Object) error {
+						return nil
+					},
+				},
+				exporter: StateExporterFn(func(_ context.Context, _ resource.Terraformed) error {
+					return errBoom
+				}),
+				obj: newOrphanedResource(xpv1.DeletionOrphan),
+			},
+		},
 		"StoreRemovalFails": {
 			args: args{
 				store: &StoreFns{
@@ -141,7 +162,7 @@ func TestRemoveFinalizer(t *testing.T) {
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			f := NewWorkspaceFinalizer(tc.args.store, tc.args.finalizer)
+			f := NewWorkspaceFinalizer(tc.args.store, tc.args.finalizer, WithFinalizerStateExporter(tc.args.exporter))
 			err := f.RemoveFinalizer(context.TODO(), tc.args.obj)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nRemoveFinalizer(...): -want error, +got error:\n%s", tc.reason, diff)
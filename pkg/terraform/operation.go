@@ -5,6 +5,7 @@
 package terraform
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -13,10 +14,13 @@ import (
 type Operation struct {
 	Type string
 
-	startTime *time.Time
-	endTime   *time.Time
-	err       error
-	mu        sync.RWMutex
+	startTime  *time.Time
+	endTime    *time.Time
+	err        error
+	cancel     context.CancelFunc
+	canceled   bool
+	generation int64
+	mu         sync.RWMutex
 }
 
 // MarkStart marks the operation as started atomically after checking
@@ -32,6 +36,9 @@ func (o *Operation) MarkStart(t string) bool {
 	o.Type = t
 	o.startTime = &now
 	o.endTime = nil
+	o.cancel = nil
+	o.canceled = false
+	o.generation = 0
 	return true
 }
 
@@ -51,6 +58,9 @@ func (o *Operation) Flush() {
 	o.startTime = nil
 	o.endTime = nil
 	o.err = nil
+	o.cancel = nil
+	o.canceled = false
+	o.generation = 0
 }
 
 // IsEnded returns whether the operation has ended, regardless of its result.
@@ -94,3 +104,52 @@ func (o *Operation) Error() error {
 	defer o.mu.RUnlock()
 	return o.err
 }
+
+// WithCancel returns a copy of the parent context that is canceled when
+// the current operation is canceled with Cancel. The returned cancel
+// function should be called once the operation is done to release the
+// associated resources.
+func (o *Operation) WithCancel(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cancel = cancel
+	return ctx, cancel
+}
+
+// Cancel cancels the context of the running operation, if any.
+// Returns `true` if a running operation has been canceled.
+func (o *Operation) Cancel() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.cancel == nil || o.startTime == nil || o.endTime != nil {
+		return false
+	}
+	o.cancel()
+	o.canceled = true
+	return true
+}
+
+// IsCanceled returns whether the current operation has been canceled.
+func (o *Operation) IsCanceled() bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.canceled
+}
+
+// SetGeneration records the generation of the managed resource the current
+// operation has been started for. An operation with a zero generation is
+// not bound to a specific generation of its managed resource.
+func (o *Operation) SetGeneration(g int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.generation = g
+}
+
+// Generation returns the generation of the managed resource the current
+// operation has been started for.
+func (o *Operation) Generation() int64 {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.generation
+}
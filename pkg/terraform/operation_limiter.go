@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	"sync"
+
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/upjet/pkg/metrics"
+)
+
+// OperationKey identifies the concurrency limits an asynchronous
+// operation is subject to.
+type OperationKey struct {
+	// GVK is the GroupVersionKind of the managed resource the operation
+	// is run for.
+	GVK schema.GroupVersionKind
+	// ProviderConfig is the name of the ProviderConfig of the managed
+	// resource the operation is run for.
+	ProviderConfig string
+}
+
+// NewOperationKey returns the OperationKey for the asynchronous operations
+// of the specified managed resource.
+func NewOperationKey(mg xpresource.Managed) OperationKey {
+	k := OperationKey{
+		GVK: mg.GetObjectKind().GroupVersionKind(),
+	}
+	if ref := mg.GetProviderConfigReference(); ref != nil {
+		k.ProviderConfig = ref.Name
+	}
+	return k
+}
+
+// OperationLimiterOption lets you configure an OperationLimiter.
+type OperationLimiterOption func(l *OperationLimiter)
+
+// WithKindLimit configures the maximum number of concurrently running
+// asynchronous operations per managed resource kind. A non-positive
+// limit means no limit.
+func WithKindLimit(n int) OperationLimiterOption {
+	return func(l *OperationLimiter) {
+		l.kindLimit = n
+	}
+}
+
+// WithProviderConfigLimit configures the maximum number of concurrently
+// running asynchronous operations per ProviderConfig across all managed
+// resource kinds. A non-positive limit means no limit.
+func WithProviderConfigLimit(n int) OperationLimiterOption {
+	return func(l *OperationLimiter) {
+		l.providerConfigLimit = n
+	}
+}
+
+// OperationLimiter bounds the number of concurrently running asynchronous
+// operations per managed resource kind and per ProviderConfig, so that
+// a burst of operations for a kind or for a tenant (ProviderConfig) cannot
+// exhaust the quotas and the API rate limits of the external APIs, or
+// starve the operations of the other kinds and tenants. The operations
+// exceeding the limits are queued until a running operation completes.
+// A nil OperationLimiter does not impose any limits.
+type OperationLimiter struct {
+	kindLimit           int
+	providerConfigLimit int
+
+	mu              sync.Mutex
+	kinds           map[schema.GroupVersionKind]chan struct{}
+	providerConfigs map[string]*providerConfigSemaphore
+}
+
+// providerConfigSemaphore is the semaphore of a ProviderConfig together
+// with the number of the operations queued for or holding it, so that
+// the semaphores of the ProviderConfigs without any operations can be
+// pruned.
+type providerConfigSemaphore struct {
+	sem  chan struct{}
+	refs int
+}
+
+// NewOperationLimiter returns a new OperationLimiter.
+func NewOperationLimiter(opts ...OperationLimiterOption) *OperationLimiter {
+	l := &OperationLimiter{
+		kinds:           make(map[schema.GroupVersionKind]chan struct{}),
+		providerConfigs: make(map[string]*providerConfigSemaphore),
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return l
+}
+
+func (l *OperationLimiter) semaphores(k OperationKey) (providerConfig, kind chan struct{}, ok bool) {
+	if l.providerConfigLimit <= 0 && l.kindLimit <= 0 {
+		return nil, nil, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	pc := l.providerConfigs[k.ProviderConfig]
+	if pc == nil {
+		pc = &providerConfigSemaphore{}
+		if l.providerConfigLimit > 0 {
+			pc.sem = make(chan struct{}, l.providerConfigLimit)
+		}
+		l.providerConfigs[k.ProviderConfig] = pc
+	}
+	pc.refs++
+	if l.kindLimit > 0 {
+		kind = l.kinds[k.GVK]
+		if kind == nil {
+			kind = make(chan struct{}, l.kindLimit)
+			l.kinds[k.GVK] = kind
+		}
+	}
+	return pc.sem, kind, true
+}
+
+// unref drops a reference to the semaphore of the specified ProviderConfig
+// and prunes the semaphore and the queued operations metrics of
+// the ProviderConfig once no operation is queued for or holding it.
+func (l *OperationLimiter) unref(providerConfig string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	pc := l.providerConfigs[providerConfig]
+	if pc == nil {
+		return
+	}
+	if pc.refs--; pc.refs > 0 {
+		return
+	}
+	delete(l.providerConfigs, providerConfig)
+	metrics.QueuedOperations.DeletePartialMatch(prometheus.Labels{"provider_config": providerConfig})
+}
+
+// Acquire blocks until the asynchronous operation with the specified key
+// can run without exceeding the configured limits, or the supplied context
+// is done. On success, it returns a function that must be called to release
+// the acquired slots once the operation completes.
+func (l *OperationLimiter) Acquire(ctx context.Context, k OperationKey) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	providerConfig, kind, ok := l.semaphores(k)
+	if !ok {
+		return func() {}, nil
+	}
+	releasePC, releaseKind, err := l.acquire(ctx, k, providerConfig, kind)
+	if err != nil {
+		l.unref(k.ProviderConfig)
+		return nil, err
+	}
+	return func() {
+		releaseKind()
+		releasePC()
+		l.unref(k.ProviderConfig)
+	}, nil
+}
+
+func (l *OperationLimiter) acquire(ctx context.Context, k OperationKey, providerConfig, kind chan struct{}) (releasePC, releaseKind func(), err error) {
+	queued := metrics.QueuedOperations.WithLabelValues(k.GVK.Group, k.GVK.Version, k.GVK.Kind, k.ProviderConfig)
+	queued.Inc()
+	defer queued.Dec()
+	// The ProviderConfig slot is acquired before the kind slot so that
+	// the operations of a tenant waiting for its own limit do not hold
+	// the slots of a kind shared with the other tenants.
+	releasePC, err = acquire(ctx, providerConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	releaseKind, err = acquire(ctx, kind)
+	if err != nil {
+		releasePC()
+		return nil, nil, err
+	}
+	return releasePC, releaseKind, nil
+}
+
+func acquire(ctx context.Context, sem chan struct{}) (func(), error) {
+	if sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestOperationLimiterAcquire(t *testing.T) {
+	gvkA := schema.GroupVersionKind{Group: "a.upbound.io", Version: "v1beta1", Kind: "A"}
+	gvkB := schema.GroupVersionKind{Group: "b.upbound.io", Version: "v1beta1", Kind: "B"}
+	type args struct {
+		opts []OperationLimiterOption
+		// running are the keys of the operations already running
+		running []OperationKey
+		key     OperationKey
+	}
+	type want struct {
+		err error
+	}
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"NoLimits": {
+			args: args{
+				running: []OperationKey{{GVK: gvkA, ProviderConfig: "default"}},
+				key:     OperationKey{GVK: gvkA, ProviderConfig: "default"},
+			},
+		},
+		"KindLimitReached": {
+			args: args{
+				opts:    []OperationLimiterOption{WithKindLimit(1)},
+				running: []OperationKey{{GVK: gvkA, ProviderConfig: "default"}},
+				key:     OperationKey{GVK: gvkA, ProviderConfig: "other"},
+			},
+			want: want{
+				err: context.DeadlineExceeded,
+			},
+		},
+		"KindLimitOfOtherKind": {
+			args: args{
+				opts:    []OperationLimiterOption{WithKindLimit(1)},
+				running: []OperationKey{{GVK: gvkA, ProviderConfig: "default"}},
+				key:     OperationKey{GVK: gvkB, ProviderConfig: "default"},
+			},
+		},
+		"ProviderConfigLimitReached": {
+			args: args{
+				opts:    []OperationLimiterOption{WithProviderConfigLimit(1)},
+				running: []OperationKey{{GVK: gvkA, ProviderConfig: "default"}},
+				key:     OperationKey{GVK: gvkB, ProviderConfig: "default"},
+			},
+			want: want{
+				err: context.DeadlineExceeded,
+			},
+		},
+		"ProviderConfigLimitOfOtherProviderConfig": {
+			args: args{
+				opts:    []OperationLimiterOption{WithProviderConfigLimit(1), WithKindLimit(2)},
+				running: []OperationKey{{GVK: gvkA, ProviderConfig: "default"}},
+				key:     OperationKey{GVK: gvkA, ProviderConfig: "other"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := NewOperationLimiter(tc.args.opts...)
+			for _, k := range tc.args.running {
+				if _, err := l.Acquire(context.Background(), k); err != nil {
+					t.Fatalf("\n%s\nAcquire(...): unexpected error: %v", name, err)
+				}
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			_, err := l.Acquire(ctx, tc.args.key)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAcquire(...): -want error, +got error:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestOperationLimiterRelease(t *testing.T) {
+	k := OperationKey{ProviderConfig: "default"}
+	l := NewOperationLimiter(WithProviderConfigLimit(1))
+	release, err := l.Acquire(context.Background(), k)
+	if err != nil {
+		t.Fatalf("Acquire(...): unexpected error: %v", err)
+	}
+	release()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := l.Acquire(ctx, k); err != nil {
+		t.Errorf("Acquire(...): unexpected error after release: %v", err)
+	}
+}
+
+func TestOperationLimiterPrune(t *testing.T) {
+	l := NewOperationLimiter(WithProviderConfigLimit(1))
+	running := OperationKey{ProviderConfig: "default"}
+	release, err := l.Acquire(context.Background(), running)
+	if err != nil {
+		t.Fatalf("Acquire(...): unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx, running); err == nil {
+		t.Fatalf("Acquire(...): want an error while the limit is reached")
+	}
+	if diff := cmp.Diff(1, len(l.providerConfigs)); diff != "" {
+		t.Errorf("Acquire(...): the semaphore of a ProviderConfig with a running operation should be kept: -want, +got:\n%s", diff)
+	}
+	release()
+	if diff := cmp.Diff(0, len(l.providerConfigs)); diff != "" {
+		t.Errorf("release(): the semaphore of an idle ProviderConfig should be pruned: -want, +got:\n%s", diff)
+	}
+}
@@ -5,6 +5,7 @@
 package terraform
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -65,6 +66,37 @@ func TestOperation(t *testing.T) {
 				result: true,
 			},
 		},
+		"Canceled": {
+			args: args{
+				calls: func(o *Operation) {
+					o.MarkStart("type")
+					o.SetGeneration(2)
+					o.WithCancel(context.Background())
+					o.Cancel()
+				},
+			},
+			want: want{
+				checks: func(o *Operation) bool {
+					return o.IsRunning() && o.IsCanceled() && o.Generation() == 2
+				},
+				result: true,
+			},
+		},
+		"CancelEnded": {
+			args: args{
+				calls: func(o *Operation) {
+					o.MarkStart("type")
+					o.WithCancel(context.Background())
+					o.MarkEnd()
+				},
+			},
+			want: want{
+				checks: func(o *Operation) bool {
+					return !o.Cancel() && !o.IsCanceled()
+				},
+				result: true,
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
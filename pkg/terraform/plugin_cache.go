@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	envCLIConfigFile = "TF_CLI_CONFIG_FILE"
+	cliConfigFile    = "upjet.tfrc"
+	lockFile         = ".terraform.lock.hcl"
+	// initMarkerFile is written into a workspace once it's successfully
+	// initialized with a plugin cache.
+	initMarkerFile = ".upjet-initialized"
+)
+
+// PluginCache configures how the providers are installed into
+// the workspaces, so that the provider binaries are shared among
+// the workspaces instead of being downloaded or copied into each of them.
+type PluginCache struct {
+	// Dir is the shared plugin cache directory the providers are installed
+	// into once and then linked into the workspaces from. Not used if empty.
+	Dir string
+
+	// MirrorDir is a read-only directory holding the provider packages in
+	// the layout of a Terraform filesystem mirror, e.g., a volume mounted
+	// into the provider pod. If set, the providers found in the mirror are
+	// installed from the mirror instead of the registry.
+	MirrorDir string
+
+	// Offline disables the installation of the providers from the registry,
+	// i.e., the providers can be installed only from the MirrorDir.
+	Offline bool
+}
+
+// cliConfig returns the CLI configuration file content for the plugin
+// cache.
+func (pc PluginCache) cliConfig() string {
+	b := &strings.Builder{}
+	if pc.Dir != "" {
+		fmt.Fprintf(b, "plugin_cache_dir = %q\n", pc.Dir)
+		// the checksums of the providers installed from the cache are
+		// recorded in the lock files generated by the WorkspaceStore.
+		b.WriteString("plugin_cache_may_break_dependency_lock_file = true\n")
+	}
+	b.WriteString("provider_installation {\n")
+	if pc.MirrorDir != "" {
+		fmt.Fprintf(b, "  filesystem_mirror {\n    path = %q\n  }\n", pc.MirrorDir)
+	}
+	if !pc.Offline {
+		b.WriteString("  direct {}\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// WithPluginCache configures the shared plugin cache of the workspaces.
+// The Terraform CLI configuration for the cache is generated and passed to
+// the CLI, and the lock file of the first workspace initialized for
+// a provider version is reused by the subsequently initialized workspaces
+// of the same provider version, so that the workspaces can be initialized
+// without accessing the registry. The workspaces of a provider version are
+// initialized one at a time, as the plugin cache is not safe for concurrent
+// use by the Terraform CLI.
+func WithPluginCache(pc PluginCache) WorkspaceStoreOption {
+	return func(ws *WorkspaceStore) {
+		ws.pluginCache = &pc
+	}
+}
+
+// cliConfigEnv writes the CLI configuration file of the plugin cache, if
+// it has not been written yet, and returns the environment variables
+// the CLI is to be run with for the plugin cache.
+func (ws *WorkspaceStore) cliConfigEnv() ([]string, error) {
+	if ws.pluginCache == nil {
+		return nil, nil
+	}
+	ws.cliConfigOnce.Do(func() {
+		if ws.pluginCache.Dir != "" {
+			if err := ws.fs.MkdirAll(ws.pluginCache.Dir, os.ModePerm); err != nil {
+				ws.cliConfigErr = errors.Wrap(err, "cannot create the plugin cache directory")
+				return
+			}
+		}
+		ws.cliConfigPath = filepath.Join(ws.fs.GetTempDir(""), cliConfigFile)
+		ws.cliConfigErr = errors.Wrap(ws.fs.WriteFile(ws.cliConfigPath, []byte(ws.pluginCache.cliConfig()), 0600), "cannot write the CLI configuration file")
+	})
+	if ws.cliConfigErr != nil {
+		return nil, ws.cliConfigErr
+	}
+	return []string{fmt.Sprintf(fmtEnv, envCLIConfigFile, ws.cliConfigPath)}, nil
+}
+
+// isInitialized returns whether the workspace in the specified directory
+// has been initialized. As the lock files of the workspaces are generated
+// before they are initialized when a plugin cache is configured, the marker
+// file written by markInitialized is checked instead of the lock file in
+// that case. The .terraform directory is not a reliable marker because
+// it's created before the providers are installed, e.g., by an interrupted
+// or a failed init.
+func (ws *WorkspaceStore) isInitialized(dir string) (bool, error) {
+	marker := lockFile
+	if ws.pluginCache != nil {
+		marker = initMarkerFile
+	}
+	_, err := ws.fs.Stat(filepath.Join(dir, marker))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, errors.Wrap(err, "cannot stat init lock file")
+}
+
+// markInitialized records that the workspace in the specified directory
+// has been successfully initialized. Must be called only after the init
+// succeeds.
+func (ws *WorkspaceStore) markInitialized(dir string) error {
+	if ws.pluginCache == nil {
+		return nil
+	}
+	return errors.Wrap(ws.fs.WriteFile(filepath.Join(dir, initMarkerFile), nil, 0600), "cannot write the init marker file")
+}
+
+func lockFileKey(r ProviderRequirement) string {
+	return r.Source + "@" + r.Version
+}
+
+// lockInit locks the initialization of the workspaces of the specified
+// provider requirement if a plugin cache is configured, and returns
+// the function that unlocks it.
+func (ws *WorkspaceStore) lockInit(r ProviderRequirement) func() {
+	if ws.pluginCache == nil {
+		return func() {}
+	}
+	k := lockFileKey(r)
+	ws.lockFilesMu.Lock()
+	mu, ok := ws.initMus[k]
+	if !ok {
+		mu = &sync.Mutex{}
+		ws.initMus[k] = mu
+	}
+	ws.lockFilesMu.Unlock()
+	mu.Lock()
+	return mu.Unlock
+}
+
+// seedLockFile writes the lock file recorded for the specified provider
+// requirement, if any, into the workspace in the specified directory.
+func (ws *WorkspaceStore) seedLockFile(dir string, r ProviderRequirement) error {
+	if ws.pluginCache == nil {
+		return nil
+	}
+	ws.lockFilesMu.RLock()
+	data, ok := ws.lockFiles[lockFileKey(r)]
+	ws.lockFilesMu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return errors.Wrap(ws.fs.WriteFile(filepath.Join(dir, lockFile), data, 0600), "cannot write the lock file")
+}
+
+// recordLockFile records the lock file of the workspace in the specified
+// directory for the specified provider requirement.
+func (ws *WorkspaceStore) recordLockFile(dir string, r ProviderRequirement) error {
+	if ws.pluginCache == nil {
+		return nil
+	}
+	data, err := ws.fs.ReadFile(filepath.Join(dir, lockFile))
+	if err != nil {
+		return errors.Wrap(err, "cannot read the lock file")
+	}
+	ws.lockFilesMu.Lock()
+	defer ws.lockFilesMu.Unlock()
+	ws.lockFiles[lockFileKey(r)] = data
+	return nil
+}
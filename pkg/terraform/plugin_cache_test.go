@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+func TestPluginCacheCLIConfig(t *testing.T) {
+	cases := map[string]struct {
+		pc   PluginCache
+		want string
+	}{
+		"CacheOnly": {
+			pc: PluginCache{Dir: "/plugins"},
+			want: `plugin_cache_dir = "/plugins"
+plugin_cache_may_break_dependency_lock_file = true
+provider_installation {
+  direct {}
+}
+`,
+		},
+		"Mirror": {
+			pc: PluginCache{Dir: "/plugins", MirrorDir: "/mirror"},
+			want: `plugin_cache_dir = "/plugins"
+plugin_cache_may_break_dependency_lock_file = true
+provider_installation {
+  filesystem_mirror {
+    path = "/mirror"
+  }
+  direct {}
+}
+`,
+		},
+		"Offline": {
+			pc: PluginCache{MirrorDir: "/mirror", Offline: true},
+			want: `provider_installation {
+  filesystem_mirror {
+    path = "/mirror"
+  }
+}
+`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.pc.cliConfig()); diff != "" {
+				t.Errorf("\n%s\ncliConfig(): -want, +got:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestWorkspaceStoreLockFiles(t *testing.T) {
+	ws := NewWorkspaceStore(logging.NewNopLogger(), WithFs(afero.NewMemMapFs()), WithPluginCache(PluginCache{Dir: "/plugins"}))
+	env, err := ws.cliConfigEnv()
+	if err != nil {
+		t.Fatalf("cliConfigEnv(): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{envCLIConfigFile + "=" + ws.cliConfigPath}, env); diff != "" {
+		t.Errorf("cliConfigEnv(): -want, +got:\n%s", diff)
+	}
+	r := ProviderRequirement{Source: "hashicorp/aws", Version: "5.0.0"}
+	if err := ws.fs.WriteFile(filepath.Join("/first", lockFile), []byte("lock"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.recordLockFile("/first", r); err != nil {
+		t.Fatalf("recordLockFile(...): unexpected error: %v", err)
+	}
+	if err := ws.seedLockFile("/second", r); err != nil {
+		t.Fatalf("seedLockFile(...): unexpected error: %v", err)
+	}
+	data, err := ws.fs.ReadFile(filepath.Join("/second", lockFile))
+	if err != nil {
+		t.Fatalf("seedLockFile(...): lock file is not written: %v", err)
+	}
+	if diff := cmp.Diff("lock", string(data)); diff != "" {
+		t.Errorf("seedLockFile(...): -want, +got:\n%s", diff)
+	}
+	initialized, err := ws.isInitialized("/second")
+	if err != nil || initialized {
+		t.Errorf("isInitialized(...): want an uninitialized workspace with a seeded lock file, got %v, %v", initialized, err)
+	}
+	// an interrupted or a failed init may leave a .terraform directory
+	// behind.
+	if err := ws.fs.MkdirAll(filepath.Join("/second", ".terraform"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	initialized, err = ws.isInitialized("/second")
+	if err != nil || initialized {
+		t.Errorf("isInitialized(...): want an uninitialized workspace with a .terraform directory, got %v, %v", initialized, err)
+	}
+	if err := ws.markInitialized("/second"); err != nil {
+		t.Fatalf("markInitialized(...): unexpected error: %v", err)
+	}
+	initialized, err = ws.isInitialized("/second")
+	if err != nil || !initialized {
+		t.Errorf("isInitialized(...): want an initialized workspace, got %v, %v", initialized, err)
+	}
+}
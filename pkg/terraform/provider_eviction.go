@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	evictionReasonMaxRSS  = "resident memory limit exceeded"
+	evictionReasonMaxAge  = "maximum age exceeded"
+	evictionReasonMaxIdle = "maximum idle time exceeded"
+)
+
+// EvictionPolicy configures when the SharedProviderScheduler replaces
+// a shared native provider process, in addition to its TTL. A zero value
+// disables the respective criterion.
+type EvictionPolicy struct {
+	// MaxRSS is the maximum resident set size, in bytes, of a native
+	// provider process.
+	MaxRSS uint64
+	// MaxAge is the maximum duration a native provider process is used for.
+	MaxAge time.Duration
+	// MaxIdle is the maximum duration a native provider process is kept
+	// running while it's not in use.
+	MaxIdle time.Duration
+}
+
+// WithEvictionPolicy configures the SharedProviderScheduler to evict
+// the native provider processes according to the specified policy, which is
+// evaluated at the specified interval until the specified context is done.
+// The evicted processes are stopped once their in-flight operations drain,
+// and the callers scheduling them are asked to retry until then.
+// A non-positive interval disables the evictions.
+func WithEvictionPolicy(ctx context.Context, p EvictionPolicy, interval time.Duration) SharedProviderSchedulerOption {
+	return func(scheduler *SharedProviderScheduler) {
+		scheduler.evictionCtx = ctx
+		scheduler.evictionPolicy = p
+		scheduler.evictionInterval = interval
+	}
+}
+
+// pidReporter is implemented by the ProviderRunners that can report
+// the process ID of their native provider.
+type pidReporter interface {
+	PID() int
+}
+
+func (s *SharedProviderScheduler) evictLoop() {
+	t := time.NewTicker(s.evictionInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.evictionCtx.Done():
+			return
+		case <-t.C:
+			s.evict()
+		}
+	}
+}
+
+// evict marks the native provider processes violating the eviction policy
+// as evicted and stops the evicted ones that are not in use.
+func (s *SharedProviderScheduler) evict() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.reportRunners()
+	for h, pool := range s.runners {
+		remaining := pool[:0]
+		for _, r := range pool {
+			if r.evictionReason == "" {
+				r.evictionReason = s.evictionReason(r)
+			}
+			if r.evictionReason == "" || r.inUse > 0 {
+				remaining = append(remaining, r)
+				continue
+			}
+			s.logger.Debug("Evicting the provider runner", "handle", h, "reason", r.evictionReason, "invocationCount", r.invocationCount)
+			if err := r.Stop(); err != nil {
+				s.logger.Debug("Failed to stop the evicted provider runner", "handle", h, "error", err)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(s.runners, h)
+			continue
+		}
+		s.runners[h] = remaining
+	}
+}
+
+// evictionReason returns why the specified entry violates the eviction
+// policy, or an empty string if it does not. Must be called with the lock
+// held.
+func (s *SharedProviderScheduler) evictionReason(r *schedulerEntry) string {
+	now := s.now()
+	p := s.evictionPolicy
+	switch {
+	case p.MaxAge > 0 && now.Sub(r.startedAt) >= p.MaxAge:
+		return evictionReasonMaxAge
+	case p.MaxIdle > 0 && r.inUse == 0 && now.Sub(r.lastUsed) >= p.MaxIdle:
+		return evictionReasonMaxIdle
+	case p.MaxRSS > 0 && r.pid > 0:
+		rss, err := s.rssFn(r.pid)
+		if err != nil {
+			s.logger.Debug("Failed to get the resident memory of the native provider", "pid", r.pid, "error", err)
+			return ""
+		}
+		if rss >= p.MaxRSS {
+			return evictionReasonMaxRSS
+		}
+	}
+	return ""
+}
+
+// processRSS returns the resident set size, in bytes, of the process with
+// the specified ID.
+func processRSS(pid int) (uint64, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot open the process status file")
+	}
+	defer f.Close() //nolint:errcheck
+	return parseRSS(f.Name(), bufio.NewScanner(f))
+}
+
+func parseRSS(name string, s *bufio.Scanner) (uint64, error) {
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) != 3 || fields[0] != "VmRSS:" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		return kb * 1024, errors.Wrapf(err, "cannot parse the resident set size in %s", name)
+	}
+	if err := s.Err(); err != nil {
+		return 0, errors.Wrapf(err, "cannot read %s", name)
+	}
+	return 0, errors.Errorf("cannot find the resident set size in %s", name)
+}
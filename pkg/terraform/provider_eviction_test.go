@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"bufio"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
+)
+
+type fakeRunner struct {
+	pid     int
+	stopped bool
+}
+
+func (r *fakeRunner) Start() (string, error) {
+	return "reattach", nil
+}
+
+func (r *fakeRunner) Stop() error {
+	r.stopped = true
+	return nil
+}
+
+func (r *fakeRunner) PID() int {
+	return r.pid
+}
+
+func TestEvict(t *testing.T) {
+	now := time.Unix(1000, 0)
+	type args struct {
+		policy EvictionPolicy
+		entry  schedulerEntry
+		rssFn  func(pid int) (uint64, error)
+	}
+	type want struct {
+		evictionReason string
+		stopped        bool
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"NoPolicy": {
+			args: args{
+				entry: schedulerEntry{startedAt: time.Unix(0, 0), lastUsed: time.Unix(0, 0)},
+			},
+		},
+		"MaxAgeExceeded": {
+			args: args{
+				policy: EvictionPolicy{MaxAge: time.Minute},
+				entry:  schedulerEntry{startedAt: now.Add(-time.Hour), lastUsed: now},
+			},
+			want: want{
+				evictionReason: evictionReasonMaxAge,
+				stopped:        true,
+			},
+		},
+		"MaxAgeExceededInUse": {
+			args: args{
+				policy: EvictionPolicy{MaxAge: time.Minute},
+				entry:  schedulerEntry{startedAt: now.Add(-time.Hour), lastUsed: now, inUse: 1},
+			},
+			want: want{
+				evictionReason: evictionReasonMaxAge,
+			},
+		},
+		"MaxIdleExceeded": {
+			args: args{
+				policy: EvictionPolicy{MaxIdle: time.Minute},
+				entry:  schedulerEntry{startedAt: now.Add(-time.Hour), lastUsed: now.Add(-2 * time.Minute)},
+			},
+			want: want{
+				evictionReason: evictionReasonMaxIdle,
+				stopped:        true,
+			},
+		},
+		"NotIdle": {
+			args: args{
+				policy: EvictionPolicy{MaxIdle: time.Minute},
+				entry:  schedulerEntry{startedAt: now.Add(-time.Hour), lastUsed: now.Add(-time.Second)},
+			},
+		},
+		"MaxRSSExceeded": {
+			args: args{
+				policy: EvictionPolicy{MaxRSS: 1 << 30},
+				entry:  schedulerEntry{pid: 42, startedAt: now, lastUsed: now},
+				rssFn: func(int) (uint64, error) {
+					return 2 << 30, nil
+				},
+			},
+			want: want{
+				evictionReason: evictionReasonMaxRSS,
+				stopped:        true,
+			},
+		},
+		"RSSUnknown": {
+			args: args{
+				policy: EvictionPolicy{MaxRSS: 1 << 30},
+				entry:  schedulerEntry{pid: 42, startedAt: now, lastUsed: now},
+				rssFn: func(int) (uint64, error) {
+					return 0, errBoom
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &fakeRunner{}
+			e := tc.args.entry
+			e.ProviderRunner = r
+			s := &SharedProviderScheduler{
+				runners:        map[ProviderHandle][]*schedulerEntry{"handle": {&e}},
+				mu:             &sync.Mutex{},
+				logger:         logging.NewNopLogger(),
+				evictionPolicy: tc.args.policy,
+				now:            func() time.Time { return now },
+				rssFn:          tc.args.rssFn,
+			}
+			s.evict()
+			if diff := cmp.Diff(tc.want.evictionReason, e.evictionReason); diff != "" {
+				t.Errorf("\n%s\nevict(): -want evictionReason, +got evictionReason:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.stopped, r.stopped); diff != "" {
+				t.Errorf("\n%s\nevict(): -want stopped, +got stopped:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(!tc.want.stopped, len(s.runners["handle"]) == 1); diff != "" {
+				t.Errorf("\n%s\nevict(): -want scheduled, +got scheduled:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestSharedProviderSchedulerStartDraining(t *testing.T) {
+	now := time.Unix(1000, 0)
+	type args struct {
+		entry schedulerEntry
+	}
+	type want struct {
+		reattachConfig string
+		err            error
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"Reused": {
+			args: args{
+				entry: schedulerEntry{invocationCount: 1},
+			},
+			want: want{
+				reattachConfig: "reattach",
+			},
+		},
+		"Draining": {
+			args: args{
+				entry: schedulerEntry{inUse: 1, evictionReason: evictionReasonMaxRSS},
+			},
+			want: want{
+				err: tferrors.NewDrainingScheduleError(evictionReasonMaxRSS),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := tc.args.entry
+			e.ProviderRunner = &fakeRunner{pid: 42}
+			s := &SharedProviderScheduler{
+				runners:  map[ProviderHandle][]*schedulerEntry{"handle": {&e}},
+				mu:       &sync.Mutex{},
+				logger:   logging.NewNopLogger(),
+				poolSize: 1,
+				ttl:      100,
+				now:      func() time.Time { return now },
+			}
+			_, rc, err := s.Start("handle")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nStart(...): -want error, +got error:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.reattachConfig, rc); diff != "" {
+				t.Errorf("\n%s\nStart(...): -want reattachConfig, +got reattachConfig:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestParseRSS(t *testing.T) {
+	type want struct {
+		rss uint64
+		err error
+	}
+	cases := map[string]struct {
+		status string
+		want   want
+	}{
+		"Success": {
+			status: "Name:\tterraform-provi\nVmPeak:\t 2048 kB\nVmRSS:\t 1024 kB\nThreads:\t12\n",
+			want: want{
+				rss: 1024 * 1024,
+			},
+		},
+		"NotFound": {
+			status: "Name:\tterraform-provi\n",
+			want: want{
+				err: errors.New("cannot find the resident set size in status"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rss, err := parseRSS("status", bufio.NewScanner(strings.NewReader(tc.status)))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nparseRSS(...): -want error, +got error:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.rss, rss); diff != "" {
+				t.Errorf("\n%s\nparseRSS(...): -want rss, +got rss:\n%s", name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultProviderMetaPoolSize = 100
+
+	errProviderMetaPoolHandle = "cannot compute the handle of the provider configuration"
+	errConfigureProviderMeta  = "cannot configure the Terraform provider meta"
+)
+
+// ConfigureProviderMetaFn configures a Terraform provider with the supplied
+// configuration and returns its meta, i.e., the configured SDK client to be
+// set as Setup.Meta.
+type ConfigureProviderMetaFn func(ctx context.Context, pc ProviderConfiguration) (any, error)
+
+// ProviderMetaPoolOption lets you configure a ProviderMetaPool.
+type ProviderMetaPoolOption func(p *ProviderMetaPool)
+
+// WithProviderMetaPoolSize configures the maximum number of configured
+// provider metas kept in the pool. When the limit is reached, the least
+// recently used meta is evicted. A non-positive size means the default size.
+func WithProviderMetaPoolSize(n int) ProviderMetaPoolOption {
+	return func(p *ProviderMetaPool) {
+		if n > 0 {
+			p.size = n
+		}
+	}
+}
+
+type providerMetaEntry struct {
+	handle ProviderHandle
+	// ready is closed once the meta has been configured.
+	ready chan struct{}
+	meta  any
+	err   error
+	// elem is the entry's element in the LRU list of the pool.
+	elem *list.Element
+}
+
+// ProviderMetaPool caches the configured Terraform provider metas per unique
+// ProviderConfiguration, so that the no-fork SetupFns do not configure
+// a fresh SDK client, e.g., a new AWS session, for every reconciliation.
+// The metas are shared among the managed resources with the same provider
+// configuration and must not be modified by the callers.
+type ProviderMetaPool struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[ProviderHandle]*providerMetaEntry
+	// lru holds the ProviderHandles of the entries from the most recently
+	// used to the least recently used one.
+	lru *list.List
+	// owners maps the owners of the provider configurations, such as
+	// ProviderConfigs, to the handles of their current configurations.
+	// An owner is removed when its meta is evicted or when it's released.
+	owners map[string]ProviderHandle
+}
+
+// NewProviderMetaPool returns a new ProviderMetaPool.
+func NewProviderMetaPool(opts ...ProviderMetaPoolOption) *ProviderMetaPool {
+	p := &ProviderMetaPool{
+		size:    defaultProviderMetaPoolSize,
+		entries: make(map[ProviderHandle]*providerMetaEntry),
+		lru:     list.New(),
+		owners:  make(map[string]ProviderHandle),
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// Get returns the provider meta configured with the supplied provider
+// configuration. On a pool miss, the meta is configured using the supplied
+// ConfigureProviderMetaFn and concurrent callers with the same configuration
+// wait for its result. The owner identifies the source of the configuration,
+// e.g., the UID of a ProviderConfig. When the configuration of an owner
+// changes, e.g., because its credentials have been rotated, the meta
+// configured with the previous configuration is evicted unless it's shared
+// with another owner. An empty owner disables this eviction. Deleted owners
+// should be released with Release.
+func (p *ProviderMetaPool) Get(ctx context.Context, owner string, pc ProviderConfiguration, configure ConfigureProviderMetaFn) (any, error) {
+	h, err := pc.ToProviderHandle()
+	if err != nil {
+		return nil, errors.Wrap(err, errProviderMetaPoolHandle)
+	}
+	p.mu.Lock()
+	if owner != "" {
+		prev, ok := p.owners[owner]
+		p.owners[owner] = h
+		if ok && prev != h && !p.isOwned(prev) {
+			p.remove(prev)
+		}
+	}
+	e, ok := p.entries[h]
+	if ok {
+		p.lru.MoveToFront(e.elem)
+		p.mu.Unlock()
+		select {
+		case <-e.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return e.meta, e.err
+	}
+	e = &providerMetaEntry{
+		handle: h,
+		ready:  make(chan struct{}),
+	}
+	e.elem = p.lru.PushFront(h)
+	p.entries[h] = e
+	for p.lru.Len() > p.size {
+		p.remove(p.lru.Back().Value.(ProviderHandle))
+	}
+	p.mu.Unlock()
+
+	e.meta, e.err = configure(ctx, pc)
+	e.err = errors.Wrap(e.err, errConfigureProviderMeta)
+	close(e.ready)
+	if e.err != nil {
+		// failures are not cached so that they are retried
+		p.mu.Lock()
+		if p.entries[h] == e {
+			p.remove(h)
+		}
+		p.mu.Unlock()
+	}
+	return e.meta, e.err
+}
+
+// Release removes the specified owner from the pool, e.g., when
+// the ProviderConfig it identifies is deleted, and evicts the meta configured
+// with its current configuration unless it's shared with another owner.
+func (p *ProviderMetaPool) Release(owner string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h, ok := p.owners[owner]
+	if !ok {
+		return
+	}
+	delete(p.owners, owner)
+	if !p.isOwned(h) {
+		p.remove(h)
+	}
+}
+
+// Len returns the number of provider metas in the pool.
+func (p *ProviderMetaPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.entries)
+}
+
+// isOwned returns whether the specified handle is the current handle of
+// an owner. Must be called with the lock held.
+func (p *ProviderMetaPool) isOwned(h ProviderHandle) bool {
+	for _, oh := range p.owners {
+		if oh == h {
+			return true
+		}
+	}
+	return false
+}
+
+// remove evicts the entry with the specified handle, if any, together with
+// the owners whose current configuration it is. Callers already waiting for
+// the entry still receive its result. Must be called with the lock held.
+func (p *ProviderMetaPool) remove(h ProviderHandle) {
+	e, ok := p.entries[h]
+	if !ok {
+		return
+	}
+	p.lru.Remove(e.elem)
+	delete(p.entries, h)
+	for o, oh := range p.owners {
+		if oh == h {
+			delete(p.owners, o)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+func TestProviderMetaPoolGet(t *testing.T) {
+	errBoom := errors.New("boom")
+	type call struct {
+		owner string
+		token string
+	}
+	type args struct {
+		opts    []ProviderMetaPoolOption
+		calls   []call
+		release []string
+		err     error
+	}
+	type want struct {
+		configured int
+		len        int
+		owners     int
+		err        error
+	}
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"SameConfiguration": {
+			args: args{
+				calls: []call{{owner: "pc", token: "a"}, {owner: "pc", token: "a"}},
+			},
+			want: want{
+				configured: 1,
+				len:        1,
+				owners:     1,
+			},
+		},
+		"CredentialsRotated": {
+			args: args{
+				calls: []call{{owner: "pc", token: "a"}, {owner: "pc", token: "b"}},
+			},
+			want: want{
+				configured: 2,
+				len:        1,
+				owners:     1,
+			},
+		},
+		"SharedConfigurationNotEvicted": {
+			args: args{
+				calls: []call{{owner: "pc1", token: "a"}, {owner: "pc2", token: "a"}, {owner: "pc1", token: "b"}, {owner: "pc2", token: "a"}},
+			},
+			want: want{
+				configured: 2,
+				len:        2,
+				owners:     2,
+			},
+		},
+		"SizeLimit": {
+			args: args{
+				opts:  []ProviderMetaPoolOption{WithProviderMetaPoolSize(1)},
+				calls: []call{{token: "a"}, {token: "b"}, {token: "a"}},
+			},
+			want: want{
+				configured: 3,
+				len:        1,
+			},
+		},
+		"EvictedOwnerRemoved": {
+			args: args{
+				opts:  []ProviderMetaPoolOption{WithProviderMetaPoolSize(1)},
+				calls: []call{{owner: "pc1", token: "a"}, {owner: "pc2", token: "b"}},
+			},
+			want: want{
+				configured: 2,
+				len:        1,
+				owners:     1,
+			},
+		},
+		"Released": {
+			args: args{
+				calls:   []call{{owner: "pc", token: "a"}},
+				release: []string{"pc"},
+			},
+			want: want{
+				configured: 1,
+			},
+		},
+		"ReleasedSharedConfigurationNotEvicted": {
+			args: args{
+				calls:   []call{{owner: "pc1", token: "a"}, {owner: "pc2", token: "a"}},
+				release: []string{"pc1"},
+			},
+			want: want{
+				configured: 1,
+				len:        1,
+				owners:     1,
+			},
+		},
+		"ConfigureFailed": {
+			args: args{
+				calls: []call{{owner: "pc", token: "a"}, {owner: "pc", token: "a"}},
+				err:   errBoom,
+			},
+			want: want{
+				configured: 2,
+				err:        errors.Wrap(errBoom, errConfigureProviderMeta),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			configured := 0
+			configure := func(_ context.Context, pc ProviderConfiguration) (any, error) {
+				configured++
+				return pc["token"], tc.args.err
+			}
+			p := NewProviderMetaPool(tc.args.opts...)
+			var err error
+			for _, c := range tc.args.calls {
+				var meta any
+				meta, err = p.Get(context.TODO(), c.owner, ProviderConfiguration{"token": c.token}, configure)
+				if err == nil && meta != c.token {
+					t.Errorf("\n%s\nGet(...): want meta %q, got %v", name, c.token, meta)
+				}
+			}
+			for _, o := range tc.args.release {
+				p.Release(o)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want error, +got error:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.configured, configured); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want configured, +got configured:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.len, p.Len()); diff != "" {
+				t.Errorf("\n%s\nLen(): -want, +got:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.owners, len(p.owners)); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want owners, +got owners:\n%s", name, diff)
+			}
+		})
+	}
+}
@@ -7,20 +7,32 @@ package terraform
 import (
 	"bufio"
 	"fmt"
+	"io"
+	"net"
 	"os"
+	osexec "os/exec"
 	"regexp"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/clock"
 	"k8s.io/utils/exec"
+
+	"github.com/crossplane/upjet/pkg/metrics"
 )
 
 const (
 	// error messages
 	errFmtTimeout = "timed out after %v while waiting for the reattach configuration string"
+	errFmtBackoff = "native provider restart is backed off until %s after %d consecutive failures"
+	errExited     = "native provider process exited unexpectedly"
+
+	reasonNativeProviderExited event.Reason = "NativeProviderExited"
 
 	// an example value would be: '{"registry.terraform.io/hashicorp/aws": {"Protocol": "grpc", "ProtocolVersion":5, "Pid":... "Addr":{"Network": "unix","String": "..."}}}'
 	fmtReattachEnv = `{"%s":{"Protocol":"grpc","ProtocolVersion":%d,"Pid":%d,"Test": true,"Addr":{"Network": "unix","String": "%s"}}}`
@@ -32,6 +44,16 @@ const (
 	valMagicCookie         = "d602bf8f470bc67ca7faa0386276bbdd4330efaf76d1a219cb4d6991ca9872b2"
 	defaultProtocolVersion = 5
 	reattachTimeout        = 1 * time.Minute
+
+	defaultProbeTimeout   = 5 * time.Second
+	defaultProbeInterval  = 30 * time.Second
+	defaultInitialBackoff = 1 * time.Second
+	defaultMaxBackoff     = 5 * time.Minute
+
+	// HTTP/2 client connection preface followed by an empty SETTINGS frame
+	http2Preface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n\x00\x00\x00\x04\x00\x00\x00\x00\x00"
+	// http2FrameTypeSettings is the type of the HTTP/2 SETTINGS frames
+	http2FrameTypeSettings = 0x4
 )
 
 var (
@@ -77,6 +99,21 @@ type SharedProvider struct {
 	clock              clock.Clock
 	mu                 *sync.Mutex
 	stopCh             chan bool
+
+	probe         func(socket string, timeout time.Duration) error
+	probeTimeout  time.Duration
+	probeInterval time.Duration
+	// unhealthy is the error of the last failed liveness probe of
+	// the running native provider, if any.
+	unhealthy      error
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	failures       int
+	retryAt        time.Time
+	pid            int
+
+	recorder       event.Recorder
+	recorderObject runtime.Object
 }
 
 // SharedProviderOption lets you configure the shared gRPC runner.
@@ -127,14 +164,59 @@ func WithNativeProviderLogger(logger logging.Logger) SharedProviderOption {
 	}
 }
 
+// WithNativeProviderEventRecorder configures the runner to record a warning
+// event with the exit error against the specified object, e.g., the Pod of
+// the provider, each time the native provider process exits unexpectedly.
+func WithNativeProviderEventRecorder(r event.Recorder, obj runtime.Object) SharedProviderOption {
+	return func(sp *SharedProvider) {
+		sp.recorder = r
+		sp.recorderObject = obj
+	}
+}
+
+// WithNativeProviderProbeTimeout configures the timeout of the liveness
+// probes of the native provider's gRPC server. An unresponsive native
+// provider is restarted the next time it's to be reused. A non-positive
+// timeout disables the liveness probes.
+func WithNativeProviderProbeTimeout(d time.Duration) SharedProviderOption {
+	return func(sp *SharedProvider) {
+		sp.probeTimeout = d
+	}
+}
+
+// WithNativeProviderProbeInterval configures the interval at which
+// the liveness probes of the native provider's gRPC server are run in
+// the background. A non-positive interval disables the liveness probes.
+func WithNativeProviderProbeInterval(d time.Duration) SharedProviderOption {
+	return func(sp *SharedProvider) {
+		sp.probeInterval = d
+	}
+}
+
+// WithNativeProviderRestartBackoff configures the exponential backoff of
+// the native provider restarts after consecutive crashes or failed liveness
+// probes, starting with the initial duration and capped at the max duration.
+func WithNativeProviderRestartBackoff(initial, max time.Duration) SharedProviderOption {
+	return func(sp *SharedProvider) {
+		sp.initialBackoff = initial
+		sp.maxBackoff = max
+	}
+}
+
 // NewSharedProvider instantiates a SharedProvider runner with an
 // OS executor using the supplied options.
 func NewSharedProvider(opts ...SharedProviderOption) *SharedProvider {
 	sr := &SharedProvider{
 		protocolVersion: defaultProtocolVersion,
-		executor:        exec.New(),
+		executor:        processExecutor{Interface: exec.New()},
 		clock:           clock.RealClock{},
+		recorder:        event.NewNopRecorder(),
 		mu:              &sync.Mutex{},
+		probe:           probeGRPCServer,
+		probeTimeout:    defaultProbeTimeout,
+		probeInterval:   defaultProbeInterval,
+		initialBackoff:  defaultInitialBackoff,
+		maxBackoff:      defaultMaxBackoff,
 	}
 	for _, o := range opts {
 		o(sr)
@@ -142,6 +224,88 @@ func NewSharedProvider(opts ...SharedProviderOption) *SharedProvider {
 	return sr
 }
 
+// probeGRPCServer checks whether the gRPC server listening on the specified
+// unix socket is responsive by sending it the HTTP/2 connection preface and
+// waiting for the server's SETTINGS frame.
+func probeGRPCServer(socket string, timeout time.Duration) error {
+	conn, err := net.DialTimeout("unix", socket, timeout)
+	if err != nil {
+		return errors.Wrap(err, "cannot connect to the native provider")
+	}
+	defer conn.Close() //nolint:errcheck
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return errors.Wrap(err, "cannot set the deadline of the liveness probe")
+	}
+	if _, err := io.WriteString(conn, http2Preface); err != nil {
+		return errors.Wrap(err, "cannot write to the native provider")
+	}
+	// HTTP/2 frame header: 3-byte length, 1-byte type, 1-byte flags and
+	// 4-byte stream identifier
+	header := make([]byte, 9)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return errors.Wrap(err, "cannot read from the native provider")
+	}
+	if header[3] != http2FrameTypeSettings {
+		return errors.Errorf("unexpected HTTP/2 frame type from the native provider: %d", header[3])
+	}
+	return nil
+}
+
+// probeLoop periodically probes the native provider listening on
+// the specified socket, without holding the lock, and records whether it's
+// unresponsive until the native provider with the specified reattach
+// configuration is stopped or restarted.
+func (sr *SharedProvider) probeLoop(stopCh <-chan bool, reattachConfig, socket string) {
+	t := sr.clock.NewTimer(sr.probeInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-t.C():
+		}
+		err := sr.probe(socket, sr.probeTimeout)
+		sr.mu.Lock()
+		if sr.reattachConfig != reattachConfig {
+			sr.mu.Unlock()
+			return
+		}
+		sr.unhealthy = err
+		sr.mu.Unlock()
+		t.Reset(sr.probeInterval)
+	}
+}
+
+// recordFailure records a crash or a failed liveness probe of the native
+// provider and schedules the next restart attempt with an exponential
+// backoff. Must be called with the lock held.
+func (sr *SharedProvider) recordFailure(reason string) {
+	metrics.NativeProviderCrashes.WithLabelValues(reason).Inc()
+	sr.failures++
+	backoff := sr.initialBackoff
+	for i := 1; i < sr.failures && backoff < sr.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > sr.maxBackoff {
+		backoff = sr.maxBackoff
+	}
+	sr.retryAt = sr.clock.Now().Add(backoff)
+}
+
+// recordExit records a warning event for the unexpected exit of the native
+// provider process with the specified exit error.
+func (sr *SharedProvider) recordExit(err error) {
+	if sr.recorder == nil || sr.recorderObject == nil {
+		return
+	}
+	if err == nil {
+		err = errors.New(errExited)
+	} else {
+		err = errors.Wrap(err, errExited)
+	}
+	sr.recorder.Event(sr.recorderObject, event.Warning(reasonNativeProviderExited, err, "nativeProviderPath", sr.nativeProviderPath))
+}
+
 // Start starts a shared gRPC server if not already running
 // A logger, native provider's path and command-line arguments to be
 // passed to it must have been properly configured.
@@ -152,20 +316,47 @@ func (sr *SharedProvider) Start() (string, error) { //nolint:gocyclo
 	defer sr.mu.Unlock()
 	log := sr.logger.WithValues("nativeProviderPath", sr.nativeProviderPath, "nativeProviderArgs", sr.nativeProviderArgs)
 	if sr.reattachConfig != "" {
-		log.Debug("Shared gRPC server is running...", "reattachConfig", sr.reattachConfig)
-		return sr.reattachConfig, nil
+		if sr.unhealthy == nil {
+			log.Debug("Shared gRPC server is running...", "reattachConfig", sr.reattachConfig)
+			return sr.reattachConfig, nil
+		}
+		log.Info("Native provider is unresponsive, restarting it", "error", sr.unhealthy)
+		sr.recordFailure("unresponsive")
+		sr.stop()
+	}
+	if sr.failures > 0 && sr.clock.Now().Before(sr.retryAt) {
+		return "", errors.Errorf(errFmtBackoff, sr.retryAt.Format(time.RFC3339), sr.failures)
 	}
 	log.Debug("Provider runner not yet started. Will fork a new native provider.")
 	errCh := make(chan error, 1)
 	reattachCh := make(chan string, 1)
-	sr.stopCh = make(chan bool, 1)
+	stopCh := make(chan bool, 1)
+	sr.stopCh = stopCh
+	// reattachConfig is the reattach configuration of this run, which is
+	// set once the native provider is ready.
+	reattachConfig := ""
+	// pid is the process ID of the native provider of this run, which is
+	// set before its reattach configuration is reported.
+	pid := 0
+	// started is the native provider process of this run once it's forked,
+	// so that it can be stopped if it never reports its reattach
+	// configuration, in which case the goroutine below is still blocked
+	// reading its output and cannot handle a stop request.
+	var started exec.Cmd
+	timedOut := false
+	startedMu := &sync.Mutex{}
 
 	go func() {
 		defer close(errCh)
 		defer close(reattachCh)
 		defer func() {
 			sr.mu.Lock()
-			sr.reattachConfig = ""
+			// the native provider might have already been restarted
+			if reattachConfig != "" && sr.reattachConfig == reattachConfig {
+				sr.reattachConfig = ""
+				sr.pid = 0
+				sr.unhealthy = nil
+			}
 			sr.mu.Unlock()
 		}()
 		//#nosec G204 no user input
@@ -180,7 +371,16 @@ func (sr *SharedProvider) Start() (string, error) { //nolint:gocyclo
 			errCh <- err
 			return
 		}
+		startedMu.Lock()
+		started = cmd
+		if timedOut {
+			cmd.Stop()
+		}
+		startedMu.Unlock()
 		log.Debug("Forked new native provider.")
+		if p, ok := cmd.(pidReporter); ok {
+			pid = p.PID()
+		}
 		scanner := bufio.NewScanner(stdout)
 		for scanner.Scan() {
 			t := scanner.Text()
@@ -188,7 +388,7 @@ func (sr *SharedProvider) Start() (string, error) { //nolint:gocyclo
 			if matches == nil {
 				continue
 			}
-			reattachCh <- fmt.Sprintf(fmtReattachEnv, sr.nativeProviderName, sr.protocolVersion, os.Getpid(), matches[1])
+			reattachCh <- matches[1]
 			break
 		}
 
@@ -200,20 +400,43 @@ func (sr *SharedProvider) Start() (string, error) { //nolint:gocyclo
 		select {
 		case err := <-waitErrCh:
 			log.Info("Native Terraform provider process error", "error", err)
+			sr.recordExit(err)
+			// Start might be waiting for the error while holding the lock
 			errCh <- err
-		case <-sr.stopCh:
+			sr.mu.Lock()
+			if reattachConfig != "" && sr.reattachConfig == reattachConfig {
+				sr.recordFailure("exited")
+			}
+			sr.mu.Unlock()
+		case <-stopCh:
 			cmd.Stop()
 			log.Debug("Stopped the provider runner.")
 		}
 	}()
 
 	select {
-	case reattachConfig := <-reattachCh:
+	case socket := <-reattachCh:
+		reattachConfig = fmt.Sprintf(fmtReattachEnv, sr.nativeProviderName, sr.protocolVersion, os.Getpid(), socket)
 		sr.reattachConfig = reattachConfig
+		sr.pid = pid
+		sr.unhealthy = nil
+		sr.failures = 0
+		if sr.probeTimeout > 0 && sr.probeInterval > 0 {
+			go sr.probeLoop(stopCh, reattachConfig, socket)
+		}
 		return sr.reattachConfig, nil
 	case err := <-errCh:
+		sr.recordFailure("exited")
 		return "", err
 	case <-sr.clock.After(reattachTimeout):
+		sr.recordFailure("unresponsive")
+		startedMu.Lock()
+		timedOut = true
+		if started != nil {
+			started.Stop()
+		}
+		startedMu.Unlock()
+		sr.stop()
 		return "", errors.Errorf(errFmtTimeout, reattachTimeout)
 	}
 }
@@ -226,8 +449,90 @@ func (sr *SharedProvider) Stop() error {
 	if sr.stopCh == nil {
 		return errors.New("shared provider process not started yet")
 	}
+	sr.stop()
+	return nil
+}
+
+// stop stops the running native provider, if any. Must be called with
+// the lock held.
+func (sr *SharedProvider) stop() {
+	if sr.stopCh == nil {
+		return
+	}
 	sr.stopCh <- true
 	close(sr.stopCh)
 	sr.stopCh = nil
-	return nil
+	sr.reattachConfig = ""
+	sr.pid = 0
+	sr.unhealthy = nil
+}
+
+// PID returns the process ID of the running native provider, or 0 if it's
+// not running or its executor cannot report it.
+func (sr *SharedProvider) PID() int {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	return sr.pid
+}
+
+// processExecutor is an exec.Interface whose commands can report
+// the process IDs of the processes they start.
+type processExecutor struct {
+	exec.Interface
+}
+
+// Command returns a command reporting the process ID of the process
+// it starts.
+func (processExecutor) Command(cmd string, args ...string) exec.Cmd {
+	//#nosec G204 no user input
+	return &processCmd{Cmd: osexec.Command(cmd, args...)}
+}
+
+// processCmd is an exec.Cmd that can report the ID of the process
+// it has started.
+type processCmd struct {
+	*osexec.Cmd
+}
+
+func (c *processCmd) SetDir(dir string) {
+	c.Dir = dir
+}
+
+func (c *processCmd) SetStdin(in io.Reader) {
+	c.Stdin = in
+}
+
+func (c *processCmd) SetStdout(out io.Writer) {
+	c.Stdout = out
+}
+
+func (c *processCmd) SetStderr(out io.Writer) {
+	c.Stderr = out
+}
+
+func (c *processCmd) SetEnv(env []string) {
+	c.Env = env
+}
+
+// Stop signals the started process to terminate and kills it if it's
+// still running after a grace period.
+func (c *processCmd) Stop() {
+	if c.Process == nil {
+		return
+	}
+	p := c.Process
+	_ = p.Signal(syscall.SIGTERM)
+	time.AfterFunc(10*time.Second, func() {
+		// fails if the process has already been released by Wait
+		_ = p.Signal(syscall.SIGKILL)
+	})
+}
+
+// PID returns the ID of the started process, or 0 if it has not
+// been started.
+func (c *processCmd) PID() int {
+	if c.Process == nil {
+		return 0
+	}
+	return c.Process.Pid
 }
@@ -5,6 +5,7 @@
 package terraform
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -14,10 +15,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clock "k8s.io/utils/clock/testing"
 	"k8s.io/utils/exec"
 	testingexec "k8s.io/utils/exec/testing"
@@ -69,6 +74,41 @@ func TestStartSharedServer(t *testing.T) {
 				reattachConfig: "test1",
 			},
 		},
+		"UnresponsiveRestarted": {
+			args: args{
+				runner: &SharedProvider{
+					nativeProviderPath: testPath,
+					nativeProviderName: testName,
+					protocolVersion:    defaultProtocolVersion,
+					reattachConfig:     "test1",
+					logger:             logging.NewNopLogger(),
+					executor:           newExecutorWithStoutPipe(testReattachConfig2, nil),
+					mu:                 &sync.Mutex{},
+					clock:              clock.NewFakeClock(time.Now()),
+					stopCh:             make(chan bool, 1),
+					unhealthy:          testErr,
+				},
+			},
+			want: want{
+				reattachConfig: fmt.Sprintf(`{"provider-test":{"Protocol":"grpc","ProtocolVersion":5,"Pid":%d,"Test": true,"Addr":{"Network": "unix","String": "test2"}}}`, os.Getpid()),
+			},
+		},
+		"RestartBackedOff": {
+			args: args{
+				runner: &SharedProvider{
+					nativeProviderPath: testPath,
+					logger:             logging.NewNopLogger(),
+					executor:           newExecutorWithStoutPipe(testReattachConfig1, nil),
+					mu:                 &sync.Mutex{},
+					clock:              clock.NewFakeClock(time.Unix(0, 0).UTC()),
+					failures:           2,
+					retryAt:            time.Unix(60, 0).UTC(),
+				},
+			},
+			want: want{
+				err: errors.Errorf(errFmtBackoff, time.Unix(60, 0).UTC().Format(time.RFC3339), 2),
+			},
+		},
 		"NativeProviderError": {
 			args: args{
 				runner: NewSharedProvider(WithNativeProviderLogger(logging.NewNopLogger()), WithNativeProviderPath(testPath),
@@ -178,3 +218,203 @@ func TestWithNativeProviderExecutor(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordFailure(t *testing.T) {
+	now := time.Unix(0, 0)
+	tests := map[string]struct {
+		failures int
+		want     time.Time
+	}{
+		"FirstFailure": {
+			want: now.Add(time.Second),
+		},
+		"ConsecutiveFailures": {
+			failures: 3,
+			want:     now.Add(8 * time.Second),
+		},
+		"CappedBackoff": {
+			failures: 20,
+			want:     now.Add(time.Minute),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sr := NewSharedProvider(WithNativeProviderRestartBackoff(time.Second, time.Minute))
+			sr.clock = clock.NewFakeClock(now)
+			sr.failures = tt.failures
+			sr.recordFailure("exited")
+			if diff := cmp.Diff(tt.want, sr.retryAt); diff != "" {
+				t.Errorf("recordFailure(): -want retryAt, +got retryAt:\n%s", diff)
+			}
+		})
+	}
+}
+
+type chanRecorder struct {
+	events chan event.Event
+}
+
+func (r *chanRecorder) Event(_ runtime.Object, e event.Event) {
+	r.events <- e
+}
+
+func (r *chanRecorder) WithAnnotations(_ ...string) event.Recorder {
+	return r
+}
+
+func TestSharedProviderRecordExit(t *testing.T) {
+	errBoom := errors.New("boom")
+	r := &chanRecorder{events: make(chan event.Event, 1)}
+	executor := &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			func(cmd string, args ...string) exec.Cmd {
+				return &testingexec.FakeCmd{
+					StdoutPipeResponse: testingexec.FakeStdIOPipeResponse{
+						ReadCloser: io.NopCloser(strings.NewReader(`1|5|unix|test1|grpc|`)),
+					},
+					WaitResponse: errBoom,
+				}
+			},
+		},
+	}
+	sr := NewSharedProvider(WithNativeProviderLogger(logging.NewNopLogger()), WithNativeProviderPath("path"),
+		WithNativeProviderExecutor(executor), WithNativeProviderEventRecorder(r, &corev1.Pod{}))
+	if _, err := sr.Start(); err != nil {
+		t.Fatalf("Start(): unexpected error: %v", err)
+	}
+	select {
+	case e := <-r.events:
+		want := event.Warning(reasonNativeProviderExited, errors.Wrap(errBoom, errExited), "nativeProviderPath", "path")
+		if diff := cmp.Diff(want, e); diff != "" {
+			t.Errorf("Start(): -want event, +got event:\n%s", diff)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Error("Start(): no event is recorded for the exited native provider")
+	}
+}
+
+// blockingCmd is a FakeCmd whose standard output is not closed until it's
+// stopped.
+type blockingCmd struct {
+	*testingexec.FakeCmd
+	w       *io.PipeWriter
+	once    sync.Once
+	stopped chan struct{}
+}
+
+func (c *blockingCmd) Stop() {
+	c.once.Do(func() {
+		_ = c.w.Close()
+		close(c.stopped)
+	})
+}
+
+func TestSharedProviderStartTimeout(t *testing.T) {
+	r, w := io.Pipe()
+	cmd := &blockingCmd{
+		FakeCmd: &testingexec.FakeCmd{
+			StdoutPipeResponse: testingexec.FakeStdIOPipeResponse{
+				ReadCloser: r,
+			},
+		},
+		w:       w,
+		stopped: make(chan struct{}),
+	}
+	sr := &SharedProvider{
+		nativeProviderPath: "path",
+		logger:             logging.NewNopLogger(),
+		executor: &testingexec.FakeExec{
+			CommandScript: []testingexec.FakeCommandAction{
+				func(string, ...string) exec.Cmd {
+					return cmd
+				},
+			},
+		},
+		mu:    &sync.Mutex{},
+		clock: &fakeClock{},
+	}
+	if _, err := sr.Start(); err == nil {
+		t.Fatal("Start(): expected a timeout error")
+	}
+	select {
+	case <-cmd.stopped:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Error("Start(): the native provider that does not report its reattach configuration is not stopped")
+	}
+}
+
+func TestSharedProviderStartResetsFailures(t *testing.T) {
+	sr := &SharedProvider{
+		nativeProviderPath: "path",
+		logger:             logging.NewNopLogger(),
+		executor:           newExecutorWithStoutPipe(`1|5|unix|test1|grpc|`, nil),
+		mu:                 &sync.Mutex{},
+		clock:              clock.NewFakeClock(time.Unix(120, 0).UTC()),
+		failures:           2,
+		retryAt:            time.Unix(60, 0).UTC(),
+	}
+	if _, err := sr.Start(); err != nil {
+		t.Fatalf("Start(): unexpected error: %v", err)
+	}
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.failures != 0 {
+		t.Errorf("Start(): want 0 failures after a successful start, got %d", sr.failures)
+	}
+}
+
+func TestSharedProviderProbeLoop(t *testing.T) {
+	errBoom := errors.New("boom")
+	probed := make(chan struct{})
+	fc := clock.NewFakeClock(time.Now())
+	sr := &SharedProvider{
+		reattachConfig: "test1",
+		mu:             &sync.Mutex{},
+		clock:          fc,
+		probeTimeout:   time.Second,
+		probeInterval:  time.Minute,
+		probe: func(string, time.Duration) error {
+			close(probed)
+			return errBoom
+		},
+	}
+	stopCh := make(chan bool)
+	defer close(stopCh)
+	go sr.probeLoop(stopCh, "test1", "test1")
+	if err := wait.PollUntilContextTimeout(context.TODO(), time.Millisecond, wait.ForeverTestTimeout, true, func(context.Context) (bool, error) {
+		return fc.HasWaiters(), nil
+	}); err != nil {
+		t.Fatalf("probeLoop(): the probe is not scheduled: %v", err)
+	}
+	fc.Step(time.Minute)
+	select {
+	case <-probed:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatal("probeLoop(): the native provider is not probed")
+	}
+	if err := wait.PollUntilContextTimeout(context.TODO(), time.Millisecond, wait.ForeverTestTimeout, true, func(context.Context) (bool, error) {
+		sr.mu.Lock()
+		defer sr.mu.Unlock()
+		return sr.unhealthy != nil, nil
+	}); err != nil {
+		t.Errorf("probeLoop(): the unresponsive native provider is not marked unhealthy: %v", err)
+	}
+}
+
+func TestProcessExecutorPID(t *testing.T) {
+	cmd := processExecutor{Interface: exec.New()}.Command("true")
+	p, ok := cmd.(pidReporter)
+	if !ok {
+		t.Fatal("Command(): the command cannot report its process ID")
+	}
+	if pid := p.PID(); pid != 0 {
+		t.Errorf("PID(): want 0 before the process is started, got %d", pid)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Start(): unexpected error: %v", err)
+	}
+	defer cmd.Wait() //nolint:errcheck
+	if pid := p.PID(); pid <= 0 {
+		t.Errorf("PID(): want the ID of the started process, got %d", pid)
+	}
+}
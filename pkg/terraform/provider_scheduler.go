@@ -5,11 +5,14 @@
 package terraform
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/pkg/errors"
 
+	"github.com/crossplane/upjet/pkg/metrics"
 	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
 )
 
@@ -75,43 +78,61 @@ type schedulerEntry struct {
 	ProviderRunner
 	inUse           int
 	invocationCount int
+	pid             int
+	startedAt       time.Time
+	lastUsed        time.Time
+	// evictionReason is set once the entry is evicted and no longer
+	// scheduled.
+	evictionReason string
 }
 
 type providerInUse struct {
 	scheduler *SharedProviderScheduler
-	handle    ProviderHandle
+	entry     *schedulerEntry
 }
 
 func (p *providerInUse) Increment() {
 	p.scheduler.mu.Lock()
 	defer p.scheduler.mu.Unlock()
-	r := p.scheduler.runners[p.handle]
-	r.inUse++
-	r.invocationCount++
+	p.entry.inUse++
+	p.entry.invocationCount++
+	p.entry.lastUsed = p.scheduler.now()
 }
 
 func (p *providerInUse) Decrement() {
 	p.scheduler.mu.Lock()
 	defer p.scheduler.mu.Unlock()
-	if p.scheduler.runners[p.handle].inUse == 0 {
+	if p.entry.inUse == 0 {
 		return
 	}
-	p.scheduler.runners[p.handle].inUse--
+	p.entry.inUse--
+	p.entry.lastUsed = p.scheduler.now()
 }
 
 // SharedProviderScheduler is a ProviderScheduler that
 // shares a native plugin (Terraform provider) process between
 // MR reconciliation loops whose MRs yield the same ProviderHandle, i.e.,
 // whose Terraform resource blocks are configuration-wise identical.
+// It can be configured to run a pool of native plugin processes per
+// ProviderHandle, in which case the reconciliation loops are spread
+// across the processes of the pool by their in-use counts.
 // SharedProviderScheduler is configured with a max TTL and it will gracefully
 // attempt to replace ProviderRunners whose TTL exceed this maximum,
-// if they are not in-use.
+// if they are not in-use. It can also be configured with an EvictionPolicy
+// to replace the ProviderRunners based on their resident memory, age or
+// idle time.
 type SharedProviderScheduler struct {
-	runnerOpts []SharedProviderOption
-	runners    map[ProviderHandle]*schedulerEntry
-	ttl        int
-	mu         *sync.Mutex
-	logger     logging.Logger
+	runnerOpts       []SharedProviderOption
+	runners          map[ProviderHandle][]*schedulerEntry
+	poolSize         int
+	ttl              int
+	mu               *sync.Mutex
+	logger           logging.Logger
+	evictionCtx      context.Context
+	evictionPolicy   EvictionPolicy
+	evictionInterval time.Duration
+	now              func() time.Time
+	rssFn            func(pid int) (uint64, error)
 }
 
 // SharedProviderSchedulerOption represents an option to configure the
@@ -126,29 +147,53 @@ func WithSharedProviderOptions(opts ...SharedProviderOption) SharedProviderSched
 	}
 }
 
+// WithProviderPoolSize configures the maximum number of native plugin
+// processes run per ProviderHandle. A new process is added to the pool of
+// a ProviderHandle only when all the processes in the pool are in use.
+// Defaults to 1.
+func WithProviderPoolSize(n int) SharedProviderSchedulerOption {
+	return func(scheduler *SharedProviderScheduler) {
+		scheduler.poolSize = n
+	}
+}
+
 // NewSharedProviderScheduler initializes a new SharedProviderScheduler
 // with the specified logger and options.
 func NewSharedProviderScheduler(l logging.Logger, ttl int, opts ...SharedProviderSchedulerOption) *SharedProviderScheduler {
 	scheduler := &SharedProviderScheduler{
-		mu:      &sync.Mutex{},
-		runners: make(map[ProviderHandle]*schedulerEntry),
-		logger:  l,
-		ttl:     ttl,
+		mu:       &sync.Mutex{},
+		runners:  make(map[ProviderHandle][]*schedulerEntry),
+		poolSize: 1,
+		logger:   l,
+		ttl:      ttl,
+		now:      time.Now,
+		rssFn:    processRSS,
 	}
 	for _, o := range opts {
 		o(scheduler)
 	}
+	if scheduler.evictionInterval > 0 {
+		go scheduler.evictLoop()
+	}
 	return scheduler
 }
 
 func (s *SharedProviderScheduler) Start(h ProviderHandle) (InUse, string, error) {
-	logger := s.logger.WithValues("handle", h, "ttl", s.ttl, "ttlMargin", ttlMargin)
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	defer s.reportRunners()
 
-	r := s.runners[h]
+	i := s.selectRunner(h)
+	logger := s.logger.WithValues("handle", h, "index", i, "ttl", s.ttl, "ttlMargin", ttlMargin)
+	var r *schedulerEntry
+	if i < len(s.runners[h]) {
+		r = s.runners[h][i]
+	}
 	switch {
-	case r != nil && (r.invocationCount < s.ttl || r.inUse > 0):
+	case r != nil && r.evictionReason != "" && r.inUse > 0:
+		logger.Debug("The provider runner has been evicted and is draining. Caller will need to retry.", "reason", r.evictionReason, "inUse", r.inUse)
+		return nil, "", tferrors.NewDrainingScheduleError(r.evictionReason)
+	case r != nil && r.evictionReason == "" && (r.invocationCount < s.ttl || r.inUse > 0):
 		if r.invocationCount > int(float64(s.ttl)*(1+ttlMargin)) {
 			logger.Debug("Reuse budget has been exceeded. Caller will need to retry.")
 			return nil, "", tferrors.NewRetryScheduleError(r.invocationCount, s.ttl)
@@ -156,9 +201,10 @@ func (s *SharedProviderScheduler) Start(h ProviderHandle) (InUse, string, error)
 
 		logger.Debug("Reusing the provider runner", "invocationCount", r.invocationCount, "inUse", r.inUse)
 		rc, err := r.Start()
+		s.updatePID(r)
 		return &providerInUse{
 			scheduler: s,
-			handle:    h,
+			entry:     r,
 		}, rc, errors.Wrapf(err, "cannot use already started provider with handle: %s", h)
 	case r != nil:
 		logger.Debug("The provider runner has expired. Attempting to stop...", "invocationCount", r.invocationCount, "inUse", r.inUse)
@@ -168,16 +214,24 @@ func (s *SharedProviderScheduler) Start(h ProviderHandle) (InUse, string, error)
 	}
 
 	runner := NewSharedProvider(s.runnerOpts...)
+	now := s.now()
 	r = &schedulerEntry{
 		ProviderRunner: runner,
+		startedAt:      now,
+		lastUsed:       now,
 	}
 	runner.logger = logger
-	s.runners[h] = r
+	if i < len(s.runners[h]) {
+		s.runners[h][i] = r
+	} else {
+		s.runners[h] = append(s.runners[h], r)
+	}
 	logger.Debug("Starting new shared provider...")
-	rc, err := s.runners[h].Start()
+	rc, err := r.Start()
+	s.updatePID(r)
 	return &providerInUse{
 		scheduler: s,
-		handle:    h,
+		entry:     r,
 	}, rc, errors.Wrapf(err, "cannot start the shared provider runner for handle: %s", h)
 }
 
@@ -186,6 +240,50 @@ func (s *SharedProviderScheduler) Stop(ProviderHandle) error {
 	return nil
 }
 
+// selectRunner returns the index of the entry in the pool of the specified
+// ProviderHandle with the lowest in-use count, skipping the draining
+// entries, or the length of the pool if a new entry is to be added to it.
+// Must be called with the lock held.
+func (s *SharedProviderScheduler) selectRunner(h ProviderHandle) int {
+	pool := s.runners[h]
+	selected := -1
+	for i, r := range pool {
+		if r.evictionReason != "" && r.inUse > 0 {
+			continue
+		}
+		if selected == -1 || r.inUse < pool[selected].inUse {
+			selected = i
+		}
+	}
+	switch {
+	case len(pool) < s.poolSize && (selected == -1 || pool[selected].inUse > 0):
+		return len(pool)
+	case selected == -1:
+		// all the entries are draining
+		return 0
+	default:
+		return selected
+	}
+}
+
+// reportRunners reports the number of the native plugin processes run by
+// the scheduler. Must be called with the lock held.
+func (s *SharedProviderScheduler) reportRunners() {
+	n := 0
+	for _, pool := range s.runners {
+		n += len(pool)
+	}
+	metrics.SharedProviders.Set(float64(n))
+}
+
+// updatePID records the process ID of the native provider of the specified
+// entry, which might have been restarted. Must be called with the lock held.
+func (s *SharedProviderScheduler) updatePID(r *schedulerEntry) {
+	if p, ok := r.ProviderRunner.(pidReporter); ok {
+		r.pid = p.PID()
+	}
+}
+
 // WorkspaceProviderScheduler is a ProviderScheduler that
 // shares a native plugin (Terraform provider) process between
 // the Terraform CLI invocations in the context of a single
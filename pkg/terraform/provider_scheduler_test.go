@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSelectRunner(t *testing.T) {
+	type args struct {
+		poolSize int
+		pool     []*schedulerEntry
+	}
+	cases := map[string]struct {
+		args args
+		want int
+	}{
+		"EmptyPool": {
+			args: args{
+				poolSize: 2,
+			},
+			want: 0,
+		},
+		"IdleRunner": {
+			args: args{
+				poolSize: 2,
+				pool:     []*schedulerEntry{{}},
+			},
+			want: 0,
+		},
+		"PoolNotFull": {
+			args: args{
+				poolSize: 2,
+				pool:     []*schedulerEntry{{inUse: 1}},
+			},
+			want: 1,
+		},
+		"LeastInUse": {
+			args: args{
+				poolSize: 3,
+				pool:     []*schedulerEntry{{inUse: 3}, {inUse: 1}, {inUse: 2}},
+			},
+			want: 1,
+		},
+		"SkipDraining": {
+			args: args{
+				poolSize: 2,
+				pool:     []*schedulerEntry{{inUse: 1, evictionReason: evictionReasonMaxAge}, {inUse: 2}},
+			},
+			want: 1,
+		},
+		"AllDraining": {
+			args: args{
+				poolSize: 1,
+				pool:     []*schedulerEntry{{inUse: 1, evictionReason: evictionReasonMaxAge}},
+			},
+			want: 0,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &SharedProviderScheduler{
+				runners:  map[ProviderHandle][]*schedulerEntry{"handle": tc.args.pool},
+				poolSize: tc.args.poolSize,
+			}
+			if diff := cmp.Diff(tc.want, s.selectRunner("handle")); diff != "" {
+				t.Errorf("\n%s\nselectRunner(...): -want index, +got index:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestSharedProviderSchedulerInUse(t *testing.T) {
+	busy := &schedulerEntry{ProviderRunner: &fakeRunner{}, inUse: 1}
+	idle := &schedulerEntry{ProviderRunner: &fakeRunner{}}
+	s := &SharedProviderScheduler{
+		runners:  map[ProviderHandle][]*schedulerEntry{"handle": {busy, idle}},
+		poolSize: 2,
+		ttl:      100,
+		mu:       &sync.Mutex{},
+		logger:   logging.NewNopLogger(),
+		now:      time.Now,
+	}
+	inUse, _, err := s.Start("handle")
+	if err != nil {
+		t.Fatalf("Start(...): %v", err)
+	}
+	inUse.Increment()
+	if diff := cmp.Diff([]int{1, 1}, []int{busy.inUse, idle.inUse}); diff != "" {
+		t.Errorf("Increment(): -want inUse, +got inUse:\n%s", diff)
+	}
+	inUse.Decrement()
+	if diff := cmp.Diff([]int{1, 0}, []int{busy.inUse, idle.inUse}); diff != "" {
+		t.Errorf("Decrement(): -want inUse, +got inUse:\n%s", diff)
+	}
+}
@@ -0,0 +1,214 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/upjet/pkg/metrics"
+)
+
+const (
+	defaultSetupCacheTTL = 10 * time.Minute
+
+	errNoProviderConfigRef = "managed resource does not reference a ProviderConfig"
+	errGetProviderConfig   = "cannot get the referenced ProviderConfig"
+	errGetCredentials      = "cannot get the credentials Secret of the ProviderConfig"
+	errSetupCacheKey       = "cannot compute the Terraform setup cache key"
+	errTrackUsage          = "cannot track the ProviderConfig usage"
+)
+
+// SetupCacheKeyFn computes the key that the Terraform setup of the specified
+// managed resource is cached with. Managed resources with the same key
+// share the same cached Setup.
+type SetupCacheKeyFn func(ctx context.Context, c client.Client, mg xpresource.Managed) (string, error)
+
+// ProviderConfigCacheKey returns a SetupCacheKeyFn that computes the cache
+// key of a managed resource from the UID and the resourceVersion of its
+// ProviderConfig, which is of the specified GroupVersionKind, and from
+// the resourceVersion of the Secret referenced at
+// spec.credentials.secretRef of the ProviderConfig, if any. Thus, a cached
+// Setup is invalidated when the ProviderConfig or its credentials change.
+// Providers whose Setup also depends on the managed resource itself, e.g.,
+// on its region, should extend the returned key accordingly.
+func ProviderConfigCacheKey(gvk schema.GroupVersionKind) SetupCacheKeyFn {
+	return func(ctx context.Context, c client.Client, mg xpresource.Managed) (string, error) {
+		ref := mg.GetProviderConfigReference()
+		if ref == nil {
+			return "", errors.New(errNoProviderConfigRef)
+		}
+		pc := &unstructured.Unstructured{}
+		pc.SetGroupVersionKind(gvk)
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name}, pc); err != nil {
+			return "", errors.Wrap(err, errGetProviderConfig)
+		}
+		key := []string{string(pc.GetUID()), pc.GetResourceVersion()}
+		p := fieldpath.Pave(pc.Object)
+		name, err := p.GetString("spec.credentials.secretRef.name")
+		if fieldpath.IsNotFound(err) {
+			return strings.Join(key, "/"), nil
+		}
+		if err != nil {
+			return "", errors.Wrap(err, errGetCredentials)
+		}
+		ns, err := p.GetString("spec.credentials.secretRef.namespace")
+		if err != nil {
+			return "", errors.Wrap(err, errGetCredentials)
+		}
+		s := &v1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, s); err != nil {
+			return "", errors.Wrap(err, errGetCredentials)
+		}
+		return strings.Join(append(key, s.GetResourceVersion()), "/"), nil
+	}
+}
+
+// SetupCacheOption lets you configure the caching SetupFn.
+type SetupCacheOption func(sc *setupCache)
+
+// WithSetupCacheTTL configures the duration a Setup is cached for. A Setup
+// with temporary credentials expires earlier if its CredentialsExpiry is
+// before the end of the TTL.
+func WithSetupCacheTTL(ttl time.Duration) SetupCacheOption {
+	return func(sc *setupCache) {
+		sc.ttl = ttl
+	}
+}
+
+// WithSetupCacheTracker configures the Tracker that records the usage of
+// the ProviderConfig of every managed resource a Setup is requested for,
+// whether the Setup is cached or not. Providers that track
+// the ProviderConfig usages in their SetupFn must track them with this
+// Tracker instead, because the SetupFn is not called on the cache hits.
+func WithSetupCacheTracker(t xpresource.Tracker) SetupCacheOption {
+	return func(sc *setupCache) {
+		sc.tracker = t
+	}
+}
+
+type setupCacheEntry struct {
+	setup   Setup
+	expires time.Time
+}
+
+type setupCache struct {
+	setupFn SetupFn
+	keyFn   SetupCacheKeyFn
+	tracker xpresource.Tracker
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]setupCacheEntry
+	inflight map[string]chan struct{}
+}
+
+// NewCachingSetupFn returns a SetupFn that caches the Setups returned from
+// the specified SetupFn with the keys computed by the specified
+// SetupCacheKeyFn, so that the credentials are not read and exchanged on
+// every reconciliation. The cached Setups are shared among the managed
+// resources with the same key and must not be modified by the callers.
+// As the specified SetupFn is not called on the cache hits, it must not have
+// any side effects that are needed for every managed resource, such as
+// tracking the ProviderConfig usages. The ProviderConfig usages should be
+// tracked with the Tracker configured with WithSetupCacheTracker.
+func NewCachingSetupFn(fn SetupFn, keyFn SetupCacheKeyFn, opts ...SetupCacheOption) SetupFn {
+	sc := &setupCache{
+		setupFn:  fn,
+		keyFn:    keyFn,
+		ttl:      defaultSetupCacheTTL,
+		now:      time.Now,
+		entries:  make(map[string]setupCacheEntry),
+		inflight: make(map[string]chan struct{}),
+	}
+	for _, o := range opts {
+		o(sc)
+	}
+	return sc.Setup
+}
+
+// Setup returns the cached Setup for the specified managed resource, or
+// calls the underlying SetupFn and caches its result on a cache miss.
+// Concurrent misses for the same key result in a single call to
+// the underlying SetupFn. The usage of the ProviderConfig of the managed
+// resource is tracked on both the cache hits and misses.
+func (sc *setupCache) Setup(ctx context.Context, c client.Client, mg xpresource.Managed) (Setup, error) {
+	if sc.tracker != nil {
+		if err := sc.tracker.Track(ctx, mg); err != nil {
+			return Setup{}, errors.Wrap(err, errTrackUsage)
+		}
+	}
+	key, err := sc.keyFn(ctx, c, mg)
+	if err != nil {
+		return Setup{}, errors.Wrap(err, errSetupCacheKey)
+	}
+	for {
+		sc.mu.Lock()
+		if e, ok := sc.entries[key]; ok && sc.now().Before(e.expires) {
+			sc.mu.Unlock()
+			metrics.SetupCacheRequests.WithLabelValues("hit").Inc()
+			return e.setup, nil
+		}
+		wait, ok := sc.inflight[key]
+		if !ok {
+			break
+		}
+		sc.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return Setup{}, ctx.Err()
+		}
+	}
+	// we are holding the lock and there is no in-flight call for the key
+	done := make(chan struct{})
+	sc.inflight[key] = done
+	sc.removeExpired()
+	sc.mu.Unlock()
+	metrics.SetupCacheRequests.WithLabelValues("miss").Inc()
+
+	s, err := sc.setupFn(ctx, c, mg)
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	delete(sc.inflight, key)
+	close(done)
+	if err != nil {
+		return Setup{}, err
+	}
+	expires := sc.now().Add(sc.ttl)
+	if !s.CredentialsExpiry.IsZero() && s.CredentialsExpiry.Before(expires) {
+		expires = s.CredentialsExpiry
+	}
+	sc.entries[key] = setupCacheEntry{
+		setup:   s,
+		expires: expires,
+	}
+	return s, nil
+}
+
+// removeExpired removes the expired entries, including the ones that have
+// been superseded by the changes to their ProviderConfigs or credentials.
+// Must be called with the lock held.
+func (sc *setupCache) removeExpired() {
+	now := sc.now()
+	for k, e := range sc.entries {
+		if !now.Before(e.expires) {
+			delete(sc.entries, k)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	xpfake "github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestProviderConfigCacheKey(t *testing.T) {
+	errBoom := errors.New("boom")
+	gvk := schema.GroupVersionKind{Group: "upbound.io", Version: "v1beta1", Kind: "ProviderConfig"}
+	mg := &xpfake.Managed{
+		ProviderConfigReferencer: xpfake.ProviderConfigReferencer{
+			Ref: &xpv1.Reference{Name: "default"},
+		},
+	}
+	pcGetFn := func(credentials map[string]any) func(context.Context, client.ObjectKey, client.Object) error {
+		return func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			switch o := obj.(type) {
+			case *unstructured.Unstructured:
+				o.SetUID("pc-uid")
+				o.SetResourceVersion("1")
+				if credentials != nil {
+					o.Object["spec"] = map[string]any{"credentials": credentials}
+				}
+			case *v1.Secret:
+				o.SetResourceVersion("2")
+			}
+			return nil
+		}
+	}
+	type args struct {
+		kube client.Client
+		mg   xpresource.Managed
+	}
+	type want struct {
+		key string
+		err error
+	}
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"NoProviderConfigRef": {
+			args: args{
+				mg: &xpfake.Managed{},
+			},
+			want: want{
+				err: errors.New(errNoProviderConfigRef),
+			},
+		},
+		"GetProviderConfigFailed": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(errBoom),
+				},
+				mg: mg,
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errGetProviderConfig),
+			},
+		},
+		"NoCredentialsSecret": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: pcGetFn(nil),
+				},
+				mg: mg,
+			},
+			want: want{
+				key: "pc-uid/1",
+			},
+		},
+		"CredentialsSecret": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: pcGetFn(map[string]any{
+						"source": "Secret",
+						"secretRef": map[string]any{
+							"namespace": "upbound-system",
+							"name":      "creds",
+						},
+					}),
+				},
+				mg: mg,
+			},
+			want: want{
+				key: "pc-uid/1/2",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			key, err := ProviderConfigCacheKey(gvk)(context.TODO(), tc.args.kube, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nProviderConfigCacheKey(...): -want error, +got error:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.key, key); diff != "" {
+				t.Errorf("\n%s\nProviderConfigCacheKey(...): -want key, +got key:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestCachingSetupFn(t *testing.T) {
+	errBoom := errors.New("boom")
+	type args struct {
+		keys    []string
+		ttl     time.Duration
+		advance time.Duration
+		// credentialsLifetime is the lifetime of the credentials in
+		// the returned Setups. Zero means that they do not expire.
+		credentialsLifetime time.Duration
+		err                 error
+	}
+	type want struct {
+		calls int
+		// tracked is the number of the tracked ProviderConfig usages
+		tracked int
+		err     error
+	}
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"Hit": {
+			args: args{
+				keys: []string{"a", "a", "a"},
+				ttl:  time.Minute,
+			},
+			want: want{
+				calls:   1,
+				tracked: 3,
+			},
+		},
+		"KeyChanged": {
+			args: args{
+				keys: []string{"a", "b", "a"},
+				ttl:  time.Minute,
+			},
+			want: want{
+				calls:   2,
+				tracked: 3,
+			},
+		},
+		"Expired": {
+			args: args{
+				keys:    []string{"a", "a"},
+				ttl:     time.Minute,
+				advance: 2 * time.Minute,
+			},
+			want: want{
+				calls:   2,
+				tracked: 2,
+			},
+		},
+		"CredentialsExpired": {
+			args: args{
+				keys:                []string{"a", "a"},
+				ttl:                 10 * time.Minute,
+				advance:             2 * time.Minute,
+				credentialsLifetime: time.Minute,
+			},
+			want: want{
+				calls:   2,
+				tracked: 2,
+			},
+		},
+		"CredentialsNotExpired": {
+			args: args{
+				keys:                []string{"a", "a"},
+				ttl:                 10 * time.Minute,
+				advance:             2 * time.Minute,
+				credentialsLifetime: 5 * time.Minute,
+			},
+			want: want{
+				calls:   1,
+				tracked: 2,
+			},
+		},
+		"CredentialsOutliveTTL": {
+			args: args{
+				keys:                []string{"a", "a"},
+				ttl:                 time.Minute,
+				advance:             2 * time.Minute,
+				credentialsLifetime: 10 * time.Minute,
+			},
+			want: want{
+				calls:   2,
+				tracked: 2,
+			},
+		},
+		"ErrorNotCached": {
+			args: args{
+				keys: []string{"a", "a"},
+				ttl:  time.Minute,
+				err:  errBoom,
+			},
+			want: want{
+				calls:   2,
+				tracked: 2,
+				err:     errBoom,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			calls := 0
+			setupFn := func(_ context.Context, _ client.Client, _ xpresource.Managed) (Setup, error) {
+				calls++
+				s := Setup{Version: "1.0.0"}
+				if tc.args.credentialsLifetime != 0 {
+					s.CredentialsExpiry = now.Add(tc.args.credentialsLifetime)
+				}
+				return s, tc.args.err
+			}
+			i := 0
+			keyFn := func(_ context.Context, _ client.Client, _ xpresource.Managed) (string, error) {
+				k := tc.args.keys[i]
+				i++
+				return k, nil
+			}
+			tracked := 0
+			tracker := xpresource.TrackerFn(func(_ context.Context, _ xpresource.Managed) error {
+				tracked++
+				return nil
+			})
+			sc := &setupCache{
+				setupFn:  setupFn,
+				keyFn:    keyFn,
+				tracker:  tracker,
+				ttl:      tc.args.ttl,
+				now:      func() time.Time { return now },
+				entries:  make(map[string]setupCacheEntry),
+				inflight: make(map[string]chan struct{}),
+			}
+			var err error
+			for range tc.args.keys {
+				_, err = sc.Setup(context.TODO(), nil, &xpfake.Managed{})
+				now = now.Add(tc.args.advance)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSetup(...): -want error, +got error:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\nSetup(...): -want calls, +got calls:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.tracked, tracked); diff != "" {
+				t.Errorf("\n%s\nSetup(...): -want tracked usages, +got tracked usages:\n%s", name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/upjet/pkg/resource"
+)
+
+const (
+	// the Terraform kubernetes backend stores the state of the default
+	// Terraform workspace in a Secret named tfstate-default-<secret_suffix>
+	// and locks it with a Lease named lock-tfstate-default-<secret_suffix>.
+	prefixKubernetesStateSecret = "tfstate-default-"
+	prefixKubernetesStateLease  = "lock-tfstate-default-"
+
+	errFmtDeleteStateSecret = "cannot delete the Terraform state Secret %q"
+	errFmtDeleteStateLease  = "cannot delete the Terraform state lock Lease %q"
+	errFmtDeleteHTTPState   = "cannot delete the Terraform state at %q"
+)
+
+// StateBackend configures the Terraform backend the workspaces store their
+// states in, so that the states survive the restarts of the provider pod
+// and can be inspected with the standard Terraform tooling. The backend is
+// written as the terraform.backend block of the generated main.tf.json files.
+type StateBackend struct {
+	// Type is the type of the Terraform backend, e.g., "kubernetes" or
+	// "http".
+	Type string
+
+	// ConfigFn returns the configuration of the backend for the specified
+	// resource. The configuration must address a distinct state for each
+	// resource, e.g., by using the resource's UID.
+	ConfigFn func(tr resource.Terraformed) map[string]any
+
+	// DeleteFn deletes the state of the specified resource from the backend.
+	// It's called when the workspace of the resource is removed from
+	// the WorkspaceStore, i.e., when the managed resource is deleted, and
+	// it must not return an error if the state does not exist. If nil,
+	// the states of the deleted resources are left in the backend.
+	DeleteFn func(ctx context.Context, obj xpresource.Object) error
+}
+
+// NewKubernetesStateBackend returns a StateBackend that stores the states in
+// Secrets in the specified namespace of the cluster the provider runs in.
+// The Secrets are suffixed with the UIDs of the resources. The states of
+// the deleted resources are deleted using the specified client.
+func NewKubernetesStateBackend(kube client.Client, namespace string) StateBackend {
+	return StateBackend{
+		Type: "kubernetes",
+		ConfigFn: func(tr resource.Terraformed) map[string]any {
+			return map[string]any{
+				"secret_suffix":     string(tr.GetUID()),
+				"namespace":         namespace,
+				"in_cluster_config": true,
+			}
+		},
+		DeleteFn: func(ctx context.Context, obj xpresource.Object) error {
+			s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: prefixKubernetesStateSecret + string(obj.GetUID())}}
+			if err := kube.Delete(ctx, s); err != nil && !kerrors.IsNotFound(err) {
+				return errors.Wrapf(err, errFmtDeleteStateSecret, s.Name)
+			}
+			l := &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: prefixKubernetesStateLease + string(obj.GetUID())}}
+			if err := kube.Delete(ctx, l); err != nil && !kerrors.IsNotFound(err) {
+				return errors.Wrapf(err, errFmtDeleteStateLease, l.Name)
+			}
+			return nil
+		},
+	}
+}
+
+// HTTPStateBackendOption lets you configure the StateBackend returned by
+// NewHTTPStateBackend.
+type HTTPStateBackendOption func(b *httpStateBackend)
+
+// WithHTTPStateBackendClient sets the HTTP client the states of the deleted
+// resources are deleted with, e.g., to trust the CA of the state server or
+// to authenticate with client certificates. Defaults to http.DefaultClient.
+func WithHTTPStateBackendClient(c *http.Client) HTTPStateBackendOption {
+	return func(b *httpStateBackend) {
+		b.client = c
+	}
+}
+
+// WithHTTPStateBackendCredentials sets the basic authentication credentials
+// of the HTTP state server, which are both written to the backend
+// configuration and used to delete the states of the deleted resources.
+func WithHTTPStateBackendCredentials(username, password string) HTTPStateBackendOption {
+	return func(b *httpStateBackend) {
+		b.username = username
+		b.password = password
+	}
+}
+
+type httpStateBackend struct {
+	client   *http.Client
+	username string
+	password string
+}
+
+// NewHTTPStateBackend returns a StateBackend that stores the states in
+// the HTTP state server at the specified address. The state of a resource
+// is addressed by appending its UID to the address, and the states of
+// the deleted resources are deleted with DELETE requests.
+func NewHTTPStateBackend(address string, opts ...HTTPStateBackendOption) StateBackend {
+	b := &httpStateBackend{
+		client: http.DefaultClient,
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	stateAddress := func(obj xpresource.Object) string {
+		return strings.TrimSuffix(address, "/") + "/" + string(obj.GetUID())
+	}
+	return StateBackend{
+		Type: "http",
+		ConfigFn: func(tr resource.Terraformed) map[string]any {
+			cfg := map[string]any{
+				"address": stateAddress(tr),
+			}
+			if b.username != "" {
+				cfg["username"] = b.username
+				cfg["password"] = b.password
+			}
+			return cfg
+		},
+		DeleteFn: func(ctx context.Context, obj xpresource.Object) error {
+			addr := stateAddress(obj)
+			req, err := http.NewRequestWithContext(ctx, http.MethodDelete, addr, nil)
+			if err != nil {
+				return errors.Wrapf(err, errFmtDeleteHTTPState, addr)
+			}
+			if b.username != "" {
+				req.SetBasicAuth(b.username, b.password)
+			}
+			resp, err := b.client.Do(req)
+			if err != nil {
+				return errors.Wrapf(err, errFmtDeleteHTTPState, addr)
+			}
+			defer resp.Body.Close() //nolint:errcheck
+			if resp.StatusCode == http.StatusNotFound || resp.StatusCode/100 == 2 {
+				return nil
+			}
+			return errors.Errorf(errFmtDeleteHTTPState+": unexpected status code %d", addr, resp.StatusCode)
+		},
+	}
+}
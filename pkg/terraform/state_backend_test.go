@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/upjet/pkg/resource/fake"
+)
+
+func TestKubernetesStateBackendDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+	type want struct {
+		deleted []string
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		err    error
+		want
+	}{
+		"Deleted": {
+			reason: "Both the state Secret and its lock Lease should be deleted.",
+			want: want{
+				deleted: []string{"upbound-system/tfstate-default-uid", "upbound-system/lock-tfstate-default-uid"},
+			},
+		},
+		"NotFound": {
+			reason: "A missing state should not be an error.",
+			err:    kerrors.NewNotFound(schema.GroupResource{}, ""),
+			want: want{
+				deleted: []string{"upbound-system/tfstate-default-uid", "upbound-system/lock-tfstate-default-uid"},
+			},
+		},
+		"DeleteFailed": {
+			reason: "The errors deleting the state should be returned.",
+			err:    errBoom,
+			want: want{
+				deleted: []string{"upbound-system/tfstate-default-uid"},
+				err:     errors.Wrapf(errBoom, errFmtDeleteStateSecret, "tfstate-default-uid"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var deleted []string
+			kube := &test.MockClient{
+				MockDelete: func(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+					deleted = append(deleted, obj.GetNamespace()+"/"+obj.GetName())
+					return tc.err
+				},
+			}
+			tr := &fake.Terraformed{}
+			tr.SetUID(types.UID("uid"))
+			err := NewKubernetesStateBackend(kube, "upbound-system").DeleteFn(context.TODO(), tr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDeleteFn(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.deleted, deleted); diff != "" {
+				t.Errorf("\n%s\nDeleteFn(...): -want deleted, +got deleted:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestHTTPStateBackendDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		opts   []HTTPStateBackendOption
+		status int
+		auth   string
+		err    bool
+	}{
+		"Deleted": {
+			reason: "A successfully deleted state should not be an error.",
+			status: http.StatusOK,
+		},
+		"NotFound": {
+			reason: "A missing state should not be an error.",
+			status: http.StatusNotFound,
+		},
+		"Credentials": {
+			reason: "The state should be deleted with the configured credentials.",
+			opts:   []HTTPStateBackendOption{WithHTTPStateBackendCredentials("user", "pass")},
+			status: http.StatusNoContent,
+			auth:   "user:pass",
+		},
+		"DeleteFailed": {
+			reason: "An unexpected status code should be an error.",
+			status: http.StatusInternalServerError,
+			err:    true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var method, path, auth string
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method, path = r.Method, r.URL.Path
+				if u, p, ok := r.BasicAuth(); ok {
+					auth = u + ":" + p
+				}
+				w.WriteHeader(tc.status)
+			}))
+			defer srv.Close()
+			tr := &fake.Terraformed{}
+			tr.SetUID(types.UID("uid"))
+			// the test server's certificate is trusted only by its client
+			opts := append([]HTTPStateBackendOption{WithHTTPStateBackendClient(srv.Client())}, tc.opts...)
+			err := NewHTTPStateBackend(srv.URL+"/states/", opts...).DeleteFn(context.TODO(), tr)
+			if diff := cmp.Diff(tc.err, err != nil); diff != "" {
+				t.Errorf("\n%s\nDeleteFn(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(http.MethodDelete+" /states/uid", method+" "+path); diff != "" {
+				t.Errorf("\n%s\nDeleteFn(...): -want request, +got request:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.auth, auth); diff != "" {
+				t.Errorf("\n%s\nDeleteFn(...): -want credentials, +got credentials:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/upjet/pkg/config"
+	"github.com/crossplane/upjet/pkg/resource"
+	"github.com/crossplane/upjet/pkg/resource/json"
+)
+
+const (
+	// SecretKeyTFState is the key of the exported Terraform state in
+	// the Secrets of the SecretStateSink.
+	SecretKeyTFState = "terraform.tfstate"
+	// SecretKeyImportID is the key of the Terraform import ID of the orphaned
+	// resource in the Secrets of the SecretStateSink.
+	SecretKeyImportID = "importID"
+
+	fmtStateSecretName = "%s-%s-tfstate"
+
+	errExportSetup         = "cannot get the Terraform setup of the orphaned resource"
+	errExportParameters    = "cannot get the parameters of the orphaned resource"
+	errExportObservation   = "cannot get the observation of the orphaned resource"
+	errExportImportID      = "cannot get the Terraform import ID of the orphaned resource"
+	errStoreExportedState  = "cannot store the exported Terraform state"
+	errReadLastState       = "cannot read the last known Terraform state of the orphaned resource"
+	errExportState         = "cannot export the Terraform state of the orphaned resource"
+	errGetStateSecret      = "cannot get the Terraform state Secret"
+	errCreateStateSecret   = "cannot create the Terraform state Secret"
+	errUpdateStateSecret   = "cannot update the Terraform state Secret"
+	errUnexpectedExportObj = "the orphaned object is not a Terraformed resource"
+)
+
+// StateSink stores the Terraform states exported for the orphaned managed
+// resources.
+type StateSink interface {
+	// Store stores the specified Terraform state of the orphaned managed
+	// resource, which is in the terraform.tfstate v4 format, together with
+	// its Terraform import ID.
+	Store(ctx context.Context, tr resource.Terraformed, state []byte, importID string) error
+}
+
+// StateSinkFn is a function that implements the StateSink interface.
+type StateSinkFn func(ctx context.Context, tr resource.Terraformed, state []byte, importID string) error
+
+// Store calls the StateSinkFn.
+func (fn StateSinkFn) Store(ctx context.Context, tr resource.Terraformed, state []byte, importID string) error {
+	return fn(ctx, tr, state, importID)
+}
+
+// StateExporter exports the last known Terraform state of the managed
+// resources that are orphaned, so that their external resources can be
+// managed with Terraform again.
+type StateExporter interface {
+	Export(ctx context.Context, tr resource.Terraformed) error
+}
+
+// StateReader reads the last known Terraform states of the managed
+// resources, e.g., from their workspaces.
+type StateReader interface {
+	// ReadState returns the JSON encoded attributes and the private state
+	// of the last known Terraform state of the specified managed resource
+	// with the specified configuration, or nil attributes if there is no
+	// known state.
+	ReadState(ctx context.Context, tr resource.Terraformed, cfg *config.Resource) (attr, private []byte, err error)
+}
+
+// StateExporterFn is a function that implements the StateExporter interface.
+type StateExporterFn func(ctx context.Context, tr resource.Terraformed) error
+
+// Export calls the StateExporterFn.
+func (fn StateExporterFn) Export(ctx context.Context, tr resource.Terraformed) error {
+	return fn(ctx, tr)
+}
+
+// OrphanStateExporter is a StateExporter that exports the last known
+// Terraform states of the orphaned managed resources read with
+// a StateReader, or builds them from their observations, parameters and
+// private states if there are none, and stores them with a StateSink.
+type OrphanStateExporter struct {
+	kube              client.Client
+	setupFn           SetupFn
+	config            *config.Resource
+	sink              StateSink
+	cli               CLI
+	privateStateStore resource.PrivateStateStore
+	stateReader       StateReader
+}
+
+// OrphanStateExporterOption configures an OrphanStateExporter.
+type OrphanStateExporterOption func(e *OrphanStateExporter)
+
+// WithExporterCLI configures the Terraform CLI configuration, such as
+// the registry host, the exported states are built for.
+func WithExporterCLI(c CLI) OrphanStateExporterOption {
+	return func(e *OrphanStateExporter) {
+		e.cli = c
+	}
+}
+
+// WithExporterPrivateStateStore configures the store the private states
+// of the managed resources are read from. It should be the same store
+// configured for the workspace store with WithPrivateStateStore.
+func WithExporterPrivateStateStore(s resource.PrivateStateStore) OrphanStateExporterOption {
+	return func(e *OrphanStateExporter) {
+		e.privateStateStore = s
+	}
+}
+
+// WithExporterStateReader configures the StateReader the last known
+// Terraform states of the orphaned managed resources are read with, e.g.,
+// the WorkspaceStore of the CLI based controllers.
+func WithExporterStateReader(r StateReader) OrphanStateExporterOption {
+	return func(e *OrphanStateExporter) {
+		e.stateReader = r
+	}
+}
+
+// NewOrphanStateExporter returns a new OrphanStateExporter that stores the
+// exported states with the specified StateSink.
+func NewOrphanStateExporter(kube client.Client, sf SetupFn, cfg *config.Resource, sink StateSink, opts ...OrphanStateExporterOption) *OrphanStateExporter {
+	e := &OrphanStateExporter{
+		kube:              kube,
+		setupFn:           sf,
+		config:            cfg,
+		sink:              sink,
+		cli:               NewTerraformCLI(),
+		privateStateStore: resource.NewAnnotationPrivateStateStore(),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+// Export exports the last known Terraform state of the specified managed
+// resource, as read with the configured StateReader. If there is no known
+// state, the exported state is built like the one the Terraform workspaces
+// are initialized with and its "id" attribute is the import ID computed
+// with the GetIDFn of the resource's external name configuration, in which
+// case the sensitive parameters, which are referenced from Secrets, are
+// not exported.
+func (e *OrphanStateExporter) Export(ctx context.Context, tr resource.Terraformed) error {
+	ts, err := e.setupFn(ctx, e.kube, tr)
+	if err != nil {
+		return errors.Wrap(err, errExportSetup)
+	}
+	params, err := tr.GetParameters()
+	if err != nil {
+		return errors.Wrap(err, errExportParameters)
+	}
+	importID, err := e.config.ExternalName.GetIDFn(ctx, meta.GetExternalName(tr), params, ts.Map())
+	if err != nil {
+		return errors.Wrap(err, errExportImportID)
+	}
+	var attr, privateRaw []byte
+	if e.stateReader != nil {
+		if attr, privateRaw, err = e.stateReader.ReadState(ctx, tr, e.config); err != nil {
+			return errors.Wrap(err, errReadLastState)
+		}
+	}
+	if attr == nil {
+		if attr, privateRaw, err = e.buildState(ctx, tr, params, importID); err != nil {
+			return err
+		}
+	}
+	rawState, err := json.JSParser.Marshal(newResourceStateV4(tr, ts, e.cli.registryHost(), attr, privateRaw))
+	if err != nil {
+		return errors.Wrap(err, errMarshalState)
+	}
+	return errors.Wrap(e.sink.Store(ctx, tr, rawState, importID), errStoreExportedState)
+}
+
+// buildState builds the attributes and the private state of the specified
+// managed resource from its observation, its specified parameters and its
+// stored private state.
+func (e *OrphanStateExporter) buildState(ctx context.Context, tr resource.Terraformed, params map[string]any, importID string) ([]byte, []byte, error) {
+	obs, err := tr.GetObservation()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errExportObservation)
+	}
+	// the observation takes precedence over the parameters as in the states
+	// the workspaces are initialized with
+	base := make(map[string]any, len(params)+len(obs)+1)
+	for k, v := range params {
+		base[k] = v
+	}
+	for k, v := range obs {
+		base[k] = v
+	}
+	base["id"] = importID
+	attr, err := json.JSParser.Marshal(base)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errMarshalAttributes)
+	}
+	var privateRaw []byte
+	pr, err := e.privateStateStore.Get(ctx, tr)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errGetPrivateState)
+	}
+	if pr != "" {
+		privateRaw = []byte(pr)
+	}
+	privateRaw, err = insertTimeoutsMeta(privateRaw, timeouts(e.config.OperationTimeouts))
+	return attr, privateRaw, errors.Wrap(err, errInsertTimeouts)
+}
+
+// SecretStateSink is a StateSink that stores the exported Terraform states in
+// Secrets named <Terraform resource type>-<name>-tfstate, with the
+// underscores in the resource type replaced with dashes, in the configured
+// namespace. The Secrets are not owned by the managed resources so that they
+// outlive them.
+type SecretStateSink struct {
+	kube      client.Client
+	namespace string
+}
+
+// NewSecretStateSink returns a new SecretStateSink that stores the exported
+// states in the specified namespace.
+func NewSecretStateSink(kube client.Client, namespace string) *SecretStateSink {
+	return &SecretStateSink{
+		kube:      kube,
+		namespace: namespace,
+	}
+}
+
+// Store stores the exported state and the import ID in the Secret of
+// the orphaned managed resource.
+func (s *SecretStateSink) Store(ctx context.Context, tr resource.Terraformed, state []byte, importID string) error {
+	name := fmt.Sprintf(fmtStateSecretName, strings.ReplaceAll(tr.GetTerraformResourceType(), "_", "-"), tr.GetName())
+	data := map[string][]byte{
+		SecretKeyTFState:  state,
+		SecretKeyImportID: []byte(importID),
+	}
+	sec := &corev1.Secret{}
+	err := s.kube.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: name}, sec)
+	switch {
+	case kerrors.IsNotFound(err):
+		sec = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.namespace,
+				Name:      name,
+			},
+			Data: data,
+		}
+		return errors.Wrap(s.kube.Create(ctx, sec), errCreateStateSecret)
+	case err != nil:
+		return errors.Wrap(err, errGetStateSecret)
+	default:
+		sec.Data = data
+		return errors.Wrap(s.kube.Update(ctx, sec), errUpdateStateSecret)
+	}
+}
+
+// isOrphaned reports whether the external resource of the specified object
+// is orphaned, i.e., the object is being deleted without deleting its
+// external resource. It mirrors the decision of the managed reconciler.
+func isOrphaned(obj xpresource.Object) bool {
+	mg, ok := obj.(xpresource.Managed)
+	if !ok || !meta.WasDeleted(mg) || meta.GetExternalName(mg) == "" {
+		return false
+	}
+	// the management policies are empty only if the management policies
+	// feature is disabled, in which case they are ignored.
+	policies := mg.GetManagementPolicies()
+	return !managed.NewManagementPoliciesResolver(len(policies) != 0, policies, mg.GetDeletionPolicy()).ShouldDelete()
+}
+
+// ExportOrphanedState exports the Terraform state of the specified object
+// with the specified StateExporter if its external resource is orphaned.
+func ExportOrphanedState(ctx context.Context, e StateExporter, obj xpresource.Object) error {
+	if e == nil || !isOrphaned(obj) {
+		return nil
+	}
+	tr, ok := obj.(resource.Terraformed)
+	if !ok {
+		return errors.New(errUnexpectedExportObj)
+	}
+	return e.Export(ctx, tr)
+}
+
+// TryExportOrphanedState exports the Terraform state of the specified object
+// with ExportOrphanedState and logs the failure, if any, with the specified
+// logger. Exporting the state is best-effort and must not block the deletion
+// of the managed resource, e.g., if its ProviderConfig has already been
+// deleted.
+func TryExportOrphanedState(ctx context.Context, e StateExporter, obj xpresource.Object, l logging.Logger) {
+	if err := ExportOrphanedState(ctx, e, obj); err != nil {
+		l.Info(errExportState, "name", obj.GetName(), "error", err)
+	}
+}
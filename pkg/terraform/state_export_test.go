@@ -0,0 +1,226 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpmeta "github.com/crossplane/crossplane-runtime/pkg/meta"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	xpfake "github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/upjet/pkg/config"
+	"github.com/crossplane/upjet/pkg/resource"
+	"github.com/crossplane/upjet/pkg/resource/fake"
+	"github.com/crossplane/upjet/pkg/resource/json"
+)
+
+type exportedState struct {
+	attributes     map[string]any
+	resourceType   string
+	name           string
+	providerConfig string
+	importID       string
+}
+
+type stateReaderFn func(ctx context.Context, tr resource.Terraformed, cfg *config.Resource) ([]byte, []byte, error)
+
+func (fn stateReaderFn) ReadState(ctx context.Context, tr resource.Terraformed, cfg *config.Resource) ([]byte, []byte, error) {
+	return fn(ctx, tr, cfg)
+}
+
+func newOrphanedResource(deletionPolicy xpv1.DeletionPolicy) *fake.Terraformed {
+	now := metav1.Now()
+	return &fake.Terraformed{
+		Managed: xpfake.Managed{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "db",
+				DeletionTimestamp: &now,
+				Annotations: map[string]string{
+					xpmeta.AnnotationKeyExternalName: "db-id",
+				},
+			},
+			Orphanable: xpfake.Orphanable{Policy: deletionPolicy},
+		},
+		Observable: fake.Observable{
+			Observation: map[string]any{"arn": "arn:db"},
+		},
+		Parameterizable: fake.Parameterizable{
+			Parameters: map[string]any{"engine": "postgres"},
+		},
+		MetadataProvider: fake.MetadataProvider{
+			Type: "aws_db_instance",
+		},
+	}
+}
+
+func TestExportOrphanedState(t *testing.T) {
+	setupFn := func(_ context.Context, _ client.Client, _ xpresource.Managed) (Setup, error) {
+		return Setup{
+			Version:     "1.5.5",
+			Requirement: ProviderRequirement{Source: "hashicorp/aws"},
+		}, nil
+	}
+	cfg := &config.Resource{ExternalName: config.IdentifierFromProvider}
+	type args struct {
+		setupFn     SetupFn
+		stateReader StateReader
+		obj         *fake.Terraformed
+	}
+	type want struct {
+		state *exportedState
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"Orphaned": {
+			reason: "The state of an orphaned resource should be exported with its import ID.",
+			args: args{
+				setupFn: setupFn,
+				obj:     newOrphanedResource(xpv1.DeletionOrphan),
+			},
+			want: want{
+				state: &exportedState{
+					attributes: map[string]any{
+						"arn":    "arn:db",
+						"engine": "postgres",
+						"id":     "db-id",
+					},
+					resourceType:   "aws_db_instance",
+					name:           "db",
+					providerConfig: `provider["registry.terraform.io/hashicorp/aws"]`,
+					importID:       "db-id",
+				},
+			},
+		},
+		"LastKnownState": {
+			reason: "The last known state of an orphaned resource should be exported as is if there is one.",
+			args: args{
+				setupFn: setupFn,
+				stateReader: stateReaderFn(func(_ context.Context, _ resource.Terraformed, _ *config.Resource) ([]byte, []byte, error) {
+					return []byte(`{"arn":"arn:db","engine":"postgres","id":"db-id","password":"secret"}`), nil, nil
+				}),
+				obj: newOrphanedResource(xpv1.DeletionOrphan),
+			},
+			want: want{
+				state: &exportedState{
+					attributes: map[string]any{
+						"arn":      "arn:db",
+						"engine":   "postgres",
+						"id":       "db-id",
+						"password": "secret",
+					},
+					resourceType:   "aws_db_instance",
+					name:           "db",
+					providerConfig: `provider["registry.terraform.io/hashicorp/aws"]`,
+					importID:       "db-id",
+				},
+			},
+		},
+		"ReadStateFailed": {
+			reason: "The errors of reading the last known state should be returned.",
+			args: args{
+				setupFn: setupFn,
+				stateReader: stateReaderFn(func(_ context.Context, _ resource.Terraformed, _ *config.Resource) ([]byte, []byte, error) {
+					return nil, nil, errBoom
+				}),
+				obj: newOrphanedResource(xpv1.DeletionOrphan),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errReadLastState),
+			},
+		},
+		"Deleted": {
+			reason: "The state of a resource whose external resource is deleted should not be exported.",
+			args: args{
+				setupFn: setupFn,
+				obj:     newOrphanedResource(xpv1.DeletionDelete),
+			},
+		},
+		"DeletedByManagementPolicies": {
+			reason: "The state of a resource whose management policies include the Delete action should not be exported even with the Orphan deletion policy.",
+			args: args{
+				setupFn: setupFn,
+				obj: func() *fake.Terraformed {
+					tr := newOrphanedResource(xpv1.DeletionOrphan)
+					tr.SetManagementPolicies(xpv1.ManagementPolicies{xpv1.ManagementActionObserve, xpv1.ManagementActionDelete})
+					return tr
+				}(),
+			},
+		},
+		"OrphanedByManagementPolicies": {
+			reason: "The state of a resource whose management policies do not include the Delete action should be exported.",
+			args: args{
+				setupFn: setupFn,
+				obj: func() *fake.Terraformed {
+					tr := newOrphanedResource(xpv1.DeletionDelete)
+					tr.SetManagementPolicies(xpv1.ManagementPolicies{xpv1.ManagementActionObserve})
+					return tr
+				}(),
+			},
+			want: want{
+				state: &exportedState{
+					attributes: map[string]any{
+						"arn":    "arn:db",
+						"engine": "postgres",
+						"id":     "db-id",
+					},
+					resourceType:   "aws_db_instance",
+					name:           "db",
+					providerConfig: `provider["registry.terraform.io/hashicorp/aws"]`,
+					importID:       "db-id",
+				},
+			},
+		},
+		"SetupFailed": {
+			reason: "The errors of the Terraform setup should be returned.",
+			args: args{
+				setupFn: func(_ context.Context, _ client.Client, _ xpresource.Managed) (Setup, error) {
+					return Setup{}, errBoom
+				},
+				obj: newOrphanedResource(xpv1.DeletionOrphan),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errExportSetup),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got *exportedState
+			sink := StateSinkFn(func(_ context.Context, tr resource.Terraformed, state []byte, importID string) error {
+				s := &json.StateV4{}
+				if err := json.JSParser.Unmarshal(state, s); err != nil {
+					return err
+				}
+				got = &exportedState{
+					resourceType:   s.Resources[0].Type,
+					name:           s.Resources[0].Name,
+					providerConfig: s.Resources[0].ProviderConfig,
+					importID:       importID,
+				}
+				return json.JSParser.Unmarshal(s.Resources[0].Instances[0].AttributesRaw, &got.attributes)
+			})
+			e := NewOrphanStateExporter(nil, tc.args.setupFn, cfg, sink, WithExporterStateReader(tc.args.stateReader))
+			err := ExportOrphanedState(context.TODO(), e, tc.args.obj)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nExportOrphanedState(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.state, got, cmp.AllowUnexported(exportedState{})); diff != "" {
+				t.Errorf("\n%s\nExportOrphanedState(...): -want state, +got state:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	iofs "io/fs"
 	"os"
 	"path/filepath"
 	"sort"
@@ -29,10 +30,17 @@ import (
 	"github.com/crossplane/upjet/pkg/config"
 	"github.com/crossplane/upjet/pkg/metrics"
 	"github.com/crossplane/upjet/pkg/resource"
+	"github.com/crossplane/upjet/pkg/resource/json"
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
 )
 
 const (
-	errGetID = "cannot get id"
+	// stateDeleteTimeout is the timeout of deleting the remote state of
+	// a removed workspace.
+	stateDeleteTimeout = 1 * time.Minute
+
+	errGetID                   = "cannot get id"
+	errStateBackendDisableInit = "a remote Terraform state backend cannot be used when the workspace initialization is disabled"
 )
 
 // SetupFn is a function that returns Terraform setup which contains
@@ -121,7 +129,17 @@ type Setup struct {
 	// the Terraform CLI.
 	Scheduler ProviderScheduler
 
+	// Meta is the configured Terraform provider meta used by the no-fork
+	// external clients. A ProviderMetaPool can be used to share the metas
+	// among the managed resources with the same provider configuration.
 	Meta any
+
+	// CredentialsExpiry is the time the temporary credentials in the Setup,
+	// e.g., the tokens obtained with an STS or OIDC token exchange, expire
+	// at. A Setup cached with NewCachingSetupFn expires at the earlier of
+	// this time and the end of the cache TTL. The zero value means that
+	// the credentials do not expire.
+	CredentialsExpiry time.Time
 }
 
 // Map returns the Setup object in map form. The initial reason was so that
@@ -174,15 +192,70 @@ func WithFeatures(f *feature.Flags) WorkspaceStoreOption {
 	}
 }
 
+// WithCLI sets the CLI, e.g., Terraform or OpenTofu, the workspaces of
+// the WorkspaceStore are run with. Defaults to Terraform.
+func WithCLI(c CLI) WorkspaceStoreOption {
+	return func(ws *WorkspaceStore) {
+		ws.cli = c
+	}
+}
+
+// WithBackend sets the Backend the workspaces of the WorkspaceStore run
+// their operations with. If not set, the workspaces run the configured CLI.
+func WithBackend(b Backend) WorkspaceStoreOption {
+	return func(ws *WorkspaceStore) {
+		ws.backend = b
+	}
+}
+
+// WithErrorClassifier sets the Classifier the errors of the operations of
+// the workspaces are classified with, e.g., to tell the terminal errors
+// from the retryable ones. Defaults to a Classifier with
+// the DefaultClassificationRules.
+func WithErrorClassifier(c *tferrors.Classifier) WorkspaceStoreOption {
+	return func(ws *WorkspaceStore) {
+		ws.classifier = c
+	}
+}
+
+// WithPrivateStateStore sets the store the private states of the managed
+// resources are read from when producing the Terraform states of their
+// workspaces. Defaults to the annotations of the managed resources.
+func WithPrivateStateStore(s resource.PrivateStateStore) WorkspaceStoreOption {
+	return func(ws *WorkspaceStore) {
+		ws.privateStateStore = s
+	}
+}
+
+// WithStateBackend configures the Terraform backend the workspaces store
+// their states in. If not set, the states are stored in the local
+// filesystem together with the workspaces. The states of the removed
+// workspaces are deleted from the backend with its DeleteFn, if any.
+// A remote backend requires the workspaces to be initialized, i.e., it cannot
+// be used together with WithDisableInit, in which case the workspaces
+// cannot be prepared.
+func WithStateBackend(b StateBackend) WorkspaceStoreOption {
+	return func(ws *WorkspaceStore) {
+		ws.stateBackend = &b
+	}
+}
+
 // NewWorkspaceStore returns a new WorkspaceStore.
 func NewWorkspaceStore(l logging.Logger, opts ...WorkspaceStoreOption) *WorkspaceStore {
 	ws := &WorkspaceStore{
-		store:    map[types.UID]*Workspace{},
-		logger:   l,
-		mu:       sync.Mutex{},
-		fs:       afero.Afero{Fs: afero.NewOsFs()},
-		executor: exec.New(),
-		features: &feature.Flags{},
+		store:      map[types.UID]*Workspace{},
+		logger:     l,
+		mu:         sync.Mutex{},
+		fs:         afero.Afero{Fs: afero.NewOsFs()},
+		executor:   exec.New(),
+		features:   &feature.Flags{},
+		cli:        NewTerraformCLI(),
+		classifier: tferrors.NewClassifier(tferrors.DefaultClassificationRules()...),
+
+		privateStateStore: resource.NewAnnotationPrivateStateStore(),
+		lockFiles:         map[string][]byte{},
+		initMus:           map[string]*sync.Mutex{},
+		removing:          map[types.UID]chan struct{}{},
 	}
 	for _, f := range opts {
 		f(ws)
@@ -191,6 +264,9 @@ func NewWorkspaceStore(l logging.Logger, opts ...WorkspaceStoreOption) *Workspac
 	if ws.processReportInterval != 0 {
 		go ws.reportTFProcesses(ws.processReportInterval)
 	}
+	if ws.sweepInterval != 0 {
+		go ws.sweepWorkspaces(ws.sweepInterval)
+	}
 	return ws
 }
 
@@ -208,30 +284,63 @@ type WorkspaceStore struct {
 	executor              exec.Interface
 	disableInit           bool
 	features              *feature.Flags
+	cli                   CLI
+	backend               Backend
+	classifier            *tferrors.Classifier
+	stateBackend          *StateBackend
+	sweepInterval         time.Duration
+	managedUIDsFn         ManagedUIDsFn
+	maxDiskUsage          int64
+	pluginCache           *PluginCache
+	cliConfigOnce         sync.Once
+	cliConfigPath         string
+	cliConfigErr          error
+	lockFilesMu           sync.RWMutex
+	lockFiles             map[string][]byte
+	// initMus serialize the initialization of the workspaces of a provider
+	// requirement that share the plugin cache. Guarded by lockFilesMu.
+	initMus           map[string]*sync.Mutex
+	privateStateStore resource.PrivateStateStore
+	// removing holds the workspaces being removed by the sweeper, which
+	// cannot be loaded until their directories are removed.
+	removing map[types.UID]chan struct{}
 }
 
 // Workspace makes sure the Terraform workspace for the given resource is ready
 // to be used and returns the Workspace object configured to work in that
 // workspace folder in the filesystem.
 func (ws *WorkspaceStore) Workspace(ctx context.Context, c resource.SecretClient, tr resource.Terraformed, ts Setup, cfg *config.Resource) (*Workspace, error) { //nolint:gocyclo
+	// the remote state is only available after the workspace is initialized
+	// with its backend, so it cannot be used without the initialization.
+	if ws.stateBackend != nil && ws.disableInit {
+		return nil, errors.New(errStateBackendDisableInit)
+	}
 	dir := filepath.Join(ws.fs.GetTempDir(""), string(tr.GetUID()))
+	env, err := ws.cliConfigEnv()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot configure the plugin cache")
+	}
+	w, loaded, err := ws.load(ctx, tr.GetUID(), func() *Workspace {
+		l := ws.logger.WithValues("workspace", dir)
+		return NewWorkspace(dir, WithLogger(l), WithExecutor(ws.executor), WithWorkspaceCLI(ws.cli), WithWorkspaceBackend(ws.backend), WithWorkspaceErrorClassifier(ws.classifier), WithRemoteState(ws.stateBackend != nil), WithEnv(env...), WithFilterFn(ts.filterSensitiveInformation))
+	})
+	if err != nil {
+		return nil, err
+	}
 	if err := ws.fs.MkdirAll(dir, os.ModePerm); err != nil {
 		return nil, errors.Wrap(err, "cannot create directory for workspace")
 	}
-	ws.mu.Lock()
-	w, ok := ws.store[tr.GetUID()]
-	if !ok {
-		l := ws.logger.WithValues("workspace", dir)
-		ws.store[tr.GetUID()] = NewWorkspace(dir, WithLogger(l), WithExecutor(ws.executor), WithFilterFn(ts.filterSensitiveInformation))
-		w = ws.store[tr.GetUID()]
+	if !loaded {
+		if err := ws.recordKind(dir, tr); err != nil {
+			return nil, err
+		}
 	}
-	ws.mu.Unlock()
 	// If there is an ongoing operation, no changes should be made in the
 	// workspace files.
 	if w.LastOperation.IsRunning() {
 		return w, nil
 	}
-	fp, err := NewFileProducer(ctx, c, dir, tr, ts, cfg, WithFileProducerFeatures(ws.features))
+	fp, err := NewFileProducer(ctx, c, dir, tr, ts, cfg, WithFileProducerFeatures(ws.features), WithFileProducerCLI(ws.cli), WithFileProducerStateBackend(ws.stateBackend), WithFileProducerPrivateStateStore(ws.privateStateStore))
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot create a new file producer")
 	}
@@ -241,8 +350,12 @@ func (ws *WorkspaceStore) Workspace(ctx context.Context, c resource.SecretClient
 		return nil, errors.Wrap(err, errGetID)
 	}
 
-	if err := fp.EnsureTFState(ctx, w.terraformID); err != nil {
-		return nil, errors.Wrap(err, "cannot ensure tfstate file")
+	// The remote state is available only after the workspace is
+	// initialized with its backend.
+	if ws.stateBackend == nil {
+		if err := fp.EnsureTFState(ctx, w.terraformID); err != nil {
+			return nil, errors.Wrap(err, "cannot ensure tfstate file")
+		}
 	}
 
 	isNeedProviderUpgrade := false
@@ -257,31 +370,110 @@ func (ws *WorkspaceStore) Workspace(ctx context.Context, c resource.SecretClient
 		return nil, errors.Wrap(err, "cannot write main tf file")
 	}
 	if isNeedProviderUpgrade {
-		out, err := w.runTF(ctx, ModeSync, "init", "-upgrade", "-input=false")
-		w.logger.Debug("init -upgrade ended", "out", ts.filterSensitiveInformation(string(out)))
-		if err != nil {
-			return w, errors.Wrapf(err, "cannot upgrade workspace: %s", ts.filterSensitiveInformation(string(out)))
+		if err := ws.initWorkspace(ctx, w, dir, ts.Requirement, true); err != nil {
+			return w, err
 		}
 	}
 	if ws.disableInit {
 		return w, nil
 	}
-	_, err = ws.fs.Stat(filepath.Join(dir, ".terraform.lock.hcl"))
-	if xpresource.Ignore(os.IsNotExist, err) != nil {
-		return nil, errors.Wrap(err, "cannot stat init lock file")
+	initialized, err := ws.isInitialized(dir)
+	if err != nil {
+		return nil, err
 	}
 	// We need to initialize only if the workspace hasn't been initialized yet.
-	if !os.IsNotExist(err) {
+	if !initialized {
+		if err := ws.initWorkspace(ctx, w, dir, ts.Requirement, false); err != nil {
+			return w, err
+		}
+	}
+	if ws.stateBackend == nil {
 		return w, nil
 	}
-	out, err := w.runTF(ctx, ModeSync, "init", "-input=false")
-	w.logger.Debug("init ended", "out", ts.filterSensitiveInformation(string(out)))
-	return w, errors.Wrapf(err, "cannot init workspace: %s", ts.filterSensitiveInformation(string(out)))
+	return w, errors.Wrap(w.EnsureRemoteState(ctx, fp), "cannot ensure remote tfstate")
+}
+
+// initWorkspace initializes, or upgrades if upgrade is true, the specified
+// workspace in the specified directory for the specified provider
+// requirement. The lock file recorded for the provider requirement, if any,
+// is seeded before a workspace is initialized, and the resulting lock file
+// is recorded afterwards. When a plugin cache is configured, the workspaces
+// of a provider requirement are initialized one at a time, because
+// the plugin cache is not safe for concurrent use by the Terraform CLI.
+func (ws *WorkspaceStore) initWorkspace(ctx context.Context, w *Workspace, dir string, r ProviderRequirement, upgrade bool) error {
+	unlock := ws.lockInit(r)
+	defer unlock()
+	if !upgrade {
+		if err := ws.seedLockFile(dir, r); err != nil {
+			return err
+		}
+	}
+	if err := w.Init(ctx, upgrade); err != nil {
+		if upgrade {
+			return errors.Wrap(err, "cannot upgrade workspace")
+		}
+		return errors.Wrap(err, "cannot init workspace")
+	}
+	if err := ws.markInitialized(dir); err != nil {
+		return err
+	}
+	return ws.recordLockFile(dir, r)
+}
+
+// load returns the workspace with the specified UID from the store, or stores
+// the one returned by the specified function if there is none, and marks it
+// as used so that the sweeper does not remove it. It waits for the workspace
+// to be removed if the sweeper is removing it. Returns whether the workspace
+// has been loaded from the store.
+func (ws *WorkspaceStore) load(ctx context.Context, uid types.UID, newFn func() *Workspace) (*Workspace, bool, error) {
+	ws.mu.Lock()
+	for {
+		done, ok := ws.removing[uid]
+		if !ok {
+			break
+		}
+		ws.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, false, errors.Wrap(ctx.Err(), "cannot wait for the workspace to be removed")
+		}
+		ws.mu.Lock()
+	}
+	defer ws.mu.Unlock()
+	w, ok := ws.store[uid]
+	if !ok {
+		w = newFn()
+		ws.store[uid] = w
+	}
+	w.lastUsed = time.Now()
+	return w, ok, nil
+}
+
+// recordKind records the kind of the specified managed resource in its
+// workspace directory, so that the sweeper can tell the kind of
+// the workspace.
+func (ws *WorkspaceStore) recordKind(dir string, tr resource.Terraformed) error {
+	gk := tr.GetObjectKind().GroupVersionKind().GroupKind()
+	if gk.Empty() {
+		return nil
+	}
+	return errors.Wrap(ws.fs.WriteFile(filepath.Join(dir, kindFile), []byte(gk.String()), 0600), "cannot record the kind of the workspace")
 }
 
 // Remove deletes the workspace directory from the filesystem and erases its
-// record from the store.
+// record from the store. If a remote state backend with a DeleteFn is
+// configured, the remote state of the workspace is deleted, too, even if
+// the workspace has not been loaded into the store since the provider
+// started, unless the external resource of the object is orphaned.
 func (ws *WorkspaceStore) Remove(obj xpresource.Object) error {
+	if ws.stateBackend != nil && ws.stateBackend.DeleteFn != nil && !isOrphaned(obj) {
+		ctx, cancel := context.WithTimeout(context.Background(), stateDeleteTimeout)
+		defer cancel()
+		if err := ws.stateBackend.DeleteFn(ctx, obj); err != nil {
+			return errors.Wrap(err, "cannot delete the remote Terraform state")
+		}
+	}
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
 	w, ok := ws.store[obj.GetUID()]
@@ -295,6 +487,40 @@ func (ws *WorkspaceStore) Remove(obj xpresource.Object) error {
 	return nil
 }
 
+// CLI returns the Terraform CLI configuration of the workspaces.
+func (ws *WorkspaceStore) CLI() CLI {
+	return ws.cli
+}
+
+// PrivateStateStore returns the store the private states of the managed
+// resources are read from.
+func (ws *WorkspaceStore) PrivateStateStore() resource.PrivateStateStore {
+	return ws.privateStateStore
+}
+
+// ReadState reads the attributes and the private state of the last known
+// Terraform state of the specified managed resource from the local state
+// file of its workspace. Returns nil attributes if the workspace has no
+// local state file, e.g., if its state is stored in a remote backend.
+func (ws *WorkspaceStore) ReadState(_ context.Context, tr resource.Terraformed, _ *config.Resource) ([]byte, []byte, error) {
+	data, err := ws.fs.ReadFile(filepath.Join(ws.fs.GetTempDir(""), string(tr.GetUID()), "terraform.tfstate"))
+	if errors.Is(err, iofs.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errReadTFState)
+	}
+	s := &json.StateV4{}
+	if err := json.JSParser.Unmarshal(data, s); err != nil {
+		return nil, nil, errors.Wrap(err, errUnmarshalTFState)
+	}
+	attr := s.GetAttributes()
+	if attr == nil {
+		return nil, nil, nil
+	}
+	return attr, s.GetPrivateRaw(), nil
+}
+
 func (ws *WorkspaceStore) initMetrics() {
 	for _, mode := range []ExecMode{ModeSync, ModeASync} {
 		for _, subcommand := range []string{"init", "apply", "destroy", "plan"} {
@@ -316,6 +542,7 @@ func (ws *WorkspaceStore) reportTFProcesses(interval time.Duration) {
 	for _, t := range []string{"cli", "provider"} {
 		metrics.TFProcesses.WithLabelValues(t).Set(0)
 	}
+	cli := ws.cli.binary()
 	t := time.NewTicker(interval)
 	for range t.C {
 		processes, err := ps.Processes()
@@ -327,7 +554,7 @@ func (ws *WorkspaceStore) reportTFProcesses(interval time.Duration) {
 		for _, p := range processes {
 			e := p.Executable()
 			switch {
-			case e == "terraform":
+			case e == filepath.Base(cli):
 				cliCount++
 			case strings.HasPrefix(e, "terraform-"):
 				providerCount++
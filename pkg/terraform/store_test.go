@@ -0,0 +1,239 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/upjet/pkg/config"
+	"github.com/crossplane/upjet/pkg/resource/fake"
+)
+
+func TestWorkspaceStoreStateBackendDisableInit(t *testing.T) {
+	ws := NewWorkspaceStore(logging.NewNopLogger(), WithFs(afero.NewMemMapFs()), WithDisableInit(true), WithStateBackend(StateBackend{Type: "s3"}))
+	_, err := ws.Workspace(context.TODO(), nil, &fake.Terraformed{}, Setup{}, nil)
+	if diff := cmp.Diff(errors.New(errStateBackendDisableInit), err, test.EquateErrors()); diff != "" {
+		t.Errorf("\nWorkspace(...): -want error, +got error:\n%s", diff)
+	}
+}
+
+// initBackend is a Backend whose Init operation records the maximum number
+// of the concurrently running initializations.
+type initBackend struct {
+	*CLIBackend
+	fs afero.Fs
+
+	mu           sync.Mutex
+	running, max int
+	initialized  []string
+}
+
+func (b *initBackend) Init(_ context.Context, inv Invocation, _ bool) error {
+	b.mu.Lock()
+	b.running++
+	if b.running > b.max {
+		b.max = b.running
+	}
+	b.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	b.mu.Lock()
+	b.running--
+	b.initialized = append(b.initialized, inv.Dir)
+	b.mu.Unlock()
+	return afero.WriteFile(b.fs, filepath.Join(inv.Dir, lockFile), []byte("lock"), 0600)
+}
+
+func TestWorkspaceStoreConcurrentInit(t *testing.T) {
+	// the Terraform configuration files of the workspaces are written into
+	// the OS temporary directory.
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	fs := afero.NewOsFs()
+	b := &initBackend{fs: fs}
+	ws := NewWorkspaceStore(logging.NewNopLogger(), WithFs(fs), WithPluginCache(PluginCache{Dir: filepath.Join(tmp, "plugins")}), WithBackend(b))
+	cfg := config.DefaultResource("upjet_resource", nil, nil)
+	ts := Setup{Requirement: ProviderRequirement{Source: "hashicorp/aws", Version: "5.0.0"}}
+	const workspaces = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, workspaces)
+	for i := 0; i < workspaces; i++ {
+		tr := &fake.Terraformed{Parameterizable: fake.Parameterizable{Parameters: map[string]any{}}}
+		tr.SetUID(types.UID(fmt.Sprintf("uid-%d", i)))
+		meta.SetExternalName(tr, "name")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ws.Workspace(context.TODO(), nil, tr, ts, cfg)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Workspace(...): unexpected error: %v", err)
+		}
+	}
+	if diff := cmp.Diff(workspaces, len(b.initialized)); diff != "" {
+		t.Errorf("Workspace(...): -want initialized workspaces, +got initialized workspaces:\n%s", diff)
+	}
+	if diff := cmp.Diff(1, b.max); diff != "" {
+		t.Errorf("Workspace(...): the workspaces sharing the plugin cache should be initialized one at a time: -want concurrent inits, +got concurrent inits:\n%s", diff)
+	}
+}
+
+func TestWorkspaceStoreRemove(t *testing.T) {
+	errBoom := errors.New("boom")
+	type args struct {
+		deleteErr error
+		stored    bool
+		orphaned  bool
+	}
+	type want struct {
+		deleted bool
+		stored  bool
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"Removed": {
+			reason: "Both the remote state and the workspace should be removed.",
+			args: args{
+				stored: true,
+			},
+			want: want{
+				deleted: true,
+			},
+		},
+		"NotStored": {
+			reason: "The remote state should be deleted even if the workspace has not been loaded into the store.",
+			want: want{
+				deleted: true,
+			},
+		},
+		"Orphaned": {
+			reason: "The remote state should be kept if the external resource is orphaned.",
+			args: args{
+				orphaned: true,
+				stored:   true,
+			},
+		},
+		"DeleteFailed": {
+			reason: "The workspace should be kept if the remote state cannot be deleted, so that the removal is retried.",
+			args: args{
+				deleteErr: errBoom,
+				stored:    true,
+			},
+			want: want{
+				deleted: true,
+				stored:  true,
+				err:     errors.Wrap(errBoom, "cannot delete the remote Terraform state"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			uid := types.UID("uid")
+			deleted := false
+			b := StateBackend{
+				Type: "kubernetes",
+				DeleteFn: func(_ context.Context, obj xpresource.Object) error {
+					deleted = obj.GetUID() == uid
+					return tc.args.deleteErr
+				},
+			}
+			ws := NewWorkspaceStore(logging.NewNopLogger(), WithFs(afero.NewMemMapFs()), WithStateBackend(b))
+			if tc.args.stored {
+				ws.store[uid] = NewWorkspace("/" + string(uid))
+			}
+			tr := &fake.Terraformed{}
+			tr.SetUID(uid)
+			if tc.args.orphaned {
+				now := metav1.Now()
+				tr.SetDeletionTimestamp(&now)
+				tr.SetDeletionPolicy(xpv1.DeletionOrphan)
+				meta.SetExternalName(tr, "name")
+			}
+			err := ws.Remove(tr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRemove(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.deleted, deleted); diff != "" {
+				t.Errorf("\n%s\nRemove(...): -want deleted, +got deleted:\n%s", tc.reason, diff)
+			}
+			_, stored := ws.store[uid]
+			if diff := cmp.Diff(tc.want.stored, stored); diff != "" {
+				t.Errorf("\n%s\nRemove(...): -want stored, +got stored:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWorkspaceStoreReadState(t *testing.T) {
+	type want struct {
+		attr    string
+		private string
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		state  string
+		want
+	}{
+		"StateFile": {
+			reason: "The attributes and the private state should be read from the local state file of the workspace.",
+			state:  `{"version":4,"resources":[{"mode":"managed","type":"aws_db_instance","name":"db","instances":[{"attributes":{"id":"db-id","password":"secret"},"private":"eyJzY2hlbWFfdmVyc2lvbiI6IjEifQ=="}]}]}`,
+			want: want{
+				attr:    `{"id":"db-id","password":"secret"}`,
+				private: `{"schema_version":"1"}`,
+			},
+		},
+		"NoStateFile": {
+			reason: "No attributes should be returned if the workspace has no local state file.",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			ws := NewWorkspaceStore(logging.NewNopLogger(), WithFs(fs))
+			tr := &fake.Terraformed{}
+			tr.SetUID("uid")
+			if tc.state != "" {
+				if err := afero.WriteFile(fs, filepath.Join(afero.GetTempDir(fs, ""), "uid", "terraform.tfstate"), []byte(tc.state), 0600); err != nil {
+					t.Fatalf("cannot write the state file: %v", err)
+				}
+			}
+			attr, private, err := ws.ReadState(context.TODO(), tr, nil)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReadState(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.attr, string(attr)); diff != "" {
+				t.Errorf("\n%s\nReadState(...): -want attributes, +got attributes:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.private, string(private)); diff != "" {
+				t.Errorf("\n%s\nReadState(...): -want private, +got private:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
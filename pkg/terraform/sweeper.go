@@ -0,0 +1,247 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	iofs "io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/upjet/pkg/metrics"
+)
+
+// workspace directories are named after the UIDs of their managed resources
+var reWorkspaceDir = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// kindFile is the file the kind of the managed resource of a workspace is
+// recorded in, so that the sweeper removes only the workspaces of the kinds
+// whose managed resources have been listed.
+const kindFile = ".upjet-kind"
+
+// ManagedUIDsFn returns the UIDs of the existing managed resources
+// the workspaces are kept for, together with the kinds of the managed
+// resources that have been listed. Only the workspaces of the listed kinds
+// are removed if their managed resources do not exist.
+type ManagedUIDsFn func(ctx context.Context) (map[types.UID]struct{}, []schema.GroupKind, error)
+
+// NewManagedUIDsFn returns a ManagedUIDsFn that lists the metadata of
+// the managed resources of the specified kinds with the supplied client.
+func NewManagedUIDsFn(c client.Reader, gvks ...schema.GroupVersionKind) ManagedUIDsFn {
+	return func(ctx context.Context) (map[types.UID]struct{}, []schema.GroupKind, error) {
+		uids := make(map[types.UID]struct{})
+		kinds := make([]schema.GroupKind, 0, len(gvks))
+		for _, gvk := range gvks {
+			l := &metav1.PartialObjectMetadataList{}
+			l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+			if err := c.List(ctx, l); err != nil {
+				return nil, nil, errors.Wrapf(err, "cannot list the managed resources of kind %s", gvk.String())
+			}
+			for _, o := range l.Items {
+				uids[o.GetUID()] = struct{}{}
+			}
+			kinds = append(kinds, gvk.GroupKind())
+		}
+		return uids, kinds, nil
+	}
+}
+
+// WithWorkspaceSweeper enables the periodic sweeping of the workspace
+// directories with the specified interval. The directories of the managed
+// resources of the listed kinds that are not reported by the supplied
+// ManagedUIDsFn are removed, e.g., the directories of the resources deleted
+// while the provider was down. The directories whose kind is unknown, e.g.,
+// the ones not used since the provider was upgraded, are kept. If
+// the ManagedUIDsFn is nil, only the disk usage limit configured with
+// WithMaxDiskUsage is enforced. The
+// upjet_terraform_workspace_disk_usage_bytes metric is reported with each
+// sweep.
+func WithWorkspaceSweeper(interval time.Duration, fn ManagedUIDsFn) WorkspaceStoreOption {
+	return func(ws *WorkspaceStore) {
+		ws.sweepInterval = interval
+		ws.managedUIDsFn = fn
+	}
+}
+
+// WithMaxDiskUsage configures the maximum total size, in bytes, of
+// the workspace directories. When the limit is exceeded, the least recently
+// used idle workspaces are removed by the workspace sweeper until the total
+// size falls below the limit. The removed workspaces are recreated when
+// they are needed again. A non-positive limit means no limit.
+func WithMaxDiskUsage(bytes int64) WorkspaceStoreOption {
+	return func(ws *WorkspaceStore) {
+		ws.maxDiskUsage = bytes
+	}
+}
+
+func (ws *WorkspaceStore) sweepWorkspaces(interval time.Duration) {
+	t := time.NewTicker(interval)
+	for range t.C {
+		if err := ws.Sweep(context.Background()); err != nil {
+			ws.logger.Info("Failed to sweep the workspaces", "err", err)
+		}
+	}
+}
+
+type workspaceDir struct {
+	uid      types.UID
+	size     int64
+	lastUsed time.Time
+}
+
+// Sweep removes the workspace directories of the managed resources that no
+// longer exist and then, if the total size of the workspace directories
+// exceeds the configured limit, removes the least recently used idle
+// workspaces. The workspaces used more recently than the sweep interval are
+// never removed, so that the sweeper does not race with the reconcilers.
+func (ws *WorkspaceStore) Sweep(ctx context.Context) error { //nolint:gocyclo
+	var uids map[types.UID]struct{}
+	var kinds map[string]struct{}
+	if ws.managedUIDsFn != nil {
+		var gks []schema.GroupKind
+		var err error
+		if uids, gks, err = ws.managedUIDsFn(ctx); err != nil {
+			return errors.Wrap(err, "cannot get the UIDs of the managed resources")
+		}
+		kinds = make(map[string]struct{}, len(gks))
+		for _, gk := range gks {
+			kinds[gk.String()] = struct{}{}
+		}
+	}
+	root := ws.fs.GetTempDir("")
+	entries, err := ws.fs.ReadDir(root)
+	if err != nil {
+		return errors.Wrap(err, "cannot read the workspaces directory")
+	}
+	dirs := make([]workspaceDir, 0, len(entries))
+	var total int64
+	for _, e := range entries {
+		if !e.IsDir() || !reWorkspaceDir.MatchString(e.Name()) {
+			continue
+		}
+		uid := types.UID(e.Name())
+		dir := filepath.Join(root, e.Name())
+		orphaned, err := ws.isOrphaned(uid, dir, uids, kinds)
+		if err != nil {
+			return err
+		}
+		if orphaned {
+			removed, err := ws.removeIdle(uid, dir)
+			if err != nil {
+				return err
+			}
+			if removed {
+				ws.logger.Debug("Removed the workspace of a nonexistent managed resource", "uid", uid)
+				continue
+			}
+		}
+		size, err := ws.dirSize(dir)
+		if err != nil {
+			return err
+		}
+		total += size
+		dirs = append(dirs, workspaceDir{uid: uid, size: size, lastUsed: ws.lastUsed(uid)})
+	}
+	if ws.maxDiskUsage > 0 && total > ws.maxDiskUsage {
+		sort.Slice(dirs, func(i, j int) bool {
+			return dirs[i].lastUsed.Before(dirs[j].lastUsed)
+		})
+		for _, d := range dirs {
+			if total <= ws.maxDiskUsage {
+				break
+			}
+			removed, err := ws.removeIdle(d.uid, filepath.Join(root, string(d.uid)))
+			if err != nil {
+				return err
+			}
+			if removed {
+				ws.logger.Debug("Removed a workspace to enforce the disk usage limit", "uid", d.uid, "size", d.size)
+				total -= d.size
+			}
+		}
+	}
+	metrics.WorkspaceDiskUsage.Set(float64(total))
+	return nil
+}
+
+func (ws *WorkspaceStore) lastUsed(uid types.UID) time.Time {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if w, ok := ws.store[uid]; ok {
+		return w.lastUsed
+	}
+	return time.Time{}
+}
+
+// isOrphaned reports whether the workspace with the specified UID and
+// directory belongs to a managed resource of one of the specified listed
+// kinds that does not exist in the specified UIDs.
+func (ws *WorkspaceStore) isOrphaned(uid types.UID, dir string, uids map[types.UID]struct{}, kinds map[string]struct{}) (bool, error) {
+	if uids == nil {
+		return false, nil
+	}
+	if _, ok := uids[uid]; ok {
+		return false, nil
+	}
+	kind, err := ws.fs.ReadFile(filepath.Join(dir, kindFile))
+	switch {
+	case errors.Is(err, iofs.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, errors.Wrap(err, "cannot read the kind of the workspace")
+	}
+	_, ok := kinds[string(kind)]
+	return ok, nil
+}
+
+// removeIdle removes the workspace with the specified UID and directory if
+// it's idle, i.e., no operation is running in it and it has not been used
+// within the sweep interval. Returns whether the workspace has been removed.
+// The workspace lock is held while the directory is removed, and
+// the workspace cannot be loaded from the store until it's removed.
+func (ws *WorkspaceStore) removeIdle(uid types.UID, dir string) (bool, error) {
+	ws.mu.Lock()
+	w, ok := ws.store[uid]
+	if ok {
+		if w.LastOperation.IsRunning() || time.Since(w.lastUsed) < ws.sweepInterval || !w.mu.TryLock() {
+			ws.mu.Unlock()
+			return false, nil
+		}
+		defer w.mu.Unlock()
+		delete(ws.store, uid)
+	}
+	done := make(chan struct{})
+	ws.removing[uid] = done
+	ws.mu.Unlock()
+
+	err := ws.fs.RemoveAll(dir)
+	ws.mu.Lock()
+	delete(ws.removing, uid)
+	ws.mu.Unlock()
+	close(done)
+	return true, errors.Wrap(err, "cannot remove workspace folder")
+}
+
+func (ws *WorkspaceStore) dirSize(dir string) (int64, error) {
+	var size int64
+	err := ws.fs.Walk(dir, func(_ string, info iofs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, errors.Wrap(err, "cannot compute the size of the workspace directory")
+}
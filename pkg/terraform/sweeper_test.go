@@ -0,0 +1,210 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestWorkspaceStoreSweep(t *testing.T) {
+	const (
+		uidA = "00000000-0000-0000-0000-00000000000a"
+		uidB = "00000000-0000-0000-0000-00000000000b"
+		uidC = "00000000-0000-0000-0000-00000000000c"
+	)
+	type workspace struct {
+		size     int
+		lastUsed time.Duration
+		running  bool
+		kind     string
+	}
+	type args struct {
+		workspaces map[types.UID]workspace
+		uidsFn     ManagedUIDsFn
+		maxUsage   int64
+	}
+	type want struct {
+		remaining []string
+		err       error
+	}
+	listed := schema.GroupKind{Group: "ec2.aws.upbound.io", Kind: "VPC"}
+	uids := func(uids ...types.UID) ManagedUIDsFn {
+		return func(_ context.Context) (map[types.UID]struct{}, []schema.GroupKind, error) {
+			m := make(map[types.UID]struct{}, len(uids))
+			for _, u := range uids {
+				m[u] = struct{}{}
+			}
+			return m, []schema.GroupKind{listed}, nil
+		}
+	}
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"NonexistentRemoved": {
+			args: args{
+				workspaces: map[types.UID]workspace{
+					uidA: {size: 1, lastUsed: -time.Hour, kind: listed.String()},
+					uidB: {size: 1, lastUsed: -time.Hour, kind: listed.String()},
+				},
+				uidsFn: uids(uidA),
+			},
+			want: want{
+				remaining: []string{uidA, "not-a-workspace"},
+			},
+		},
+		"UnlistedKindKept": {
+			args: args{
+				workspaces: map[types.UID]workspace{
+					uidA: {size: 1, lastUsed: -time.Hour, kind: listed.String()},
+					uidB: {size: 1, lastUsed: -time.Hour, kind: "Subnet.ec2.aws.upbound.io"},
+				},
+				uidsFn: uids(uidA),
+			},
+			want: want{
+				remaining: []string{uidA, uidB, "not-a-workspace"},
+			},
+		},
+		"UnknownKindKept": {
+			args: args{
+				workspaces: map[types.UID]workspace{
+					uidA: {size: 1, lastUsed: -time.Hour, kind: listed.String()},
+					uidB: {size: 1, lastUsed: -time.Hour},
+				},
+				uidsFn: uids(uidA),
+			},
+			want: want{
+				remaining: []string{uidA, uidB, "not-a-workspace"},
+			},
+		},
+		"RecentlyUsedKept": {
+			args: args{
+				workspaces: map[types.UID]workspace{
+					uidA: {size: 1, lastUsed: -time.Hour, kind: listed.String()},
+					uidB: {size: 1, kind: listed.String()},
+				},
+				uidsFn: uids(uidA),
+			},
+			want: want{
+				remaining: []string{uidA, uidB, "not-a-workspace"},
+			},
+		},
+		"DiskUsageLimit": {
+			args: args{
+				workspaces: map[types.UID]workspace{
+					uidA: {size: 10, lastUsed: -3 * time.Hour},
+					uidB: {size: 10, lastUsed: -2 * time.Hour},
+					uidC: {size: 10, lastUsed: -time.Hour},
+				},
+				maxUsage: 15,
+			},
+			want: want{
+				remaining: []string{uidC, "not-a-workspace"},
+			},
+		},
+		"RunningKept": {
+			args: args{
+				workspaces: map[types.UID]workspace{
+					uidA: {size: 10, lastUsed: -3 * time.Hour, running: true},
+					uidB: {size: 10, lastUsed: -2 * time.Hour},
+				},
+				maxUsage: 15,
+			},
+			want: want{
+				remaining: []string{uidA, "not-a-workspace"},
+			},
+		},
+		"ListFailed": {
+			args: args{
+				uidsFn: func(_ context.Context) (map[types.UID]struct{}, []schema.GroupKind, error) {
+					return nil, nil, errBoom
+				},
+			},
+			want: want{
+				remaining: []string{"not-a-workspace"},
+				err:       errors.Wrap(errBoom, "cannot get the UIDs of the managed resources"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			ws := NewWorkspaceStore(logging.NewNopLogger(), WithFs(fs), WithMaxDiskUsage(tc.args.maxUsage))
+			ws.sweepInterval = time.Minute
+			ws.managedUIDsFn = tc.args.uidsFn
+			root := ws.fs.GetTempDir("")
+			if err := ws.fs.MkdirAll(filepath.Join(root, "not-a-workspace"), 0700); err != nil {
+				t.Fatal(err)
+			}
+			for uid, w := range tc.args.workspaces {
+				dir := filepath.Join(root, string(uid))
+				if err := ws.fs.WriteFile(filepath.Join(dir, "main.tf.json"), make([]byte, w.size), 0600); err != nil {
+					t.Fatal(err)
+				}
+				if w.kind != "" {
+					if err := ws.fs.WriteFile(filepath.Join(dir, kindFile), []byte(w.kind), 0600); err != nil {
+						t.Fatal(err)
+					}
+				}
+				op := &Operation{}
+				if w.running {
+					op.MarkStart("apply")
+				}
+				ws.store[uid] = NewWorkspace(dir, WithLastOperation(op))
+				ws.store[uid].lastUsed = time.Now().Add(w.lastUsed)
+			}
+			err := ws.Sweep(context.TODO())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSweep(...): -want error, +got error:\n%s", name, diff)
+			}
+			entries, _ := ws.fs.ReadDir(root)
+			remaining := make([]string, 0, len(entries))
+			for _, e := range entries {
+				remaining = append(remaining, e.Name())
+			}
+			sort.Strings(remaining)
+			if diff := cmp.Diff(tc.want.remaining, remaining); diff != "" {
+				t.Errorf("\n%s\nSweep(...): -want remaining, +got remaining:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestWorkspaceStoreLoadRemoving(t *testing.T) {
+	const uid = "00000000-0000-0000-0000-00000000000a"
+	ws := NewWorkspaceStore(logging.NewNopLogger(), WithFs(afero.NewMemMapFs()))
+	done := make(chan struct{})
+	ws.removing[uid] = done
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if _, _, err := ws.load(ctx, uid, func() *Workspace { return NewWorkspace("/" + uid) }); err == nil {
+		t.Error("load(...): want an error while the workspace is being removed, got none")
+	}
+	if _, ok := ws.store[uid]; ok {
+		t.Error("load(...): the workspace being removed should not be stored")
+	}
+
+	delete(ws.removing, uid)
+	close(done)
+	_, loaded, err := ws.load(context.TODO(), uid, func() *Workspace { return NewWorkspace("/" + uid) })
+	if err != nil {
+		t.Fatalf("load(...): unexpected error: %v", err)
+	}
+	if loaded {
+		t.Error("load(...): want a new workspace after the removal, got a stored one")
+	}
+}
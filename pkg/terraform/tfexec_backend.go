@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: 2023 The Crossplane Authors <https://crossplane.io>
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package terraform
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/upjet/pkg/metrics"
+	"github.com/crossplane/upjet/pkg/resource/json"
+	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
+)
+
+const (
+	errNewTFExec      = "cannot initialize terraform-exec"
+	errSetTFExecEnv   = "cannot set the environment of terraform-exec"
+	errReattachConfig = "cannot unmarshal the provider reattach configuration"
+)
+
+// TFExecBackendOption lets you configure a TFExecBackend.
+type TFExecBackendOption func(b *TFExecBackend)
+
+// WithTFExecBackendCLI sets the CLI, e.g., Terraform or OpenTofu, whose
+// binary the TFExecBackend runs. The ExtraArgs of the CLI are not supported
+// by terraform-exec and are ignored.
+func WithTFExecBackendCLI(c CLI) TFExecBackendOption {
+	return func(b *TFExecBackend) {
+		b.cli = c
+	}
+}
+
+// TFExecBackend is a Backend that runs the operations with
+// hashicorp/terraform-exec. Unlike the CLIBackend, it consumes only
+// the machine-readable JSON stream that Terraform writes to its standard
+// output, so the logs on the standard error cannot be mistaken for
+// diagnostics, and it passes the reattach configurations of the shared
+// providers as typed options instead of environment variables. The CLI
+// processes are killed, rather than interrupted, when the context of
+// an operation is done.
+type TFExecBackend struct {
+	cli CLI
+}
+
+// NewTFExecBackend returns a new TFExecBackend.
+func NewTFExecBackend(opts ...TFExecBackendOption) *TFExecBackend {
+	b := &TFExecBackend{
+		cli: NewTerraformCLI(),
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// Init runs terraform init.
+func (b *TFExecBackend) Init(ctx context.Context, inv Invocation, upgrade bool) error {
+	return b.execute(ctx, inv, "init", func(tf *tfexec.Terraform, reattach *tfexec.ReattachOption) error {
+		opts := []tfexec.InitOption{tfexec.Upgrade(upgrade)}
+		if reattach != nil {
+			opts = append(opts, reattach)
+		}
+		if err := tf.Init(ctx, opts...); err != nil {
+			return errors.WithMessage(errors.New("init failed"), inv.filter(err.Error()))
+		}
+		return nil
+	})
+}
+
+// Apply runs terraform apply.
+func (b *TFExecBackend) Apply(ctx context.Context, inv Invocation) error {
+	return b.execute(ctx, inv, "apply", func(tf *tfexec.Terraform, reattach *tfexec.ReattachOption) error {
+		opts := []tfexec.ApplyOption{tfexec.Lock(false)}
+		if reattach != nil {
+			opts = append(opts, reattach)
+		}
+		var out bytes.Buffer
+		err := tf.ApplyJSON(ctx, &out, opts...)
+		inv.logger().Debug("apply ended", "out", inv.filter(out.String()))
+		if err != nil {
+			return tferrors.NewApplyFailed(out.Bytes())
+		}
+		return nil
+	})
+}
+
+// Destroy runs terraform destroy.
+func (b *TFExecBackend) Destroy(ctx context.Context, inv Invocation) error {
+	return b.execute(ctx, inv, "destroy", func(tf *tfexec.Terraform, reattach *tfexec.ReattachOption) error {
+		opts := []tfexec.DestroyOption{tfexec.Lock(false)}
+		if reattach != nil {
+			opts = append(opts, reattach)
+		}
+		var out bytes.Buffer
+		err := tf.DestroyJSON(ctx, &out, opts...)
+		inv.logger().Debug("destroy ended", "out", inv.filter(out.String()))
+		if err != nil {
+			return tferrors.NewDestroyFailed(out.Bytes())
+		}
+		return nil
+	})
+}
+
+// Refresh runs terraform refresh, which is equivalent to terraform apply
+// -refresh-only -auto-approve.
+func (b *TFExecBackend) Refresh(ctx context.Context, inv Invocation) error {
+	return b.execute(ctx, inv, "refresh", func(tf *tfexec.Terraform, reattach *tfexec.ReattachOption) error {
+		opts := []tfexec.RefreshCmdOption{tfexec.Lock(false)}
+		if reattach != nil {
+			opts = append(opts, reattach)
+		}
+		var out bytes.Buffer
+		err := tf.RefreshJSON(ctx, &out, opts...)
+		inv.logger().Debug("refresh ended", "out", inv.filter(out.String()))
+		if err != nil {
+			return tferrors.NewRefreshFailed(out.Bytes())
+		}
+		return nil
+	})
+}
+
+// Plan runs terraform plan and parses its change summary.
+func (b *TFExecBackend) Plan(ctx context.Context, inv Invocation) (PlanResult, error) {
+	var r PlanResult
+	err := b.execute(ctx, inv, "plan", func(tf *tfexec.Terraform, reattach *tfexec.ReattachOption) error {
+		opts := []tfexec.PlanOption{tfexec.Refresh(false), tfexec.Lock(false)}
+		if reattach != nil {
+			opts = append(opts, reattach)
+		}
+		var out bytes.Buffer
+		_, err := tf.PlanJSON(ctx, &out, opts...)
+		inv.logger().Debug("plan ended", "out", inv.filter(out.String()))
+		if err != nil {
+			return tferrors.NewPlanFailed(out.Bytes())
+		}
+		r, err = parsePlanSummary(out.Bytes())
+		return err
+	})
+	return r, err
+}
+
+// Import runs terraform import.
+func (b *TFExecBackend) Import(ctx context.Context, inv Invocation, address, id string) (bool, error) {
+	exists := true
+	err := b.execute(ctx, inv, "import", func(tf *tfexec.Terraform, reattach *tfexec.ReattachOption) error {
+		opts := []tfexec.ImportOption{tfexec.Lock(false)}
+		if reattach != nil {
+			opts = append(opts, reattach)
+		}
+		err := tf.Import(ctx, address, id, opts...)
+		if err == nil {
+			return nil
+		}
+		// terraform import does not support the -json flag, so the only way
+		// to tell a non-existent resource is the error message on
+		// the standard error, which terraform-exec reports in the error.
+		if strings.Contains(err.Error(), msgNonExistentImport) {
+			exists = false
+			return nil
+		}
+		return errors.WithMessage(errors.New("import failed"), inv.filter(err.Error()))
+	})
+	return exists && err == nil, err
+}
+
+// StatePull runs terraform state pull.
+func (b *TFExecBackend) StatePull(ctx context.Context, inv Invocation) ([]byte, error) {
+	var state string
+	err := b.execute(ctx, inv, "state", func(tf *tfexec.Terraform, _ *tfexec.ReattachOption) error {
+		var err error
+		state, err = tf.StatePull(ctx)
+		return err
+	})
+	return []byte(state), errors.Wrap(err, "cannot pull the state")
+}
+
+// StatePush runs terraform state push.
+func (b *TFExecBackend) StatePush(ctx context.Context, inv Invocation, path string) error {
+	err := b.execute(ctx, inv, "state", func(tf *tfexec.Terraform, _ *tfexec.ReattachOption) error {
+		return tf.StatePush(ctx, path, tfexec.Lock(false))
+	})
+	return errors.Wrap(err, "cannot push the state")
+}
+
+// StateRemove runs terraform state rm.
+func (b *TFExecBackend) StateRemove(ctx context.Context, inv Invocation, address string) error {
+	err := b.execute(ctx, inv, "state", func(tf *tfexec.Terraform, _ *tfexec.ReattachOption) error {
+		return tf.StateRm(ctx, address, tfexec.Lock(false))
+	})
+	return errors.Wrap(err, "cannot remove the resource from the state")
+}
+
+// execute runs the specified terraform-exec operation in the Workspace of
+// the specified Invocation, recording the execution metrics with
+// the specified subcommand. The reattach configuration of the shared
+// provider in the environment of the Invocation, if any, is passed to
+// the operation as a ReattachOption.
+func (b *TFExecBackend) execute(ctx context.Context, inv Invocation, subcommand string, op func(tf *tfexec.Terraform, reattach *tfexec.ReattachOption) error) error {
+	inv.logger().Debug("Running terraform", "binary", b.cli.binary(), "subcommand", subcommand)
+	tf, err := tfexec.NewTerraform(inv.Dir, b.cli.binary())
+	if err != nil {
+		return errors.Wrap(err, errNewTFExec)
+	}
+	env := envMap(append(os.Environ(), inv.Env...))
+	var reattach *tfexec.ReattachOption
+	if rc, ok := env[envReattachConfig]; ok {
+		info := tfexec.ReattachInfo{}
+		if err := json.JSParser.Unmarshal([]byte(rc), &info); err != nil {
+			return errors.Wrap(err, errReattachConfig)
+		}
+		reattach = tfexec.Reattach(info)
+	}
+	if err := tf.SetEnv(tfexec.CleanEnv(env)); err != nil {
+		return errors.Wrap(err, errSetTFExecEnv)
+	}
+	metrics.CLIExecutions.WithLabelValues(subcommand, inv.Mode.String()).Inc()
+	start := time.Now()
+	defer func() {
+		metrics.CLITime.WithLabelValues(subcommand, inv.Mode.String()).Observe(time.Since(start).Seconds())
+		metrics.CLIExecutions.WithLabelValues(subcommand, inv.Mode.String()).Dec()
+	}()
+	return op(tf, reattach)
+}
+
+// envMap converts the specified environment variables in the key=value form
+// into a map. The later values of a key override the earlier ones.
+func envMap(env []string) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, e := range env {
+		k, v, _ := strings.Cut(e, "=")
+		m[k] = v
+	}
+	return m
+}
@@ -5,6 +5,7 @@
 package terraform
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -18,7 +19,6 @@ import (
 	"github.com/spf13/afero"
 	k8sExec "k8s.io/utils/exec"
 
-	"github.com/crossplane/upjet/pkg/metrics"
 	"github.com/crossplane/upjet/pkg/resource"
 	"github.com/crossplane/upjet/pkg/resource/json"
 	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
@@ -26,8 +26,17 @@ import (
 
 const (
 	defaultAsyncTimeout = 1 * time.Hour
-	envReattachConfig   = "TF_REATTACH_PROVIDERS"
-	fmtEnv              = "%s=%s"
+	// CallbackTimeout is the timeout of the callbacks of the asynchronous
+	// operations.
+	CallbackTimeout = 1 * time.Minute
+	// stopGracePeriod is the duration a Terraform CLI process is given to
+	// terminate gracefully after it has been interrupted, before it's
+	// killed. It must be shorter than the 10s after which
+	// k8s.io/utils/exec.Cmd.Stop checks the state of the process, which
+	// is only available once the process has been waited for.
+	stopGracePeriod   = 8 * time.Second
+	envReattachConfig = "TF_REATTACH_PROVIDERS"
+	fmtEnv            = "%s=%s"
 )
 
 // ExecMode is the Terraform CLI execution mode label
@@ -90,6 +99,57 @@ func WithFilterFn(filterFn func(string) string) WorkspaceOption {
 	}
 }
 
+// WithWorkspaceCLI sets the CLI the Workspace runs its operations with.
+func WithWorkspaceCLI(c CLI) WorkspaceOption {
+	return func(w *Workspace) {
+		w.cli = c
+	}
+}
+
+// WithWorkspaceBackend sets the Backend the Workspace runs its operations
+// with. If not set, a CLIBackend is configured with the executor and the CLI
+// of the Workspace.
+func WithWorkspaceBackend(b Backend) WorkspaceOption {
+	return func(w *Workspace) {
+		w.backend = b
+	}
+}
+
+// WithWorkspaceErrorClassifier sets the Classifier the errors of
+// the operations of the Workspace are classified with.
+func WithWorkspaceErrorClassifier(c *tferrors.Classifier) WorkspaceOption {
+	return func(w *Workspace) {
+		w.classifier = c
+	}
+}
+
+// WithEnv sets the additional environment variables the operations of
+// the Workspace are run with.
+func WithEnv(env ...string) WorkspaceOption {
+	return func(w *Workspace) {
+		w.env = append(w.env, env...)
+	}
+}
+
+// WithRemoteState configures whether the Workspace stores its state in
+// a remote Terraform backend, in which case the state is pulled into
+// the local state file of the Workspace after every operation that
+// modifies it.
+func WithRemoteState(remote bool) WorkspaceOption {
+	return func(w *Workspace) {
+		w.remoteState = remote
+	}
+}
+
+// WithAsyncTimeout sets the duration after which the asynchronous operations
+// of the Workspace time out. The timeout of an operation starts once
+// the configured concurrency limits allow it to run.
+func WithAsyncTimeout(d time.Duration) WorkspaceOption {
+	return func(w *Workspace) {
+		w.asyncTimeout = d
+	}
+}
+
 // WithProviderInUse configures an InUse for keeping track of
 // the shared provider InUse by this Terraform workspace.
 func WithProviderInUse(providerInUse InUse) WorkspaceOption {
@@ -108,10 +168,15 @@ func NewWorkspace(dir string, opts ...WorkspaceOption) *Workspace {
 		fs:            afero.Afero{Fs: afero.NewOsFs()},
 		providerInUse: noopInUse{},
 		mu:            &sync.Mutex{},
+		cli:           NewTerraformCLI(),
+		asyncTimeout:  defaultAsyncTimeout,
 	}
 	for _, f := range opts {
 		f(w)
 	}
+	if w.backend == nil {
+		w.backend = NewCLIBackend(WithCLIBackendExecutor(w.executor), WithCLIBackendCLI(w.cli))
+	}
 	return w
 }
 
@@ -134,6 +199,11 @@ type Workspace struct {
 
 	logger        logging.Logger
 	executor      k8sExec.Interface
+	cli           CLI
+	backend       Backend
+	classifier    *tferrors.Classifier
+	remoteState   bool
+	asyncTimeout  time.Duration
 	providerInUse InUse
 	fs            afero.Afero
 	mu            *sync.Mutex
@@ -141,6 +211,60 @@ type Workspace struct {
 	filterFn func(string) string
 
 	terraformID string
+	// lastUsed is the last time the Workspace has been acquired from
+	// its WorkspaceStore, guarded by the WorkspaceStore's lock.
+	lastUsed time.Time
+
+	limiterMu        sync.RWMutex
+	operationLimiter *OperationLimiter
+	operationKey     OperationKey
+
+	classifierMu sync.RWMutex
+}
+
+// UseOperationLimiter makes the asynchronous operations of the receiver
+// Workspace subject to the concurrency limits of the specified
+// OperationLimiter with the given key.
+func (w *Workspace) UseOperationLimiter(l *OperationLimiter, k OperationKey) {
+	w.limiterMu.Lock()
+	defer w.limiterMu.Unlock()
+	w.operationLimiter = l
+	w.operationKey = k
+}
+
+// UseErrorClassifier makes the errors of the operations of the receiver
+// Workspace classified with the specified Classifier instead of the one
+// the Workspace has been created with.
+func (w *Workspace) UseErrorClassifier(c *tferrors.Classifier) {
+	w.classifierMu.Lock()
+	defer w.classifierMu.Unlock()
+	w.classifier = c
+}
+
+func (w *Workspace) classify(err error) error {
+	w.classifierMu.RLock()
+	defer w.classifierMu.RUnlock()
+	return w.classifier.Classify(err)
+}
+
+// runLimited runs the specified asynchronous operation in the receiver
+// Workspace once the configured concurrency limits allow it to run.
+// The operation times out after the configured async timeout, which starts
+// when the slot is acquired so that the time spent waiting in the queue does
+// not count against the operation's deadline.
+func (w *Workspace) runLimited(ctx context.Context, op func(ctx context.Context, inv Invocation) error) error {
+	w.limiterMu.RLock()
+	l, k := w.operationLimiter, w.operationKey
+	w.limiterMu.RUnlock()
+	release, err := l.Acquire(ctx, k)
+	if err != nil {
+		w.providerInUse.Decrement()
+		return errors.Wrap(err, "cannot acquire a slot for the queued async operation")
+	}
+	defer release()
+	ctx, cancel := context.WithTimeout(ctx, w.asyncTimeout)
+	defer cancel()
+	return w.run(ctx, ModeASync, op)
 }
 
 // UseProvider shares a native provider with the receiver Workspace.
@@ -166,18 +290,20 @@ func (w *Workspace) ApplyAsync(callback CallbackFn) error {
 	if !w.LastOperation.MarkStart("apply") {
 		return errors.Errorf("%s operation that started at %s is still running", w.LastOperation.Type, w.LastOperation.StartTime().String())
 	}
-	ctx, cancel := context.WithDeadline(context.TODO(), w.LastOperation.StartTime().Add(defaultAsyncTimeout))
+	ctx, cancel := w.LastOperation.WithCancel(context.TODO())
 	w.providerInUse.Increment()
 	go func() {
 		defer cancel()
-		out, err := w.runTF(ctx, ModeASync, "apply", "-auto-approve", "-input=false", "-lock=false", "-json")
-		if err != nil {
-			err = tferrors.NewApplyFailed(out)
-		}
+		err := w.runLimited(ctx, w.withStatePull(w.backend.Apply))
 		w.LastOperation.MarkEnd()
-		w.logger.Debug("apply async ended", "out", w.filterFn(string(out)))
+		w.logger.Debug("apply async ended", "canceled", w.LastOperation.IsCanceled())
 		defer func() {
-			if cErr := callback(err, ctx); cErr != nil {
+			// the callback is not run with the context of the operation,
+			// so that it can still report the result of a canceled
+			// operation.
+			cbCtx, cbCancel := context.WithTimeout(context.Background(), CallbackTimeout)
+			defer cbCancel()
+			if cErr := callback(err, cbCtx); cErr != nil {
 				w.logger.Info("callback failed", "error", cErr.Error())
 			}
 		}()
@@ -195,10 +321,8 @@ func (w *Workspace) Apply(ctx context.Context) (ApplyResult, error) {
 	if w.LastOperation.IsRunning() {
 		return ApplyResult{}, errors.Errorf("%s operation that started at %s is still running", w.LastOperation.Type, w.LastOperation.StartTime().String())
 	}
-	out, err := w.runTF(ctx, ModeSync, "apply", "-auto-approve", "-input=false", "-lock=false", "-json")
-	w.logger.Debug("apply ended", "out", w.filterFn(string(out)))
-	if err != nil {
-		return ApplyResult{}, tferrors.NewApplyFailed(out)
+	if err := w.run(ctx, ModeSync, w.withStatePull(w.backend.Apply)); err != nil {
+		return ApplyResult{}, err
 	}
 	raw, err := w.fs.ReadFile(filepath.Join(w.dir, "terraform.tfstate"))
 	if err != nil {
@@ -221,22 +345,28 @@ func (w *Workspace) DestroyAsync(callback CallbackFn) error {
 	case w.LastOperation.Type == "destroy":
 		return nil
 	// We cannot run destroy until current non-destroy operation is completed.
-	// TODO(muvaf): Gracefully terminate the ongoing apply operation?
+	// As the ongoing apply operation is stale, we interrupt it so that
+	// it completes as soon as possible.
 	case !w.LastOperation.MarkStart("destroy"):
+		if w.LastOperation.Cancel() {
+			w.logger.Debug("Canceled the ongoing operation to run destroy", "opType", w.LastOperation.Type)
+		}
 		return errors.Errorf("%s operation that started at %s is still running", w.LastOperation.Type, w.LastOperation.StartTime().String())
 	}
-	ctx, cancel := context.WithDeadline(context.TODO(), w.LastOperation.StartTime().Add(defaultAsyncTimeout))
+	ctx, cancel := w.LastOperation.WithCancel(context.TODO())
 	w.providerInUse.Increment()
 	go func() {
 		defer cancel()
-		out, err := w.runTF(ctx, ModeASync, "destroy", "-auto-approve", "-input=false", "-lock=false", "-json")
-		if err != nil {
-			err = tferrors.NewDestroyFailed(out)
-		}
+		err := w.runLimited(ctx, w.withStatePull(w.backend.Destroy))
 		w.LastOperation.MarkEnd()
-		w.logger.Debug("destroy async ended", "out", w.filterFn(string(out)))
+		w.logger.Debug("destroy async ended", "canceled", w.LastOperation.IsCanceled())
 		defer func() {
-			if cErr := callback(err, ctx); cErr != nil {
+			// the callback is not run with the context of the operation,
+			// so that it can still report the result of a canceled
+			// operation.
+			cbCtx, cbCancel := context.WithTimeout(context.Background(), CallbackTimeout)
+			defer cbCancel()
+			if cErr := callback(err, cbCtx); cErr != nil {
 				w.logger.Info("callback failed", "error", cErr.Error())
 			}
 		}()
@@ -249,12 +379,7 @@ func (w *Workspace) Destroy(ctx context.Context) error {
 	if w.LastOperation.IsRunning() {
 		return errors.Errorf("%s operation that started at %s is still running", w.LastOperation.Type, w.LastOperation.StartTime().String())
 	}
-	out, err := w.runTF(ctx, ModeSync, "destroy", "-auto-approve", "-input=false", "-lock=false", "-json")
-	w.logger.Debug("destroy ended", "out", w.filterFn(string(out)))
-	if err != nil {
-		return tferrors.NewDestroyFailed(out)
-	}
-	return nil
+	return w.run(ctx, ModeSync, w.withStatePull(w.backend.Destroy))
 }
 
 // RefreshResult contains information about the current state of the resource.
@@ -275,10 +400,8 @@ func (w *Workspace) Refresh(ctx context.Context) (RefreshResult, error) {
 	case w.LastOperation.IsEnded():
 		defer w.LastOperation.Flush()
 	}
-	out, err := w.runTF(ctx, ModeSync, "apply", "-refresh-only", "-auto-approve", "-input=false", "-lock=false", "-json")
-	w.logger.Debug("refresh ended", "out", w.filterFn(string(out)))
-	if err != nil {
-		return RefreshResult{}, tferrors.NewRefreshFailed(out)
+	if err := w.run(ctx, ModeSync, w.withStatePull(w.backend.Refresh)); err != nil {
+		return RefreshResult{}, err
 	}
 	raw, err := w.fs.ReadFile(filepath.Join(w.dir, "terraform.tfstate"))
 	if err != nil {
@@ -307,35 +430,13 @@ func (w *Workspace) Plan(ctx context.Context) (PlanResult, error) {
 	if w.LastOperation.IsRunning() {
 		return PlanResult{}, errors.Errorf("%s operation that started at %s is still running", w.LastOperation.Type, w.LastOperation.StartTime().String())
 	}
-	out, err := w.runTF(ctx, ModeSync, "plan", "-refresh=false", "-input=false", "-lock=false", "-json")
-	w.logger.Debug("plan ended", "out", w.filterFn(string(out)))
-	if err != nil {
-		return PlanResult{}, tferrors.NewPlanFailed(out)
-	}
-	line := ""
-	for _, l := range strings.Split(string(out), "\n") {
-		if strings.Contains(l, `"type":"change_summary"`) {
-			line = l
-			break
-		}
-	}
-	if line == "" {
-		return PlanResult{}, errors.Errorf("cannot find the change summary line in plan log: %s", string(out))
-	}
-	type plan struct {
-		Changes struct {
-			Add    float64 `json:"add,omitempty"`
-			Change float64 `json:"change,omitempty"`
-		} `json:"changes,omitempty"`
-	}
-	p := &plan{}
-	if err := json.JSParser.Unmarshal([]byte(line), p); err != nil {
-		return PlanResult{}, errors.Wrap(err, "cannot unmarshal change summary json")
-	}
-	return PlanResult{
-		Exists:   p.Changes.Add == 0,
-		UpToDate: p.Changes.Change == 0,
-	}, nil
+	var r PlanResult
+	err := w.run(ctx, ModeSync, func(ctx context.Context, inv Invocation) error {
+		var err error
+		r, err = w.backend.Plan(ctx, inv)
+		return err
+	})
+	return r, err
 }
 
 // ImportResult contains information about the current state of the resource.
@@ -367,19 +468,26 @@ func (w *Workspace) Import(ctx context.Context, tr resource.Terraformed) (Import
 		return ImportResult{}, errors.Wrap(err, "cannot remove terraform.tfstate file")
 	}
 
-	out, err := w.runTF(ctx, ModeSync, "import", "-input=false", "-lock=false", fmt.Sprintf("%s.%s", tr.GetTerraformResourceType(), tr.GetName()), w.terraformID)
-	w.logger.Debug("import ended", "out", w.filterFn(string(out)))
-	if err != nil {
-		// Note(turkenh): This is not a great way to check if the resource does not exist, but it is the only
-		// way we can do it for now. Terraform import does not return a proper exit code for this case or
-		// does not support -json flag to parse the returning error in a better way.
-		// https://github.com/hashicorp/terraform/blob/93f9cff99ffbb8d536b276a1be40a2c45ca4a67f/internal/terraform/node_resource_import.go#L235
-		if strings.Contains(string(out), "Cannot import non-existent remote object") {
-			return ImportResult{
-				Exists: false,
-			}, nil
+	address := fmt.Sprintf("%s.%s", tr.GetTerraformResourceType(), tr.GetName())
+	exists := false
+	err := w.run(ctx, ModeSync, w.withStatePull(func(ctx context.Context, inv Invocation) error {
+		if w.remoteState {
+			// the resource is removed from the remote state as well
+			if err := w.removeFromRemoteState(ctx, inv, address); err != nil {
+				return err
+			}
 		}
-		return ImportResult{}, errors.WithMessage(errors.New("import failed"), w.filterFn(string(out)))
+		var err error
+		exists, err = w.backend.Import(ctx, inv, address, w.terraformID)
+		return err
+	}))
+	if err != nil {
+		return ImportResult{}, err
+	}
+	if !exists {
+		return ImportResult{
+			Exists: false,
+		}, nil
 	}
 	raw, err := w.fs.ReadFile(filepath.Join(w.dir, "terraform.tfstate"))
 	if err != nil {
@@ -395,25 +503,149 @@ func (w *Workspace) Import(ctx context.Context, tr resource.Terraformed) (Import
 	}, nil
 }
 
-func (w *Workspace) runTF(ctx context.Context, execMode ExecMode, args ...string) ([]byte, error) {
-	if len(args) < 1 {
-		return nil, errors.New("args cannot be empty")
+// Init initializes the Workspace, upgrading its dependencies if upgrade is
+// true.
+func (w *Workspace) Init(ctx context.Context, upgrade bool) error {
+	return w.run(ctx, ModeSync, func(ctx context.Context, inv Invocation) error {
+		return w.backend.Init(ctx, inv, upgrade)
+	})
+}
+
+// EnsureRemoteState makes sure the remote state of the Workspace is available
+// in its local state file. If no state has been stored in the remote
+// Terraform backend of the Workspace yet, the state produced by the supplied
+// FileProducer, if any, is pushed to the backend. It's a no-op if the local
+// state file already exists, as it's kept in sync with the remote state
+// after every operation.
+func (w *Workspace) EnsureRemoteState(ctx context.Context, fp *FileProducer) error {
+	path := filepath.Join(w.dir, "terraform.tfstate")
+	_, err := w.fs.Stat(path)
+	if !os.IsNotExist(err) {
+		return errors.Wrap(err, "cannot stat terraform.tfstate file")
+	}
+	return w.run(ctx, ModeSync, func(ctx context.Context, inv Invocation) error {
+		if err := w.pullState(ctx, inv); err != nil {
+			return err
+		}
+		if _, err := w.fs.Stat(path); !os.IsNotExist(err) {
+			return errors.Wrap(err, "cannot stat terraform.tfstate file")
+		}
+		if err := fp.EnsureTFState(ctx, w.terraformID); err != nil {
+			return errors.Wrap(err, "cannot ensure tfstate file")
+		}
+		if _, err := w.fs.Stat(path); os.IsNotExist(err) {
+			// nothing to push, e.g., the resource is being deleted
+			return nil
+		}
+		return w.backend.StatePush(ctx, inv, "terraform.tfstate")
+	})
+}
+
+// withStatePull returns an operation that runs the specified operation and
+// then, if the Workspace stores its state in a remote Terraform backend,
+// pulls the remote state into the local state file of the Workspace, which
+// its results are read from. The state is pulled even if the operation
+// fails, as the operation may have partially modified the state.
+func (w *Workspace) withStatePull(op func(ctx context.Context, inv Invocation) error) func(ctx context.Context, inv Invocation) error {
+	if !w.remoteState {
+		return op
+	}
+	return func(ctx context.Context, inv Invocation) error {
+		err := op(ctx, inv)
+		// the context of the operation may already be done if it has
+		// been canceled or it has timed out.
+		if pErr := w.pullState(context.Background(), inv); pErr != nil && err == nil {
+			return pErr
+		}
+		return err
+	}
+}
+
+// pullState writes the remote state of the Workspace into its local state
+// file, or removes the local state file if there is no remote state.
+func (w *Workspace) pullState(ctx context.Context, inv Invocation) error {
+	raw, err := w.backend.StatePull(ctx, inv)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(w.dir, "terraform.tfstate")
+	if len(strings.TrimSpace(string(raw))) == 0 {
+		if err := w.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "cannot remove terraform.tfstate file")
+		}
+		return nil
+	}
+	return errors.Wrap(w.fs.WriteFile(path, raw, 0600), "cannot write terraform.tfstate file")
+}
+
+// removeFromRemoteState removes the resource at the specified address from
+// the remote state of the Workspace, if it's there.
+func (w *Workspace) removeFromRemoteState(ctx context.Context, inv Invocation, address string) error {
+	raw, err := w.backend.StatePull(ctx, inv)
+	if err != nil || len(strings.TrimSpace(string(raw))) == 0 {
+		return err
+	}
+	s := &json.StateV4{}
+	if err := json.JSParser.Unmarshal(raw, s); err != nil {
+		return errors.Wrap(err, "cannot unmarshal the remote state")
+	}
+	if len(s.Resources) == 0 {
+		return nil
 	}
-	w.logger.Debug("Running terraform", "args", args)
+	return w.backend.StateRemove(ctx, inv, address)
+}
+
+// run runs the specified Backend operation in the Workspace while holding
+// the Workspace lock and keeping the shared provider in use.
+func (w *Workspace) run(ctx context.Context, execMode ExecMode, op func(ctx context.Context, inv Invocation) error) error {
 	if execMode == ModeSync {
 		w.providerInUse.Increment()
 	}
 	defer w.providerInUse.Decrement()
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	cmd := w.executor.CommandContext(ctx, "terraform", args...)
-	cmd.SetEnv(append(os.Environ(), w.env...))
-	cmd.SetDir(w.dir)
-	metrics.CLIExecutions.WithLabelValues(args[0], execMode.String()).Inc()
-	start := time.Now()
-	defer func() {
-		metrics.CLITime.WithLabelValues(args[0], execMode.String()).Observe(time.Since(start).Seconds())
-		metrics.CLIExecutions.WithLabelValues(args[0], execMode.String()).Dec()
+	return w.classify(op(ctx, Invocation{
+		Dir:      w.dir,
+		Env:      w.env,
+		Mode:     execMode,
+		Logger:   w.logger,
+		FilterFn: w.filterFn,
+	}))
+}
+
+// commandOutput runs the specified command and returns its standard output,
+// combined with its standard error if combined is true. If the supplied
+// context is done before the command completes, the command is first
+// interrupted so that Terraform can stop gracefully, e.g., by persisting
+// the state of a partially created resource, and then killed if it does not
+// terminate within the stopGracePeriod. The command is started before
+// waiting for the context, so that it can only be interrupted once it has
+// been started.
+func commandOutput(ctx context.Context, cmd k8sExec.Cmd, kill context.CancelFunc, combined bool) ([]byte, error) {
+	var out bytes.Buffer
+	cmd.SetStdout(&out)
+	if combined {
+		cmd.SetStderr(&out)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	ch := make(chan error, 1)
+	go func() {
+		ch <- cmd.Wait()
 	}()
-	return cmd.CombinedOutput()
+	var err error
+	select {
+	case err = <-ch:
+		return out.Bytes(), err
+	case <-ctx.Done():
+	}
+	cmd.Stop()
+	select {
+	case err = <-ch:
+	case <-time.After(stopGracePeriod):
+		kill()
+		err = <-ch
+	}
+	return out.Bytes(), err
 }
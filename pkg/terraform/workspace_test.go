@@ -6,6 +6,7 @@ package terraform
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	k8sExec "k8s.io/utils/exec"
 	testingexec "k8s.io/utils/exec/testing"
 
+	"github.com/crossplane/upjet/pkg/resource/fake"
 	"github.com/crossplane/upjet/pkg/resource/json"
 	tferrors "github.com/crossplane/upjet/pkg/terraform/errors"
 )
@@ -60,18 +62,39 @@ func newFakeExec(stdOut string, err error) *testingexec.FakeExec {
 	return &testingexec.FakeExec{
 		CommandScript: []testingexec.FakeCommandAction{
 			func(_ string, _ ...string) k8sExec.Cmd {
-				return &testingexec.FakeCmd{
-					CombinedOutputScript: []testingexec.FakeAction{
-						func() ([]byte, []byte, error) {
-							return []byte(stdOut), nil, err
-						},
-					},
-				}
+				return newScriptedCmd(func() ([]byte, []byte, error) {
+					return []byte(stdOut), nil, err
+				})
 			},
 		},
 	}
 }
 
+// scriptedCmd is a fake command that runs its action when it's waited for
+// and writes the output of the action to its standard output and error.
+type scriptedCmd struct {
+	*testingexec.FakeCmd
+	action testingexec.FakeAction
+}
+
+func newScriptedCmd(action testingexec.FakeAction) *scriptedCmd {
+	return &scriptedCmd{
+		FakeCmd: &testingexec.FakeCmd{},
+		action:  action,
+	}
+}
+
+func (c *scriptedCmd) Wait() error {
+	stdOut, stdErr, err := c.action()
+	if c.Stdout != nil {
+		_, _ = c.Stdout.Write(stdOut)
+	}
+	if c.Stderr != nil {
+		_, _ = c.Stderr.Write(stdErr)
+	}
+	return err
+}
+
 func TestWorkspaceApply(t *testing.T) {
 	type args struct {
 		w *Workspace
@@ -474,3 +497,271 @@ func TestWorkspaceDestroyAsync(t *testing.T) {
 		})
 	}
 }
+
+// interruptibleCmd is a fake command that runs until it's stopped.
+type interruptibleCmd struct {
+	*scriptedCmd
+	stopped chan struct{}
+}
+
+func (c *interruptibleCmd) Stop() {
+	close(c.stopped)
+}
+
+func newInterruptibleCmd(stdOut string) *interruptibleCmd {
+	c := &interruptibleCmd{
+		stopped: make(chan struct{}),
+	}
+	c.scriptedCmd = newScriptedCmd(func() ([]byte, []byte, error) {
+		<-c.stopped
+		return []byte(stdOut), nil, errBoom
+	})
+	return c
+}
+
+// importBackend is a Backend whose Import operation is stubbed.
+type importBackend struct {
+	*CLIBackend
+	exists bool
+	err    error
+	// address and id are the arguments Import has been called with
+	address, id string
+}
+
+func (b *importBackend) Import(_ context.Context, _ Invocation, address, id string) (bool, error) {
+	b.address, b.id = address, id
+	if b.exists {
+		// the imported state is written into the workspace
+		if err := fs.WriteFile(directory+"terraform.tfstate", []byte(tfstate), 0600); err != nil {
+			return false, err
+		}
+	}
+	return b.exists, b.err
+}
+
+func TestWorkspaceImport(t *testing.T) {
+	tr := &fake.Terraformed{}
+	tr.SetName("example")
+	type args struct {
+		backend *importBackend
+		id      string
+	}
+	type want struct {
+		r   ImportResult
+		err error
+	}
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"NoID": {
+			args: args{
+				backend: &importBackend{exists: true},
+			},
+			want: want{
+				r: ImportResult{Exists: false},
+			},
+		},
+		"NotExists": {
+			args: args{
+				backend: &importBackend{},
+				id:      "some-id",
+			},
+			want: want{
+				r: ImportResult{Exists: false},
+			},
+		},
+		"Failure": {
+			args: args{
+				backend: &importBackend{err: errBoom},
+				id:      "some-id",
+			},
+			want: want{
+				err: errBoom,
+			},
+		},
+		"Success": {
+			args: args{
+				backend: &importBackend{exists: true},
+				id:      "some-id",
+			},
+			want: want{
+				r: ImportResult{Exists: false, State: state},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := NewWorkspace(directory, WithWorkspaceBackend(tc.args.backend), WithAferoFs(fs), WithFilterFn(filterFn))
+			w.terraformID = tc.args.id
+			if err := w.fs.WriteFile(directory+"terraform.tfstate", []byte(tfstate), 0777); err != nil {
+				panic(err)
+			}
+			r, err := w.Import(context.TODO(), tr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nImport(...): -want error, +got error:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.r, r); diff != "" {
+				t.Errorf("\n%s\nImport(...): -want result, +got result:\n%s", name, diff)
+			}
+			if tc.args.id != "" && tc.args.backend.address != ".example" {
+				t.Errorf("\n%s\nImport(...): unexpected resource address %q", name, tc.args.backend.address)
+			}
+		})
+	}
+}
+
+// remoteStateBackend is a Backend whose state operations are stubbed.
+type remoteStateBackend struct {
+	*CLIBackend
+	remote  string
+	pullErr error
+	// pushed is the state pushed to the backend
+	pushed string
+}
+
+func (b *remoteStateBackend) StatePull(_ context.Context, _ Invocation) ([]byte, error) {
+	return []byte(b.remote), b.pullErr
+}
+
+func (b *remoteStateBackend) StatePush(_ context.Context, inv Invocation, path string) error {
+	raw, err := fs.ReadFile(filepath.Join(inv.Dir, path))
+	b.pushed = string(raw)
+	return err
+}
+
+func TestWorkspaceEnsureRemoteState(t *testing.T) {
+	type args struct {
+		backend *remoteStateBackend
+		local   string
+	}
+	type want struct {
+		local  string
+		pushed string
+		err    error
+	}
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"LocalStateExists": {
+			args: args{
+				backend: &remoteStateBackend{remote: "remote"},
+				local:   tfstate,
+			},
+			want: want{
+				local: tfstate,
+			},
+		},
+		"RemoteStatePulled": {
+			args: args{
+				backend: &remoteStateBackend{remote: tfstate},
+			},
+			want: want{
+				local: tfstate,
+			},
+		},
+		"PullFailed": {
+			args: args{
+				backend: &remoteStateBackend{pullErr: errBoom},
+			},
+			want: want{
+				err: errBoom,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(directory, "terraform.tfstate")
+			_ = fs.Remove(path)
+			if tc.args.local != "" {
+				if err := fs.WriteFile(path, []byte(tc.args.local), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			w := NewWorkspace(directory, WithWorkspaceBackend(tc.args.backend), WithRemoteState(true), WithAferoFs(fs), WithFilterFn(filterFn))
+			err := w.EnsureRemoteState(context.TODO(), nil)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nEnsureRemoteState(...): -want error, +got error:\n%s", name, diff)
+			}
+			local, _ := fs.ReadFile(path)
+			if diff := cmp.Diff(tc.want.local, string(local)); diff != "" {
+				t.Errorf("\n%s\nEnsureRemoteState(...): -want local state, +got local state:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.pushed, tc.args.backend.pushed); diff != "" {
+				t.Errorf("\n%s\nEnsureRemoteState(...): -want pushed state, +got pushed state:\n%s", name, diff)
+			}
+		})
+	}
+}
+
+func TestCommandOutput(t *testing.T) {
+	type want struct {
+		out     string
+		err     error
+		stopped bool
+		killed  bool
+	}
+	cases := map[string]struct {
+		cancel   bool
+		startErr error
+		want
+	}{
+		"NotStarted": {
+			cancel:   true,
+			startErr: errBoom,
+			want: want{
+				err: errBoom,
+			},
+		},
+		"Completed": {
+			want: want{
+				out:     "stopped",
+				err:     errBoom,
+				stopped: true,
+			},
+		},
+		"Interrupted": {
+			cancel: true,
+			want: want{
+				out:     "interrupted",
+				err:     errBoom,
+				stopped: true,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cmd := newInterruptibleCmd("interrupted")
+			cmd.StartResponse = tc.startErr
+			if tc.cancel {
+				cancel()
+			} else {
+				cmd = newInterruptibleCmd("stopped")
+				go cmd.Stop()
+			}
+			killed := false
+			out, err := commandOutput(ctx, cmd, func() { killed = true }, true)
+			if diff := cmp.Diff(tc.want.out, string(out)); diff != "" {
+				t.Errorf("\n%s\ncommandOutput(...): -want output, +got output:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ncommandOutput(...): -want error, +got error:\n%s", name, diff)
+			}
+			stopped := false
+			select {
+			case <-cmd.stopped:
+				stopped = true
+			default:
+			}
+			if diff := cmp.Diff(tc.want.stopped, stopped); diff != "" {
+				t.Errorf("\n%s\ncommandOutput(...): -want stopped, +got stopped:\n%s", name, diff)
+			}
+			if diff := cmp.Diff(tc.want.killed, killed); diff != "" {
+				t.Errorf("\n%s\ncommandOutput(...): -want killed, +got killed:\n%s", name, diff)
+			}
+		})
+	}
+}
@@ -144,6 +144,9 @@ func NewField(g *Builder, cfg *config.Resource, r *resource, sch *schema.Schema,
 			cfg.LateInitializer.AddIgnoredCanonicalFields(fieldPath(f.CanonicalPaths))
 		}
 	}
+	if p, ok := cfg.LateInitializer.Policies[fieldPath(f.TerraformPaths)]; ok {
+		cfg.LateInitializer.AddCanonicalPolicy(fieldPath(f.CanonicalPaths), p)
+	}
 
 	fieldType, initType, err := g.buildSchema(f, cfg, names, r)
 	if err != nil {